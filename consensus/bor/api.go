@@ -1,18 +1,27 @@
 package bor
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/bor/valset"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -23,8 +32,18 @@ import (
 var (
 	// MaxCheckpointLength is the maximum number of blocks that can be requested for constructing a checkpoint root hash
 	MaxCheckpointLength = uint64(math.Pow(2, 15))
+
+	// MaxValidateBlockRange is the maximum number of headers validated by a single ValidateBlockRange call
+	MaxValidateBlockRange = uint64(10000)
+
+	// MaxPerfectProposersRange is the maximum number of blocks scanned by a single GetPerfectProposers call
+	MaxPerfectProposersRange = uint64(10000)
 )
 
+// futureSpanFetchTimeout bounds the fetches from heimdall done by a single
+// GetSpanForFutureBlock call.
+const futureSpanFetchTimeout = 10 * time.Second
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -50,6 +69,134 @@ func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
 	return api.bor.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 }
 
+// ExportedSnapshot is the complete content of a snapshot, in a stable order so
+// that the exports of two nodes can be compared byte for byte.
+type ExportedSnapshot struct {
+	Number           uint64              `json:"number"`
+	Hash             common.Hash         `json:"hash"`
+	SpanID           *uint64             `json:"spanID,omitempty"` // Span at the block, if its state is available
+	TotalVotingPower int64               `json:"totalVotingPower"`
+	Proposer         *valset.Validator   `json:"proposer"`
+	Validators       []*valset.Validator `json:"validators"` // In the order of the validator set
+	Recents          []RecentSigner      `json:"recents"`    // Ordered by block number
+}
+
+// RecentSigner is the signer of a recent block of a snapshot.
+type RecentSigner struct {
+	Number uint64         `json:"number"`
+	Signer common.Address `json:"signer"`
+}
+
+// ExportSnapshot returns the complete snapshot at the given block (the head if not
+// given), for the offline comparison of the snapshots of nodes which disagree on
+// the authors schedule.
+func (api *API) ExportSnapshot(ctx context.Context, number *rpc.BlockNumber) (*ExportedSnapshot, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a copy, the snapshot is shared with the engine
+	validatorSet := snap.ValidatorSet.Copy()
+
+	export := &ExportedSnapshot{
+		Number:           snap.Number,
+		Hash:             snap.Hash,
+		TotalVotingPower: validatorSet.TotalVotingPower(),
+		Proposer:         validatorSet.GetProposer(),
+		Validators:       validatorSet.Validators,
+		Recents:          make([]RecentSigner, 0, len(snap.Recents)),
+	}
+
+	for number, signer := range snap.Recents {
+		export.Recents = append(export.Recents, RecentSigner{Number: number, Signer: signer})
+	}
+
+	sort.Slice(export.Recents, func(i, j int) bool {
+		return export.Recents[i].Number < export.Recents[j].Number
+	})
+
+	if currentSpan, err := api.bor.spanner.GetCurrentSpan(ctx, snap.Hash); err == nil {
+		export.SpanID = &currentSpan.ID
+	}
+
+	return export, nil
+}
+
+// DecodedExtraData is the content of the extra-data of a bor header.
+type DecodedExtraData struct {
+	Number       uint64              `json:"number"`
+	Hash         common.Hash         `json:"hash"`
+	Vanity       hexutil.Bytes       `json:"vanity"`
+	SprintEnd    bool                `json:"sprintEnd"`              // Whether the header carries the validators of the next sprint
+	Validators   []*valset.Validator `json:"validators,omitempty"`   // Validators of the next sprint, at the sprint ends only
+	TxDependency [][]uint64          `json:"txDependency,omitempty"` // Dependencies of the transactions, after the parallel universe fork
+	Signature    hexutil.Bytes       `json:"signature"`
+	Signer       common.Address      `json:"signer"` // Recovered from the signature
+}
+
+// DecodeExtraData returns the decoded extra-data of the header at the given block
+// (the head if not given): the vanity, the validators embedded at the sprint ends
+// and the seal, along with its signer.
+func (api *API) DecodeExtraData(number *rpc.BlockNumber) (*DecodedExtraData, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	if err := validateHeaderExtraField(header.Extra); err != nil {
+		return nil, err
+	}
+
+	headerNumber := header.Number.Uint64()
+	config := api.bor.config
+
+	decoded := &DecodedExtraData{
+		Number:    headerNumber,
+		Hash:      header.Hash(),
+		Vanity:    common.CopyBytes(header.Extra[:types.ExtraVanityLength]),
+		SprintEnd: headerNumber > 0 && (headerNumber+1)%config.CalculateSprint(headerNumber) == 0,
+		Signature: common.CopyBytes(header.Extra[len(header.Extra)-types.ExtraSealLength:]),
+	}
+
+	payload := header.Extra[types.ExtraVanityLength : len(header.Extra)-types.ExtraSealLength]
+	validatorBytes := payload
+
+	if config.IsParallelUniverse(header.Number) && len(payload) > 0 {
+		var blockExtraData types.BlockExtraData
+		if err := rlp.DecodeBytes(payload, &blockExtraData); err != nil {
+			return nil, fmt.Errorf("failed to decode the extra-data of block %d: %w", headerNumber, err)
+		}
+
+		validatorBytes = blockExtraData.ValidatorBytes
+		decoded.TxDependency = blockExtraData.TxDependency
+	}
+
+	if decoded.SprintEnd {
+		validators, err := valset.ParseValidators(validatorBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the validators of block %d: %w", headerNumber, err)
+		}
+
+		decoded.Validators = validators
+	}
+
+	signer, err := ecrecover(header, api.bor.signatures, config)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded.Signer = signer
+
+	return decoded, nil
+}
+
 type BlockSigners struct {
 	Signers []difficultiesKV
 	Diff    int
@@ -261,6 +408,17 @@ func (api *API) GetCurrentProposer() (common.Address, error) {
 	return snap.ValidatorSet.GetProposer().Address, nil
 }
 
+// GetRecentsLockout returns the number of blocks until the given validator drops
+// out of the recents of the current snapshot, 0 if it's not a recent signer.
+func (api *API) GetRecentsLockout(address common.Address) (uint64, error) {
+	snap, err := api.GetSnapshot(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return snap.RecentsLockout(address, api.bor.recentsLimitPercent), nil
+}
+
 // GetCurrentValidators gets the current validators
 func (api *API) GetCurrentValidators() ([]*valset.Validator, error) {
 	snap, err := api.GetSnapshot(nil)
@@ -271,6 +429,678 @@ func (api *API) GetCurrentValidators() ([]*valset.Validator, error) {
 	return snap.ValidatorSet.Validators, nil
 }
 
+// SpanCountdown describes how far the head is from the next span change.
+type SpanCountdown struct {
+	Head                uint64 `json:"head"`
+	CurrentSpanID       uint64 `json:"currentSpanID"`
+	NextSpanID          uint64 `json:"nextSpanID"`
+	NextSpanStartBlock  uint64 `json:"nextSpanStartBlock"`
+	BlocksUntilNextSpan uint64 `json:"blocksUntilNextSpan"`
+	NextSpanFetched     bool   `json:"nextSpanFetched"`
+}
+
+// BlocksUntilNextSpan returns the number of blocks from the head until the next
+// span boundary, along with the upcoming span id. The next span is never fetched
+// from heimdall here, NextSpanFetched only reports whether it was already committed.
+func (api *API) BlocksUntilNextSpan() (*SpanCountdown, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	currentSpan, err := api.bor.spanner.GetCurrentSpan(context.Background(), header.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	head := header.Number.Uint64()
+	countdown := &SpanCountdown{
+		Head:               head,
+		CurrentSpanID:      currentSpan.ID,
+		NextSpanID:         currentSpan.ID + 1,
+		NextSpanStartBlock: currentSpan.EndBlock + 1,
+	}
+
+	if countdown.NextSpanStartBlock > head {
+		countdown.BlocksUntilNextSpan = countdown.NextSpanStartBlock - head
+	}
+
+	// The next span is fetched and committed in the first block of the last
+	// sprint of the current span (see needToCommitSpan).
+	sprint := api.bor.config.CalculateSprint(head)
+	if currentSpan.EndBlock > sprint {
+		countdown.NextSpanFetched = head >= currentSpan.EndBlock-sprint+1
+	}
+
+	return countdown, nil
+}
+
+// SpanCommit is the block a span was committed on-chain at.
+type SpanCommit struct {
+	SpanID    uint64       `json:"spanID"`
+	Number    uint64       `json:"number"`
+	Hash      *common.Hash `json:"hash,omitempty"` // Hash of the block, if it's already part of the chain
+	Committed bool         `json:"committed"`      // Whether the block is already part of the chain
+}
+
+// GetSpanCommitBlock returns the block the given span was (or will be) committed
+// at. A span is committed in the first block of the last sprint of the previous
+// span (see needToCommitSpan), which is derived from the start of the span as
+// reported by heimdall. The initial span isn't committed by any block.
+func (api *API) GetSpanCommitBlock(ctx context.Context, spanID uint64) (*SpanCommit, error) {
+	if spanID == 0 {
+		return nil, errUnknownSpanCommit
+	}
+
+	spanProvider := api.bor.getSpanProvider()
+	if spanProvider == nil {
+		return nil, errHeimdallClientUnavailable
+	}
+
+	heimdallSpan, err := spanProvider.GetSpan(ctx, spanID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousEnd := heimdallSpan.StartBlock - 1
+
+	sprint := api.bor.config.CalculateSprint(previousEnd)
+	if heimdallSpan.StartBlock <= sprint {
+		return nil, errUnknownSpanCommit
+	}
+
+	commit := &SpanCommit{
+		SpanID: spanID,
+		Number: previousEnd - sprint + 1,
+	}
+
+	if header := api.chain.GetHeaderByNumber(commit.Number); header != nil {
+		hash := header.Hash()
+		commit.Hash = &hash
+		commit.Committed = true
+	}
+
+	return commit, nil
+}
+
+// FutureBlockSpan is the span a block belongs to, with its validator set.
+type FutureBlockSpan struct {
+	Number            uint64              `json:"number"`
+	SpanID            uint64              `json:"spanID"`                      // Id of the span, estimated from the length of the last known span if not available
+	Available         bool                `json:"available"`                   // Whether the span is already decided by heimdall
+	Committed         bool                `json:"committed"`                   // Whether the span is already committed on-chain
+	StartBlock        uint64              `json:"startBlock,omitempty"`        // First block of the span, if available
+	EndBlock          uint64              `json:"endBlock,omitempty"`          // Last block of the span, if available
+	Validators        []*valset.Validator `json:"validators,omitempty"`        // Validator set of the span, if available
+	SelectedProducers []valset.Validator  `json:"selectedProducers,omitempty"` // Block producers of the span, if available and not committed yet
+}
+
+// GetSpanForFutureBlock returns the span the given block belongs to, which can be
+// one after the span committed at the head, with its validator set. The spans not
+// committed yet are fetched from heimdall. Available is false, without an error,
+// if heimdall didn't decide the span yet, while a failed fetch is an error.
+func (api *API) GetSpanForFutureBlock(ctx context.Context, number uint64) (*FutureBlockSpan, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	currentSpan, err := api.bor.spanner.GetCurrentSpan(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	if number < currentSpan.StartBlock {
+		return nil, fmt.Errorf("block %d is before the current span %d starting at block %d", number, currentSpan.ID, currentSpan.StartBlock)
+	}
+
+	res := &FutureBlockSpan{Number: number}
+
+	if number <= currentSpan.EndBlock {
+		validators, err := api.bor.spanner.GetCurrentValidatorsByHash(ctx, header.Hash(), number)
+		if err != nil {
+			return nil, err
+		}
+
+		res.SpanID = currentSpan.ID
+		res.Available = true
+		res.Committed = true
+		res.StartBlock = currentSpan.StartBlock
+		res.EndBlock = currentSpan.EndBlock
+		res.Validators = validators
+
+		return res, nil
+	}
+
+	spanProvider := api.bor.getSpanProvider()
+	if spanProvider == nil {
+		return nil, errHeimdallClientUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, futureSpanFetchTimeout)
+	defer cancel()
+
+	// The spans are only known to heimdall up to its latest one, fetching a later
+	// one would be retried until the timeout. Without the latest span, the fetch
+	// failing is all that can be told.
+	latestID := uint64(math.MaxUint64)
+
+	latest, err := spanProvider.GetCurrentSpan(ctx)
+	if err == nil {
+		latestID = latest.ID
+	} else if !errors.Is(err, errLatestSpanNotSupported) {
+		return nil, fmt.Errorf("failed to fetch the latest span: %w", err)
+	}
+
+	// Walk the spans after the current one until the one of the block, as their
+	// length isn't known beforehand
+	id, start, end := currentSpan.ID, currentSpan.StartBlock, currentSpan.EndBlock
+
+	for number > end {
+		if id+1 > latestID {
+			res.SpanID = id + 1 + (number-end-1)/(end-start+1)
+			return res, nil
+		}
+
+		heimdallSpan, err := spanProvider.GetSpan(ctx, id+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch span %d: %w", id+1, err)
+		}
+
+		if heimdallSpan.StartBlock != end+1 || heimdallSpan.EndBlock < heimdallSpan.StartBlock {
+			return nil, fmt.Errorf("span %d from block %d to %d doesn't follow span %d ending at block %d", heimdallSpan.ID, heimdallSpan.StartBlock, heimdallSpan.EndBlock, id, end)
+		}
+
+		id, start, end = heimdallSpan.ID, heimdallSpan.StartBlock, heimdallSpan.EndBlock
+
+		if number <= end {
+			res.SpanID = id
+			res.Available = true
+			res.StartBlock = start
+			res.EndBlock = end
+			res.Validators = heimdallSpan.ValidatorSet.Validators
+			res.SelectedProducers = heimdallSpan.SelectedProducers
+		}
+	}
+
+	return res, nil
+}
+
+// ResolvedBorConfig holds the bor parameters in effect at a block, resolved from
+// the block-keyed values of the chain config.
+type ResolvedBorConfig struct {
+	Number                     uint64         `json:"number"`
+	Period                     uint64         `json:"period"`
+	ProducerDelay              uint64         `json:"producerDelay"`
+	Sprint                     uint64         `json:"sprint"`
+	BackupMultiplier           uint64         `json:"backupMultiplier"`
+	StateSyncConfirmationDelay uint64         `json:"stateSyncConfirmationDelay"`
+	MaxStateSyncPerSprint      uint64         `json:"maxStateSyncPerSprint"`
+	BurntContract              common.Address `json:"burntContract"`
+	ValidatorContract          common.Address `json:"validatorContract"`
+	StateReceiverContract      common.Address `json:"stateReceiverContract"`
+	Jaipur                     bool           `json:"jaipur"`
+	Delhi                      bool           `json:"delhi"`
+	Indore                     bool           `json:"indore"`
+	SpanID                     uint64         `json:"spanID,omitempty"`
+	SpanSize                   uint64         `json:"spanSize,omitempty"`
+	Producers                  uint64         `json:"producers,omitempty"`
+}
+
+// GetBorConfig returns the bor parameters in effect at the given block (the head
+// if not given), taking the forks scheduled in the chain config into account. The
+// span and its number of producers are read from the validator contract at that
+// block, they're left out if the block or its state isn't known locally.
+func (api *API) GetBorConfig(ctx context.Context, number *rpc.BlockNumber) (*ResolvedBorConfig, error) {
+	var (
+		num    uint64
+		header *types.Header
+	)
+
+	if number == nil || *number < 0 {
+		header = api.chain.CurrentHeader()
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+
+		num = header.Number.Uint64()
+	} else {
+		num = uint64(number.Int64())
+
+		if api.chain != nil {
+			header = api.chain.GetHeaderByNumber(num)
+		}
+	}
+
+	var (
+		config   = api.bor.config
+		bigNum   = new(big.Int).SetUint64(num)
+		resolved = &ResolvedBorConfig{
+			Number:                num,
+			Period:                config.CalculatePeriod(num),
+			ProducerDelay:         config.CalculateProducerDelay(num),
+			Sprint:                config.CalculateSprint(num),
+			BackupMultiplier:      config.CalculateBackupMultiplier(num),
+			MaxStateSyncPerSprint: config.CalculateMaxStateSyncPerSprint(num),
+			ValidatorContract:     common.HexToAddress(config.ValidatorContract),
+			StateReceiverContract: common.HexToAddress(config.StateReceiverContract),
+			Jaipur:                config.IsJaipur(bigNum),
+			Delhi:                 config.IsDelhi(bigNum),
+			Indore:                config.IsIndore(bigNum),
+		}
+	)
+
+	if len(config.StateSyncConfirmationDelay) > 0 {
+		resolved.StateSyncConfirmationDelay = config.CalculateStateSyncDelay(num)
+	}
+
+	if len(config.BurntContract) > 0 {
+		resolved.BurntContract = common.HexToAddress(config.CalculateBurntContract(num))
+	}
+
+	if header == nil || api.bor.spanner == nil {
+		return resolved, nil
+	}
+
+	currentSpan, err := api.bor.spanner.GetCurrentSpan(ctx, header.Hash())
+	if err != nil {
+		return resolved, nil
+	}
+
+	resolved.SpanID = currentSpan.ID
+	resolved.SpanSize = currentSpan.EndBlock - currentSpan.StartBlock + 1
+
+	if producers, err := api.bor.spanner.GetCurrentValidatorsByHash(ctx, header.Hash(), num); err == nil {
+		resolved.Producers = uint64(len(producers))
+	}
+
+	return resolved, nil
+}
+
+// ProposerTurn is a range of blocks (a sprint) proposed by the same validator.
+type ProposerTurn struct {
+	StartBlock uint64         `json:"startBlock"`
+	EndBlock   uint64         `json:"endBlock"`
+	Proposer   common.Address `json:"proposer"`
+}
+
+// ProposerSchedule is the ordered list of the proposers of a span.
+type ProposerSchedule struct {
+	SpanID     uint64         `json:"spanID"`
+	StartBlock uint64         `json:"startBlock"`
+	EndBlock   uint64         `json:"endBlock"`
+	Turns      []ProposerTurn `json:"turns"`
+}
+
+// GetCurrentProposerSchedule returns the in-turn proposer of every sprint of the
+// current span. The schedule is derived from the validator set at the start of the
+// span, rotated at every sprint end the same way Snapshot.apply does.
+func (api *API) GetCurrentProposerSchedule() (*ProposerSchedule, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	currentSpan, err := api.bor.spanner.GetCurrentSpan(context.Background(), header.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	// The validator set used to seal the first block of the span is the one of its parent
+	snapNumber := currentSpan.StartBlock
+	if snapNumber > 0 {
+		snapNumber--
+	}
+
+	snapHeader := api.chain.GetHeaderByNumber(snapNumber)
+	if snapHeader == nil {
+		return nil, errUnknownBlock
+	}
+
+	snap, err := api.bor.snapshot(api.chain, snapNumber, snapHeader.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &ProposerSchedule{
+		SpanID:     currentSpan.ID,
+		StartBlock: currentSpan.StartBlock,
+		EndBlock:   currentSpan.EndBlock,
+		Turns:      make([]ProposerTurn, 0),
+	}
+
+	validatorSet := snap.ValidatorSet.Copy()
+
+	for start := currentSpan.StartBlock; start <= currentSpan.EndBlock; {
+		sprint := api.bor.config.CalculateSprint(start)
+
+		end := start - start%sprint + sprint - 1
+		if end > currentSpan.EndBlock {
+			end = currentSpan.EndBlock
+		}
+
+		schedule.Turns = append(schedule.Turns, ProposerTurn{
+			StartBlock: start,
+			EndBlock:   end,
+			Proposer:   validatorSet.GetProposer().Address,
+		})
+
+		validatorSet.IncrementProposerPriority(1)
+
+		start = end + 1
+	}
+
+	return schedule, nil
+}
+
+// GetSealerForBlock returns the in-turn signer of the given block. Unlike
+// GetAuthor, which recovers the signer of a sealed block, the block doesn't need
+// to exist yet: the in-turn signer of a future block is predicted from the
+// snapshot at the head, rotated at every sprint end until the block, up to the
+// end of the current span. Later blocks are refused, as the validator set of the
+// next span may differ.
+func (api *API) GetSealerForBlock(number uint64) (common.Address, error) {
+	if number == 0 {
+		return common.Address{}, errUnknownBlock
+	}
+
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return common.Address{}, errUnknownBlock
+	}
+
+	// The in-turn signer of an existing block is the proposer of its parent's snapshot
+	head := header.Number.Uint64()
+	if number <= head {
+		parent := api.chain.GetHeaderByNumber(number - 1)
+		if parent == nil {
+			return common.Address{}, errUnknownBlock
+		}
+
+		snap, err := api.bor.snapshot(api.chain, number-1, parent.Hash(), nil)
+		if err != nil {
+			return common.Address{}, err
+		}
+
+		return snap.ValidatorSet.GetProposer().Address, nil
+	}
+
+	currentSpan, err := api.bor.spanner.GetCurrentSpan(context.Background(), header.Hash())
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if number > currentSpan.EndBlock {
+		return common.Address{}, fmt.Errorf("block %d is beyond the current span %d ending at block %d", number, currentSpan.ID, currentSpan.EndBlock)
+	}
+
+	snap, err := api.bor.snapshot(api.chain, head, header.Hash(), nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	// The proposer rotates once after every sprint end block (see Snapshot.apply)
+	validatorSet := snap.ValidatorSet.Copy()
+
+	for block := head + 1; block < number; block++ {
+		if (block+1)%api.bor.config.CalculateSprint(block) == 0 {
+			validatorSet.IncrementProposerPriority(1)
+		}
+	}
+
+	return validatorSet.GetProposer().Address, nil
+}
+
+// GetStateSyncStatus returns the last state-sync event applied by the engine and
+// the latest one available in heimdall.
+func (api *API) GetStateSyncStatus(ctx context.Context) (*StateSyncStatus, error) {
+	status := &StateSyncStatus{}
+
+	if progress := api.bor.stateSyncProgress.Load(); progress != nil {
+		status.LastAppliedID = progress.lastID
+		status.LastAppliedBlock = progress.lastBlock
+	} else {
+		// Nothing was applied since the start, fall back to the state receiver at the head
+		header := api.chain.CurrentHeader()
+
+		lastStateID, err := api.bor.GenesisContractsClient.LastStateId(nil, header.Number.Uint64(), header.Hash())
+		if err != nil {
+			return nil, err
+		}
+
+		status.LastAppliedID = lastStateID.Uint64()
+	}
+
+	status.HeimdallLatestID = status.LastAppliedID
+
+	if api.bor.HeimdallClient == nil {
+		return status, nil
+	}
+
+	events, err := api.bor.HeimdallClient.StateSyncEvents(ctx, status.LastAppliedID+1, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if event.ID > status.HeimdallLatestID {
+			status.HeimdallLatestID = event.ID
+		}
+	}
+
+	return status, nil
+}
+
+// BlockRangeValidation is the result of the validation of a range of headers.
+type BlockRangeValidation struct {
+	Start        uint64  `json:"start"`
+	End          uint64  `json:"end"`
+	Checked      uint64  `json:"checked"`                // Number of headers validated
+	Valid        bool    `json:"valid"`                  // Whether all the checked headers are valid
+	FirstInvalid *uint64 `json:"firstInvalid,omitempty"` // Number of the first invalid header
+	Reason       string  `json:"reason,omitempty"`       // Why the first invalid header failed the validation
+	NextStart    *uint64 `json:"nextStart,omitempty"`    // Where to resume if the range was capped by MaxValidateBlockRange
+}
+
+// ValidateBlockRange runs the bor header validation (seal, author, difficulty and
+// extra-data, without executing the transactions) over the headers in [start, end]
+// and reports the first invalid one. At most MaxValidateBlockRange headers are checked
+// per call, NextStart tells where to continue from if the range was capped.
+func (api *API) ValidateBlockRange(start uint64, end uint64) (*BlockRangeValidation, error) {
+	currentHeaderNumber := api.chain.CurrentHeader().Number.Uint64()
+
+	if start > end || end > currentHeaderNumber {
+		return nil, &valset.InvalidStartEndBlockError{Start: start, End: end, CurrentHeader: currentHeaderNumber}
+	}
+
+	result := &BlockRangeValidation{
+		Start: start,
+		End:   end,
+		Valid: true,
+	}
+
+	last := end
+	if end-start+1 > MaxValidateBlockRange {
+		last = start + MaxValidateBlockRange - 1
+		nextStart := last + 1
+		result.NextStart = &nextStart
+	}
+
+	for number := start; number <= last; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+
+		result.Checked++
+
+		if err := api.bor.verifyHeader(api.chain, header, nil); err != nil {
+			result.Valid = false
+			result.FirstInvalid = &number
+			result.Reason = err.Error()
+			result.NextStart = nil
+
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// PerfectProposer is a validator which sealed all its in-turn blocks of a range.
+type PerfectProposer struct {
+	Address common.Address `json:"address"`
+	Slots   uint64         `json:"slots"` // Number of in-turn blocks of the validator in the range
+}
+
+// GetPerfectProposers returns the validators which sealed every block they were
+// the in-turn proposer of in [start, end], along with their number of in-turn
+// blocks, the ones with the most first. The in-turn proposer of a block is the one
+// of the snapshot at its parent, as in GetSnapshotProposer. The range is limited
+// to MaxPerfectProposersRange blocks.
+func (api *API) GetPerfectProposers(start uint64, end uint64) ([]PerfectProposer, error) {
+	currentHeaderNumber := api.chain.CurrentHeader().Number.Uint64()
+
+	if start > end || end > currentHeaderNumber {
+		return nil, &valset.InvalidStartEndBlockError{Start: start, End: end, CurrentHeader: currentHeaderNumber}
+	}
+
+	if end-start+1 > MaxPerfectProposersRange {
+		return nil, fmt.Errorf("range of %d blocks exceeds the maximum of %d blocks", end-start+1, MaxPerfectProposersRange)
+	}
+
+	// The genesis block has no proposer
+	if start == 0 {
+		start = 1
+	}
+
+	var (
+		slots  = make(map[common.Address]uint64)
+		missed = make(map[common.Address]bool)
+	)
+
+	for number := start; number <= end; {
+		// The proposer only changes at the end of a sprint
+		sprint := api.bor.config.CalculateSprint(number)
+
+		last := number - number%sprint + sprint - 1
+		if last > end {
+			last = end
+		}
+
+		parent := api.chain.GetHeaderByNumber(number - 1)
+		if parent == nil {
+			return nil, errUnknownBlock
+		}
+
+		snap, err := api.bor.snapshot(api.chain, number-1, parent.Hash(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		proposer := snap.ValidatorSet.GetProposer().Address
+
+		for ; number <= last; number++ {
+			header := api.chain.GetHeaderByNumber(number)
+			if header == nil {
+				return nil, errUnknownBlock
+			}
+
+			author, err := api.bor.Author(header)
+			if err != nil {
+				return nil, err
+			}
+
+			slots[proposer]++
+
+			if author != proposer {
+				missed[proposer] = true
+			}
+		}
+	}
+
+	perfect := make([]PerfectProposer, 0, len(slots))
+
+	for address, count := range slots {
+		if !missed[address] {
+			perfect = append(perfect, PerfectProposer{Address: address, Slots: count})
+		}
+	}
+
+	sort.Slice(perfect, func(i, j int) bool {
+		if perfect[i].Slots != perfect[j].Slots {
+			return perfect[i].Slots > perfect[j].Slots
+		}
+
+		return bytes.Compare(perfect[i].Address[:], perfect[j].Address[:]) < 0
+	})
+
+	return perfect, nil
+}
+
+// HeimdallClientInfo describes the heimdall client used by the engine.
+type HeimdallClientInfo struct {
+	Type     string `json:"type"`            // One of "http", "grpc", "heimdallapp", "none" or "unknown"
+	Endpoint string `json:"endpoint"`        // Url, gRPC address or "childprocess"
+	Proxy    string `json:"proxy,omitempty"` // Url of the caching proxy the calls are routed through, if any
+}
+
+// heimdallClientTyper is implemented by the heimdall clients able to tell their
+// type and the endpoint they target.
+type heimdallClientTyper interface {
+	ClientType() (string, string)
+}
+
+// GetHeimdallClientType returns the type of the heimdall client selected by the
+// node and the endpoint it targets.
+func (api *API) GetHeimdallClientType() *HeimdallClientInfo {
+	if api.bor.HeimdallClient == nil {
+		return &HeimdallClientInfo{Type: "none"}
+	}
+
+	return DescribeHeimdallClient(api.bor.HeimdallClient)
+}
+
+// DescribeHeimdallClient describes the given client, looking through the proxy,
+// recording, concurrency limiting and swapping wrappers.
+func DescribeHeimdallClient(client IHeimdallClient) *HeimdallClientInfo {
+	switch c := client.(type) {
+	case *HeimdallProxyClient:
+		info := DescribeHeimdallClient(c.client)
+		info.Proxy = DescribeHeimdallClient(c.proxy).Endpoint
+
+		return info
+	case *HeimdallLimitedClient:
+		return DescribeHeimdallClient(c.client)
+	case *HeimdallRecordingClient:
+		return DescribeHeimdallClient(c.client)
+	case *HeimdallSwappableClient:
+		return DescribeHeimdallClient(c.Current())
+	case heimdallClientTyper:
+		typ, endpoint := c.ClientType()
+		return &HeimdallClientInfo{Type: typ, Endpoint: endpoint}
+	default:
+		return &HeimdallClientInfo{Type: "unknown"}
+	}
+}
+
+// GetSealHash returns the hash bor signs when sealing the given header, i.e. the
+// hash over the header without the seal of its extra-data, so that remote signers
+// can produce compatible signatures. As the hash depends on the chain's fork
+// configuration, the chain id the caller intends to sign for must match the
+// node's one.
+func (api *API) GetSealHash(header *types.Header, chainID *hexutil.Big) (common.Hash, error) {
+	if chainID == nil || chainID.ToInt().Cmp(api.bor.chainConfig.ChainID) != 0 {
+		return common.Hash{}, &ChainIDMismatchError{Expected: api.bor.chainConfig.ChainID, Actual: chainID.ToInt()}
+	}
+
+	if err := validateHeaderExtraField(header.Extra); err != nil {
+		return common.Hash{}, err
+	}
+
+	return SealHash(header, api.bor.config), nil
+}
+
 // GetRootHash returns the merkle root of the start to end block headers
 func (api *API) GetRootHash(start uint64, end uint64) (string, error) {
 	if err := api.initializeRootHashCache(); err != nil {
@@ -341,6 +1171,127 @@ func (api *API) GetRootHash(start uint64, end uint64) (string, error) {
 	return root, nil
 }
 
+// milestoneVoteTimeout bounds the check of the milestone id against heimdall done
+// by a single VoteOnMilestone call.
+const milestoneVoteTimeout = 10 * time.Second
+
+// MilestoneVote is the vote the node would cast for a proposed milestone.
+type MilestoneVote struct {
+	MilestoneID   string         `json:"milestoneID"`
+	StartBlock    uint64         `json:"startBlock"`
+	EndBlock      uint64         `json:"endBlock"`
+	Validator     bool           `json:"validator"`               // Whether the node signs for a validator of the current set
+	Signer        common.Address `json:"signer"`                  // Signing address of the node, zero if not mining
+	Vote          bool           `json:"vote"`                    // Whether the node agrees with the milestone
+	RootHash      string         `json:"rootHash"`                // Proposed root hash of the range
+	LocalRootHash string         `json:"localRootHash,omitempty"` // Root hash of the range computed on the local chain
+	Reason        string         `json:"reason,omitempty"`        // Why the node votes no
+}
+
+// VoteOnMilestone returns the vote the node would cast for the milestone of the given
+// id proposing rootHash as the root hash of the blocks in [start, end], along with
+// the locally computed root hash. A node which doesn't sign for a validator of the
+// current set never votes. Unlike GetVoteOnHash, no sprint is locked on the vote.
+func (api *API) VoteOnMilestone(ctx context.Context, start uint64, end uint64, rootHash string, milestoneID string) (*MilestoneVote, error) {
+	if milestoneID == "" {
+		return nil, errors.New("empty milestone id")
+	}
+
+	localRoot, err := api.GetRootHash(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	vote := &MilestoneVote{
+		MilestoneID:   milestoneID,
+		StartBlock:    start,
+		EndBlock:      end,
+		RootHash:      rootHash,
+		LocalRootHash: localRoot,
+	}
+
+	if current := api.bor.authorizedSigner.Load(); current != nil {
+		vote.Signer = current.signer
+	}
+
+	snap, err := api.GetSnapshot(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote.Signer != (common.Address{}) && snap.ValidatorSet.HasAddress(vote.Signer) {
+		vote.Validator = true
+	}
+
+	if !vote.Validator {
+		vote.Reason = "not a validator"
+		return vote, nil
+	}
+
+	// Only validators vote, record the vote once decided
+	defer func() {
+		api.bor.RecordMilestoneVote(milestoneID, start, end, rootHash, vote.Vote)
+	}()
+
+	if !strings.EqualFold(strings.TrimPrefix(rootHash, "0x"), localRoot) {
+		vote.Reason = "root hash mismatch"
+		return vote, nil
+	}
+
+	if api.bor.HeimdallClient == nil {
+		vote.Reason = errHeimdallClientUnavailable.Error()
+		return vote, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, milestoneVoteTimeout)
+	defer cancel()
+
+	if err := api.bor.HeimdallClient.FetchMilestoneID(ctx, milestoneID); err != nil {
+		vote.Reason = fmt.Sprintf("milestone id unknown to heimdall: %v", err)
+		return vote, nil
+	}
+
+	vote.Vote = true
+
+	return vote, nil
+}
+
+// GetLastSpanChange returns the validators added, removed and whose voting power
+// changed with the latest span committed by the node, along with the block the
+// change takes effect at.
+func (api *API) GetLastSpanChange(ctx context.Context) (*SpanChange, error) {
+	change, err := api.bor.LastSpanChange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if change == nil {
+		return nil, errNoSpanChange
+	}
+
+	return change, nil
+}
+
+// GetMilestoneVoteHistory returns up to limit of the votes cast by the node on
+// milestones, through GetVoteOnHash or VoteOnMilestone, newest first. The history
+// is persisted and bounded to the last maxMilestoneVoteHistory votes.
+func (api *API) GetMilestoneVoteHistory(limit int) ([]*rawdb.MilestoneVoteEntry, error) {
+	if limit <= 0 || limit > maxMilestoneVoteHistory {
+		limit = maxMilestoneVoteHistory
+	}
+
+	entries, err := rawdb.ReadMilestoneVoteHistory(api.bor.db, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []*rawdb.MilestoneVoteEntry{}
+	}
+
+	return entries, nil
+}
+
 func (api *API) initializeRootHashCache() error {
 	var err error
 	if api.rootHashCache == nil {
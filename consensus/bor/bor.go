@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
+	"runtime"
 	"sort"
 	"strconv"
 	"sync"
@@ -36,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -69,6 +72,10 @@ var (
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
 
+	// errUnknownSpanCommit is returned when the block committing the initial span,
+	// which isn't committed by any block, is requested.
+	errUnknownSpanCommit = errors.New("span isn't committed by any block")
+
 	// errMissingVanity is returned if a block's extra-data section is shorter than
 	// 32 bytes, which is required to store the signer vanity.
 	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
@@ -104,6 +111,25 @@ var (
 
 	errUncleDetected     = errors.New("uncles not allowed")
 	errUnknownValidators = errors.New("unknown validators")
+
+	// errStateSyncPaused is returned when sealing while the state-sync is paused
+	errStateSyncPaused = errors.New("sealing paused, state-sync is paused")
+
+	// errHeimdallClientUnavailable is returned when heimdall is needed but the
+	// engine runs without it
+	errHeimdallClientUnavailable = errors.New("heimdall client not available")
+
+	// errHeimdallClientNotSwappable is returned when swapping the heimdall client
+	// of an engine created without a swappable one
+	errHeimdallClientNotSwappable = errors.New("heimdall client not swappable")
+
+	// errUndersizedSpan is returned when the span from heimdall has fewer validators
+	// than the chain's minimum, refusing the block committing it
+	errUndersizedSpan = errors.New("too few validators in the span")
+
+	// errOversizedSpan is returned when the span from heimdall has more validators
+	// than the chain's maximum, refusing the block committing it
+	errOversizedSpan = errors.New("too many validators in the span")
 )
 
 // SignerFn is a signer callback function to request a header to be signed by a
@@ -194,6 +220,18 @@ func CalcProducerDelay(number uint64, succession int, c *params.BorConfig) uint6
 	return delay
 }
 
+// CalcSignerProducerDelay is CalcProducerDelay for the given signer, using its
+// out-of-turn delay from the chain config instead of the backup multiplier if it
+// has one.
+func CalcSignerProducerDelay(number uint64, succession int, signer common.Address, c *params.BorConfig) uint64 {
+	backupDelay, ok := c.CalculateOutOfTurnDelay(number, signer)
+	if !ok || succession == 0 {
+		return CalcProducerDelay(number, succession, c)
+	}
+
+	return CalcProducerDelay(number, 0, c) + uint64(succession)*backupDelay
+}
+
 // BorRLP returns the rlp bytes which needs to be signed for the bor
 // sealing. The RLP to sign consists of the entire header apart from the 65 byte signature
 // contained at the end of the extra data.
@@ -219,14 +257,50 @@ type Bor struct {
 
 	authorizedSigner atomic.Pointer[signer] // Ethereum address and sign function of the signing key
 
+	stateSyncProgress atomic.Pointer[stateSyncProgress] // Last state-sync event applied by the engine
+
 	ethAPI                 api.Caller
 	spanner                Spanner
 	GenesisContractsClient GenesisContract
 	HeimdallClient         IHeimdallClient
+	spanProvider           SpanProvider             // Overrides the spans source, derived from HeimdallClient if nil
+	swappableHeimdall      *HeimdallSwappableClient // Client swapped by SwapHeimdallClient, wrapped by HeimdallClient (nil = HeimdallClient itself)
+
+	strictExtraData            bool   // Validate the whole extra-data layout early in VerifyHeader
+	strictDifficulty           bool   // Check the difficulty of the signer's turn before the validator list, not only in verifySeal
+	futureBlockTolerance       uint64 // Seconds a header's timestamp may be ahead of the local clock, for clock skew
+	snapshotCheckpointInterval uint64 // Number of blocks after which to save the snapshot to the database (0 = checkpointInterval)
+	maxSnapshotWalkback        uint64 // Most headers walked back to reconstruct a snapshot (0 = unbounded)
+	disallowOutOfTurn          bool   // Only seal blocks when in-turn, the blocks of the other signers are still accepted
+	maxSpanStaleness           uint64 // Pause sealing this close to the end of the span until the next span is fetched (0 = disabled)
+	recentsLimitPercent        uint64 // Maximum size of the snapshot recents, in percent of the validator set (0 = defaultRecentsLimitPercent)
+	verifySpanCommit           bool   // Check the span committed at a span boundary against heimdall before sealing
+	verifyConcurrency          int    // Maximum number of headers verified concurrently in a batch (0 = number of CPUs)
+	sealStopBeforeSpanChange   uint64 // Stop sealing this many blocks before a span the signer isn't a producer of (0 = disabled)
+
+	parallelStateSync int // Maximum number of state receivers whose state-sync events are executed speculatively in parallel (0 = disabled)
+
+	genesisSpanSource GenesisSpanSource // Source of the validator set of the genesis snapshot ("" = contract)
+
+	systemTxProviders []SystemTxProvider // Extra system transactions applied at the start of every sprint
+
+	latestFetchedSpanID atomic.Uint64 // Newest span fetched from heimdall
+	stateSyncPaused     atomic.Bool   // Whether sealing is paused for a state-sync maintenance window
+
+	eligibilityFeed event.Feed   // Changes of the membership of the local signer in the active set
+	eligibility     *eligibility // Last known membership of the local signer, nil until the first sprint start
+	eligibilityLock sync.Mutex   // Protects eligibility
+
+	milestoneVoteLock sync.Mutex // Serializes the writes to the milestone vote history
+
+	lastSpanCommit *spanCommit // Latest span committed by an imported block, nil if unknown
+	lastSpanChange *SpanChange // Validator set changes of lastSpanCommit, nil until resolved
+	spanChangeLock sync.Mutex  // Protects lastSpanCommit and lastSpanChange
 
 	// The fields below are for testing only
-	fakeDiff      bool // Skip difficulty verifications
-	devFakeAuthor bool
+	fakeDiff       bool // Skip difficulty verifications
+	devFakeAuthor  bool
+	devFakeAuthors []common.Address // Fake authors rotated per sprint in DevFakeAuthor mode
 
 	closeOnce sync.Once
 }
@@ -245,6 +319,7 @@ func New(
 	heimdallClient IHeimdallClient,
 	genesisContracts GenesisContract,
 	devFakeAuthor bool,
+	opts ...Option,
 ) *Bor {
 	// get bor config
 	borConfig := chainConfig.Bor
@@ -270,6 +345,10 @@ func New(
 		devFakeAuthor:          devFakeAuthor,
 	}
 
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	c.authorizedSigner.Store(&signer{
 		common.Address{},
 		func(_ accounts.Account, _ string, i []byte) ([]byte, error) {
@@ -311,17 +390,70 @@ func (c *Bor) SetSpanner(spanner Spanner) {
 // method returns a quit channel to abort the operations and a results channel to
 // retrieve the async verifications (the order is that of the input slice).
 func (c *Bor) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	workers := c.verifyConcurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return verifyConcurrently(len(headers), workers, func(i int) error {
+		return c.verifyHeader(chain, headers[i], headers[:i])
+	})
+}
+
+// verifyConcurrently runs verify over the n items of a batch on up to the given
+// number of workers, and delivers the results in the order of the items.
+func verifyConcurrently(n int, workers int, verify func(i int) error) (chan<- struct{}, <-chan error) {
 	abort := make(chan struct{})
-	results := make(chan error, len(headers))
+	results := make(chan error, n)
+
+	if n == 0 {
+		return abort, results
+	}
+
+	if workers > n {
+		workers = n
+	}
+
+	var (
+		inputs = make(chan int)
+		done   = make(chan int, workers)
+		errs   = make([]error, n)
+	)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for index := range inputs {
+				errs[index] = verify(index)
+				done <- index
+			}
+		}()
+	}
 
 	go func() {
-		for i, header := range headers {
-			err := c.verifyHeader(chain, header, headers[:i])
+		defer close(inputs)
+
+		var (
+			in, out = 0, 0
+			checked = make([]bool, n)
+			inputs  = inputs
+		)
 
+		for {
 			select {
+			case inputs <- in:
+				if in++; in == n {
+					// Reached end of headers. Stop sending to workers.
+					inputs = nil
+				}
+			case index := <-done:
+				for checked[index] = true; checked[out]; out++ {
+					results <- errs[out]
+					if out == n-1 {
+						return
+					}
+				}
 			case <-abort:
 				return
-			case results <- err:
 			}
 		}
 	}()
@@ -329,6 +461,67 @@ func (c *Bor) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.
 	return abort, results
 }
 
+// ValidateFutureBlockTolerance checks that the future block tolerance doesn't
+// exceed the shortest block period, so that a block can't be accepted before its
+// parent is due.
+func ValidateFutureBlockTolerance(config *params.BorConfig, seconds uint64) error {
+	if seconds == 0 || len(config.Period) == 0 {
+		return nil
+	}
+
+	shortest := uint64(math.MaxUint64)
+	for _, period := range config.Period {
+		shortest = min(shortest, period)
+	}
+
+	if seconds > shortest {
+		return fmt.Errorf("future block tolerance %ds exceeds the shortest block period %ds", seconds, shortest)
+	}
+
+	return nil
+}
+
+// ValidateSpanSprintAlignment checks that the sprint schedule keeps the spans, a
+// whole number of sprints long, aligned with the sprints across the forks: every
+// sprint length is above zero, and a fork changing it starts on a sprint boundary
+// with a divisor of the previous length, so that the sprints of the spans remain
+// whole ones.
+func ValidateSpanSprintAlignment(config *params.BorConfig) error {
+	type fork struct {
+		number uint64
+		sprint uint64
+	}
+
+	forks := make([]fork, 0, len(config.Sprint))
+
+	for key, sprint := range config.Sprint {
+		number, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid sprint fork block %q: %w", key, err)
+		}
+
+		forks = append(forks, fork{number, sprint})
+	}
+
+	sort.Slice(forks, func(i, j int) bool { return forks[i].number < forks[j].number })
+
+	for i, f := range forks {
+		if f.sprint == 0 {
+			return fmt.Errorf("sprint length from block %d is zero", f.number)
+		}
+
+		if f.number%f.sprint != 0 {
+			return fmt.Errorf("sprint length %d from block %d doesn't start on a sprint boundary", f.sprint, f.number)
+		}
+
+		if i > 0 && forks[i-1].sprint%f.sprint != 0 {
+			return fmt.Errorf("sprint length %d from block %d doesn't divide the previous sprint length %d", f.sprint, f.number, forks[i-1].sprint)
+		}
+	}
+
+	return nil
+}
+
 // verifyHeader checks whether a header conforms to the consensus rules.The
 // caller may optionally pass in a batch of parents (ascending order) to avoid
 // looking those up from the database. This is useful for concurrently verifying
@@ -341,10 +534,17 @@ func (c *Bor) verifyHeader(chain consensus.ChainHeaderReader, header *types.Head
 	number := header.Number.Uint64()
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(time.Now().Unix())+c.futureBlockTolerance {
+		futureBlockDeferredCounter.Inc(1)
 		return consensus.ErrFutureBlock
 	}
 
+	if c.strictExtraData {
+		if err := c.validateExtraDataStrict(header); err != nil {
+			return err
+		}
+	}
+
 	if err := validateHeaderExtraField(header.Extra); err != nil {
 		return err
 	}
@@ -394,6 +594,18 @@ func (c *Bor) verifyHeader(chain consensus.ChainHeaderReader, header *types.Head
 	return c.verifyCascadingFields(chain, header, parents)
 }
 
+// validateSignerDifficulty checks that the difficulty of the header is the one of
+// the turn of its signer in the validator set of the snapshot.
+func validateSignerDifficulty(snap *Snapshot, header *types.Header, signer common.Address) error {
+	difficulty := Difficulty(snap.ValidatorSet, signer)
+
+	if !header.Difficulty.IsUint64() || header.Difficulty.Uint64() != difficulty {
+		return &WrongDifficultyError{header.Number.Uint64(), difficulty, header.Difficulty.Uint64(), signer.Bytes()}
+	}
+
+	return nil
+}
+
 // validateHeaderExtraField validates that the extra-data contains both the vanity and signature.
 // header.Extra = header.Vanity + header.ProducerBytes (optional) + header.Seal
 func validateHeaderExtraField(extraBytes []byte) error {
@@ -452,7 +664,12 @@ func (c *Bor) verifyCascadingFields(chain consensus.ChainHeaderReader, header *t
 		return err
 	}
 
+	// There's no tolerance for clock skew here: verifySeal enforces the producer
+	// delay, which is at least the period, so a tolerance bounded below the period
+	// would never accept a header, and one applied to the producer delay too would
+	// accept the blocks the other nodes reject.
 	if parent.Time+c.config.CalculatePeriod(number) > header.Time {
+		periodViolationCounter.Inc(1)
 		return ErrInvalidTimestamp
 	}
 
@@ -462,6 +679,24 @@ func (c *Bor) verifyCascadingFields(chain consensus.ChainHeaderReader, header *t
 		return err
 	}
 
+	// Reject a wrong difficulty, otherwise only checked at the end of verifySeal,
+	// before the more expensive checks of the validator list
+	if c.strictDifficulty && !c.fakeDiff {
+		signer, err := ecrecover(header, c.signatures, c.config)
+		if err != nil {
+			return err
+		}
+
+		if !snap.ValidatorSet.HasAddress(signer) {
+			return &UnauthorizedSignerError{number - 1, signer.Bytes()}
+		}
+
+		if err := validateSignerDifficulty(snap, header, signer); err != nil {
+			wrongDifficultyCounter.Inc(1)
+			return err
+		}
+	}
+
 	// Verify the validator list match the local contract
 	if IsSprintStart(number+1, c.config.CalculateSprint(number)) {
 		newValidators, err := c.spanner.GetCurrentValidatorsByBlockNrOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), number+1)
@@ -518,6 +753,10 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 	if c.devFakeAuthor && signer.String() != "0x0000000000000000000000000000000000000000" {
 		log.Info("👨‍💻Using DevFakeAuthor", "signer", signer)
 
+		if len(c.devFakeAuthors) > 0 {
+			return c.devFakeAuthorsSnapshot(signer, number, hash), nil
+		}
+
 		val := valset.NewValidator(signer, 1000)
 		validatorset := valset.NewValidatorSet([]*valset.Validator{val})
 
@@ -529,6 +768,9 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 	var snap *Snapshot
 
 	headers := make([]*types.Header, 0, 16)
+	interval := c.getSnapshotCheckpointInterval()
+	rebuildStart := time.Now()
+	loadedFromDisk := false
 
 	//nolint:govet
 	for snap == nil {
@@ -539,12 +781,14 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 			break
 		}
 
-		// If an on-disk checkpoint snapshot can be found, use that
-		if number%checkpointInterval == 0 {
+		// If an on-disk checkpoint snapshot can be found, use that. Snapshots are
+		// stored by hash, so the ones of blocks reorged out are never picked up.
+		if number%interval == 0 || number%checkpointInterval == 0 {
 			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
 				log.Trace("Loaded snapshot from disk", "number", number, "hash", hash)
 
 				snap = s
+				loadedFromDisk = true
 
 				break
 			}
@@ -564,7 +808,7 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 				hash := checkpoint.Hash()
 
 				// get validators and current span
-				validators, err := c.spanner.GetCurrentValidatorsByHash(context.Background(), hash, number+1)
+				validators, err := c.genesisValidators(context.Background(), hash)
 				if err != nil {
 					return nil, err
 				}
@@ -581,7 +825,13 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 			}
 		}
 
-		// No snapshot for this header, gather the header and move backward
+		// No snapshot for this header, gather the header and move backward, up to
+		// the limit if any as the checkpoint snapshot is missing if it's not found by then
+		if c.maxSnapshotWalkback > 0 && uint64(len(headers)) >= c.maxSnapshotWalkback {
+			snapshotWalkbackExceededCounter.Inc(1)
+			return nil, &SnapshotWalkbackError{Number: number, Hash: hash, Walked: uint64(len(headers)), Max: c.maxSnapshotWalkback}
+		}
+
 		var header *types.Header
 		if len(parents) > 0 {
 			// If we have explicit parents, pick from there (enforced)
@@ -613,15 +863,19 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
 
-	snap, err := snap.apply(headers)
+	snap, err := snap.apply(headers, c.recentsLimitPercent)
 	if err != nil {
 		return nil, err
 	}
 
 	c.recents.Add(snap.Hash, snap)
 
+	if loadedFromDisk {
+		snapshotRebuildTimer.UpdateSince(rebuildStart)
+	}
+
 	// If we've generated a new checkpoint snapshot, save to disk
-	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+	if snap.Number%interval == 0 && len(headers) > 0 {
 		if err = snap.store(c.db); err != nil {
 			return nil, err
 		}
@@ -632,6 +886,42 @@ func (c *Bor) snapshot(chain consensus.ChainHeaderReader, number uint64, hash co
 	return snap, err
 }
 
+// devFakeAuthorsSnapshot returns the snapshot used in DevFakeAuthor mode when fake
+// authors are configured: all of them (and the local signer, so that it can keep
+// sealing) are validators, and the proposer rotates through the fake authors at
+// every sprint, like the real proposer rotation.
+func (c *Bor) devFakeAuthorsSnapshot(signer common.Address, number uint64, hash common.Hash) *Snapshot {
+	validators := make([]*valset.Validator, 0, len(c.devFakeAuthors)+1)
+	seen := make(map[common.Address]struct{}, len(c.devFakeAuthors)+1)
+
+	for _, author := range append([]common.Address{signer}, c.devFakeAuthors...) {
+		if _, ok := seen[author]; !ok {
+			seen[author] = struct{}{}
+			validators = append(validators, valset.NewValidator(author, 1000))
+		}
+	}
+
+	snapshot := newSnapshot(c.config, c.signatures, number, hash, validators)
+
+	// The snapshot at number is used for the next block
+	next := number + 1
+	proposer := c.devFakeAuthors[(next/c.config.CalculateSprint(next))%uint64(len(c.devFakeAuthors))]
+
+	_, snapshot.ValidatorSet.Proposer = snapshot.ValidatorSet.GetByAddress(proposer)
+
+	return snapshot
+}
+
+// getSnapshotCheckpointInterval returns the number of blocks after which a
+// snapshot is stored to the database.
+func (c *Bor) getSnapshotCheckpointInterval() uint64 {
+	if c.snapshotCheckpointInterval == 0 {
+		return checkpointInterval
+	}
+
+	return c.snapshotCheckpointInterval
+}
+
 // VerifyUncles implements consensus.Engine, always returning an error for any
 // uncles as this consensus mechanism doesn't permit uncles.
 func (c *Bor) VerifyUncles(_ consensus.ChainReader, block *types.Block) error {
@@ -687,23 +977,39 @@ func (c *Bor) verifySeal(chain consensus.ChainHeaderReader, header *types.Header
 		parent = chain.GetHeader(header.ParentHash, number-1)
 	}
 
-	if IsBlockOnTime(parent, header, number, succession, c.config) {
+	if IsBlockOnTime(parent, header, number, succession, signer, c.config) {
+		periodViolationCounter.Inc(1)
 		return &BlockTooSoonError{number, succession}
 	}
 
 	// Ensure that the difficulty corresponds to the turn-ness of the signer
 	if !c.fakeDiff {
-		difficulty := Difficulty(snap.ValidatorSet, signer)
-		if header.Difficulty.Uint64() != difficulty {
-			return &WrongDifficultyError{number, difficulty, header.Difficulty.Uint64(), signer.Bytes()}
+		if err := validateSignerDifficulty(snap, header, signer); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func IsBlockOnTime(parent *types.Header, header *types.Header, number uint64, succession int, cfg *params.BorConfig) bool {
-	return parent != nil && header.Time < parent.Time+CalcProducerDelay(number, succession, cfg)
+func IsBlockOnTime(parent *types.Header, header *types.Header, number uint64, succession int, signer common.Address, cfg *params.BorConfig) bool {
+	return parent != nil && header.Time < parent.Time+CalcSignerProducerDelay(number, succession, signer, cfg)
+}
+
+// FeeRecipient implements consensus.FeeRecipientEngine, returning the fee recipient
+// set in the chain config for the block if any.
+func (c *Bor) FeeRecipient(header *types.Header) *common.Address {
+	recipient := c.config.CalculateFeeRecipient(header.Number.Uint64())
+	if recipient == "" {
+		return nil
+	}
+
+	address := common.HexToAddress(recipient)
+	if address == (common.Address{}) {
+		return nil
+	}
+
+	return &address
 }
 
 // Prepare implements consensus.Engine, preparing all the consensus fields of the
@@ -734,11 +1040,16 @@ func (c *Bor) Prepare(chain consensus.ChainHeaderReader, header *types.Header) e
 
 	// get validator set if number
 	if IsSprintStart(number+1, c.config.CalculateSprint(number)) {
-		newValidators, err := c.spanner.GetCurrentValidatorsByHash(context.Background(), header.ParentHash, number+1)
-		if err != nil {
+		parent := chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
 			return errUnknownValidators
 		}
 
+		newValidators, err := c.getValidatorsWithRetry(context.Background(), chain, parent, number+1)
+		if err != nil {
+			return err
+		}
+
 		// sort validator by address
 		sort.Sort(valset.ValidatorsByAddress(newValidators))
 
@@ -802,7 +1113,7 @@ func (c *Bor) Prepare(chain consensus.ChainHeaderReader, header *types.Header) e
 		}
 	}
 
-	header.Time = parent.Time + CalcProducerDelay(number, succession, c.config)
+	header.Time = parent.Time + CalcSignerProducerDelay(number, succession, currentSigner.signer, c.config)
 	if header.Time < uint64(time.Now().Unix()) {
 		header.Time = uint64(time.Now().Unix())
 	}
@@ -815,6 +1126,7 @@ func (c *Bor) Prepare(chain consensus.ChainHeaderReader, header *types.Header) e
 func (c *Bor) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, _ []*types.Transaction, _ []*types.Header, withdrawals []*types.Withdrawal) {
 	var (
 		stateSyncData []*types.StateSyncData
+		committedSpan *span.HeimdallSpan
 		err           error
 	)
 
@@ -827,20 +1139,38 @@ func (c *Bor) Finalize(chain consensus.ChainHeaderReader, header *types.Header,
 	if IsSprintStart(headerNumber, c.config.CalculateSprint(headerNumber)) {
 		ctx := context.Background()
 		cx := statefull.ChainContext{Chain: chain, Bor: c}
+		timers := c.blockTimers(headerNumber)
+
+		c.checkEligibility(chain, header)
+
 		// check and commit span
-		if err := c.checkAndCommitSpan(ctx, state, header, cx); err != nil {
+		start := time.Now()
+		if committedSpan, err = c.checkAndCommitSpan(ctx, state, header, cx); err != nil {
 			log.Error("Error while committing span", "error", err)
 			return
 		}
+		timers.spanCommit.UpdateSince(start)
+
+		if committedSpan != nil {
+			c.recordSpanCommit(header, committedSpan)
+		}
 
 		if c.HeimdallClient != nil {
 			// commit states
+			start = time.Now()
 			stateSyncData, err = c.CommitStates(ctx, state, header, cx)
+			timers.stateSync.UpdateSince(start)
+
 			if err != nil {
 				log.Error("Error while committing states", "error", err)
 				return
 			}
 		}
+
+		if err = c.applySystemTxs(ctx, state, header, cx); err != nil {
+			log.Error("Error while applying system transactions", "error", err)
+			return
+		}
 	}
 
 	if err = c.changeContractCodeIfNeeded(headerNumber, state); err != nil {
@@ -909,27 +1239,56 @@ func (c *Bor) FinalizeAndAssemble(ctx context.Context, chain consensus.ChainHead
 	if IsSprintStart(headerNumber, c.config.CalculateSprint(headerNumber)) {
 		cx := statefull.ChainContext{Chain: chain, Bor: c}
 
+		c.checkEligibility(chain, header)
+
+		var committedSpan *span.HeimdallSpan
+
+		timers := c.blockTimers(headerNumber)
+		start := time.Now()
+
 		tracing.Exec(finalizeCtx, "", "bor.checkAndCommitSpan", func(ctx context.Context, span trace.Span) {
 			// check and commit span
-			err = c.checkAndCommitSpan(finalizeCtx, state, header, cx)
+			committedSpan, err = c.checkAndCommitSpan(finalizeCtx, state, header, cx)
 		})
 
+		timers.spanCommit.UpdateSince(start)
+
 		if err != nil {
 			log.Error("Error while committing span", "error", err)
 			return nil, err
 		}
 
+		if committedSpan != nil && c.verifySpanCommit {
+			if err = c.verifyCommittedSpan(finalizeCtx, header, committedSpan); err != nil {
+				log.Error("Sealing paused, the committed span doesn't match heimdall", "error", err)
+				return nil, err
+			}
+		}
+
 		if c.HeimdallClient != nil {
+			start = time.Now()
+
 			tracing.Exec(finalizeCtx, "", "bor.checkAndCommitSpan", func(ctx context.Context, span trace.Span) {
 				// commit states
 				stateSyncData, err = c.CommitStates(finalizeCtx, state, header, cx)
 			})
 
+			timers.stateSync.UpdateSince(start)
+
 			if err != nil {
 				log.Error("Error while committing states", "error", err)
 				return nil, err
 			}
 		}
+
+		tracing.Exec(finalizeCtx, "", "bor.applySystemTxs", func(ctx context.Context, span trace.Span) {
+			err = c.applySystemTxs(finalizeCtx, state, header, cx)
+		})
+
+		if err != nil {
+			log.Error("Error while applying system transactions", "error", err)
+			return nil, err
+		}
 	}
 
 	tracing.Exec(finalizeCtx, "", "bor.changeContractCodeIfNeeded", func(ctx context.Context, span trace.Span) {
@@ -968,6 +1327,21 @@ func (c *Bor) FinalizeAndAssemble(ctx context.Context, chain consensus.ChainHead
 	return block, nil
 }
 
+// RecordTxExecution records the time spent executing the transactions of a block
+// built locally, along with the breakdown of the engine's processing of the block.
+func (c *Bor) RecordTxExecution(header *types.Header, elapsed time.Duration) {
+	c.blockTimers(header.Number.Uint64()).txs.Update(elapsed)
+}
+
+// blockTimers returns the timers breaking down the processing of the given block.
+func (c *Bor) blockTimers(number uint64) *blockTimers {
+	if IsSprintStart(number, c.config.CalculateSprint(number)) {
+		return sprintBlockTimers
+	}
+
+	return regularBlockTimers
+}
+
 // Authorize injects a private key into the consensus engine to mint new blocks
 // with.
 func (c *Bor) Authorize(currentSigner common.Address, signFn SignerFn) {
@@ -977,11 +1351,46 @@ func (c *Bor) Authorize(currentSigner common.Address, signFn SignerFn) {
 	})
 }
 
+// SealingSlot is a block the local signer is the in-turn proposer of.
+type SealingSlot struct {
+	Number uint64         // Number of the block
+	Signer common.Address // Local signer
+	Due    uint64         // Time the block is due at, in unix seconds
+}
+
+// InTurnSlot returns the slot of the block after parent if the local signer is
+// its in-turn proposer, nil otherwise.
+func (c *Bor) InTurnSlot(chain consensus.ChainHeaderReader, parent *types.Header) (*SealingSlot, error) {
+	current := c.authorizedSigner.Load()
+	if current == nil || current.signer == (common.Address{}) {
+		return nil, nil
+	}
+
+	snap, err := c.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if proposer := snap.ValidatorSet.GetProposer(); proposer == nil || proposer.Address != current.signer {
+		return nil, nil
+	}
+
+	number := parent.Number.Uint64() + 1
+
+	return &SealingSlot{
+		Number: number,
+		Signer: current.signer,
+		Due:    parent.Time + CalcSignerProducerDelay(number, 0, current.signer, c.config),
+	}, nil
+}
+
 // Seal implements consensus.Engine, attempting to create a sealed block using
 // the local signing credentials.
 func (c *Bor) Seal(ctx context.Context, chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
 	_, sealSpan := tracing.StartSpan(ctx, "bor.Seal")
 
+	start := time.Now()
+
 	var endSpan bool = true
 
 	defer func() {
@@ -1022,10 +1431,30 @@ func (c *Bor) Seal(ctx context.Context, chain consensus.ChainHeaderReader, block
 		return err
 	}
 
+	// Bail out if we're not in-turn and out-of-turn sealing is disabled
+	if c.disallowOutOfTurn && successionNumber != 0 {
+		return &OutOfTurnError{number, currentSigner.signer.Bytes(), successionNumber}
+	}
+
+	// Bail out if the state-sync is paused, the block could miss state-sync events
+	if c.stateSyncPaused.Load() {
+		return errStateSyncPaused
+	}
+
+	// Bail out if the span is about to end and the next one can't be fetched
+	if err := c.checkSpanStaleness(ctx, header); err != nil {
+		return err
+	}
+
+	// Bail out if the span is about to end and the signer isn't a producer of the next one
+	if err := c.checkSealStopBeforeSpanChange(ctx, header, currentSigner.signer); err != nil {
+		return err
+	}
+
 	// Sweet, the protocol permits us to sign the block, wait for our time
 	delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
 	// wiggle was already accounted for in header.Time, this is just for logging
-	wiggle := time.Duration(successionNumber) * time.Duration(c.config.CalculateBackupMultiplier(number)) * time.Second
+	wiggle := time.Duration(CalcSignerProducerDelay(number, successionNumber, currentSigner.signer, c.config)-CalcProducerDelay(number, 0, c.config)) * time.Second
 
 	// Sign all the things!
 	err = Sign(currentSigner.signFn, currentSigner.signer, header, c.config)
@@ -1033,6 +1462,8 @@ func (c *Bor) Seal(ctx context.Context, chain consensus.ChainHeaderReader, block
 		return err
 	}
 
+	c.blockTimers(number).seal.UpdateSince(start)
+
 	// Wait until sealing is terminated or delay timeout.
 	log.Info("Waiting for slot to sign and propagate", "number", number, "hash", header.Hash, "delay-in-sec", uint(delay), "delay", common.PrettyDuration(delay))
 
@@ -1134,24 +1565,26 @@ func (c *Bor) Close() error {
 	return nil
 }
 
+// checkAndCommitSpan commits the next span if the header is the block it's due in,
+// and returns the committed span (nil if none).
 func (c *Bor) checkAndCommitSpan(
 	ctx context.Context,
 	state *state.StateDB,
 	header *types.Header,
 	chain core.ChainContext,
-) error {
+) (*span.HeimdallSpan, error) {
 	headerNumber := header.Number.Uint64()
 
 	span, err := c.spanner.GetCurrentSpan(ctx, header.ParentHash)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if c.needToCommitSpan(span, headerNumber) {
-		return c.FetchAndCommitSpan(ctx, span.ID+1, state, header, chain)
+		return c.fetchAndCommitSpan(ctx, span.ID+1, state, header, chain)
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (c *Bor) needToCommitSpan(currentSpan *span.Span, headerNumber uint64) bool {
@@ -1180,35 +1613,95 @@ func (c *Bor) FetchAndCommitSpan(
 	header *types.Header,
 	chain core.ChainContext,
 ) error {
+	_, err := c.fetchAndCommitSpan(ctx, newSpanID, state, header, chain)
+
+	return err
+}
+
+func (c *Bor) fetchAndCommitSpan(
+	ctx context.Context,
+	newSpanID uint64,
+	state *state.StateDB,
+	header *types.Header,
+	chain core.ChainContext,
+) (*span.HeimdallSpan, error) {
 	var heimdallSpan span.HeimdallSpan
 
-	if c.HeimdallClient == nil {
+	spanProvider := c.getSpanProvider()
+
+	if spanProvider == nil {
 		// fixme: move to a new mock or fake and remove c.HeimdallClient completely
 		s, err := c.getNextHeimdallSpanForTest(ctx, newSpanID, header, chain)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		heimdallSpan = *s
 	} else {
-		response, err := c.HeimdallClient.Span(ctx, newSpanID)
+		response, err := spanProvider.GetSpan(ctx, newSpanID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		heimdallSpan = *response
 	}
 
+	c.recordFetchedSpan(heimdallSpan.ID)
+
 	// check if chain id matches with Heimdall span
 	if heimdallSpan.ChainID != c.chainConfig.ChainID.String() {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"chain id proposed span, %s, and bor chain id, %s, doesn't match",
 			heimdallSpan.ChainID,
 			c.chainConfig.ChainID,
 		)
 	}
 
-	return c.spanner.CommitSpan(ctx, heimdallSpan, state, header, chain)
+	c.checkSpanSprintAlignment(&heimdallSpan)
+
+	// A span with too few validators (a heimdall bug or misconfiguration) would
+	// leave no one to verify the authors against. The block is refused, leaving
+	// the previous validator set in place until heimdall serves a valid span.
+	if validators, minimum := uint64(len(heimdallSpan.ValidatorSet.Validators)), c.config.CalculateMinValidators(header.Number.Uint64()); validators < minimum {
+		undersizedSpanCounter.Inc(1)
+		log.Error("Refusing the span from heimdall, too few validators",
+			"number", header.Number.Uint64(), "span", heimdallSpan.ID, "validators", validators, "min", minimum)
+
+		return nil, fmt.Errorf("%w: span %d has %d, min %d", errUndersizedSpan, heimdallSpan.ID, validators, minimum)
+	}
+
+	// Nor with too many, which would bloat the snapshots and the extra data
+	if validators, maximum := uint64(len(heimdallSpan.ValidatorSet.Validators)), c.config.CalculateMaxValidators(header.Number.Uint64()); maximum > 0 && validators > maximum {
+		oversizedSpanCounter.Inc(1)
+		log.Error("Refusing the span from heimdall, too many validators",
+			"number", header.Number.Uint64(), "span", heimdallSpan.ID, "validators", validators, "max", maximum)
+
+		return nil, fmt.Errorf("%w: span %d has %d, max %d", errOversizedSpan, heimdallSpan.ID, validators, maximum)
+	}
+
+	if err := c.spanner.CommitSpan(ctx, heimdallSpan, state, header, chain); err != nil {
+		return nil, err
+	}
+
+	return &heimdallSpan, nil
+}
+
+// checkSpanSprintAlignment reports a span from heimdall which isn't a whole number
+// of sprints long, as of the sprint length at its start, which would change the
+// validator set in the middle of a sprint. The span is committed regardless, like
+// by the other nodes.
+func (c *Bor) checkSpanSprintAlignment(heimdallSpan *span.HeimdallSpan) bool {
+	sprint := c.config.CalculateSprint(heimdallSpan.StartBlock)
+
+	if sprint == 0 || heimdallSpan.EndBlock < heimdallSpan.StartBlock || (heimdallSpan.EndBlock-heimdallSpan.StartBlock+1)%sprint != 0 {
+		misalignedSpanCounter.Inc(1)
+		log.Error("Span from heimdall not aligned with the sprints", "span", heimdallSpan.ID,
+			"start", heimdallSpan.StartBlock, "end", heimdallSpan.EndBlock, "sprint", sprint)
+
+		return false
+	}
+
+	return true
 }
 
 // CommitStates commit states
@@ -1267,22 +1760,51 @@ func (c *Bor) CommitStates(
 
 	fetchTime := time.Since(fetchStart)
 	processStart := time.Now()
-	totalGas := 0 /// limit on gas for state sync per block
 	chainID := c.chainConfig.ChainID.String()
 	stateSyncs := make([]*types.StateSyncData, 0, len(eventRecords))
+	events := make([]*clerk.EventRecordWithTime, 0, len(eventRecords))
 
-	var gasUsed uint64
+	// The events over the per sprint limit are deferred to the next sprints. As the
+	// limit is part of the chain config, every node defers the same events.
+	maxStateSyncs := c.config.CalculateMaxStateSyncPerSprint(number)
+	maxPayloadBytes := c.config.CalculateMaxStateSyncPayloadBytes(number)
 
-	for _, eventRecord := range eventRecords {
+	for i, eventRecord := range eventRecords {
 		if eventRecord.ID <= lastStateID {
 			continue
 		}
 
+		if maxStateSyncs > 0 && uint64(len(stateSyncs)) >= maxStateSyncs {
+			deferred := len(eventRecords) - i
+			stateSyncDeferredCounter.Inc(int64(deferred))
+
+			log.Info("Deferring state-sync events to the next sprint", "number", number, "limit", maxStateSyncs, "deferred", deferred)
+
+			break
+		}
+
 		if err = validateEventRecord(eventRecord, number, to, lastStateID, chainID); err != nil {
 			log.Error("while validating event record", "block", number, "to", to, "stateID", lastStateID+1, "error", err.Error())
 			break
 		}
 
+		// An oversized event can't be skipped, the block would miss it, so the block
+		// is refused: sealing it fails and importing it fails on the state root
+		if maxPayloadBytes > 0 && uint64(len(eventRecord.Data)) > maxPayloadBytes {
+			stateSyncOversizedCounter.Inc(1)
+
+			err = &StateSyncPayloadTooLargeError{
+				Number:  number,
+				EventID: eventRecord.ID,
+				Size:    len(eventRecord.Data),
+				Limit:   maxPayloadBytes,
+			}
+
+			log.Error("Refusing the block, state-sync event payload too large", "err", err)
+
+			return nil, err
+		}
+
 		stateData := types.StateSyncData{
 			ID:       eventRecord.ID,
 			Contract: eventRecord.Contract,
@@ -1291,23 +1813,21 @@ func (c *Bor) CommitStates(
 		}
 
 		stateSyncs = append(stateSyncs, &stateData)
-
-		// we expect that this call MUST emit an event, otherwise we wouldn't make a receipt
-		// if the receiver address is not a contract then we'll skip the most of the execution and emitting an event as well
-		// https://github.com/maticnetwork/genesis-contracts/blob/master/contracts/StateReceiver.sol#L27
-		gasUsed, err = c.GenesisContractsClient.CommitState(eventRecord, state, header, chain)
-		if err != nil {
-			return nil, err
-		}
-
-		totalGas += int(gasUsed)
+		events = append(events, eventRecord)
 
 		lastStateID++
 	}
 
+	gasUsed, err := c.commitStateSyncEvents(events, state, header, chain)
+	if err != nil {
+		return nil, err
+	}
+
 	processTime := time.Since(processStart)
 
-	log.Info("StateSyncData", "gas", totalGas, "number", number, "lastStateID", lastStateID, "total records", len(eventRecords), "fetch time", int(fetchTime.Milliseconds()), "process time", int(processTime.Milliseconds()))
+	c.recordStateSyncProgress(number, lastStateID)
+
+	log.Info("StateSyncData", "gas", gasUsed, "number", number, "lastStateID", lastStateID, "total records", len(eventRecords), "fetch time", int(fetchTime.Milliseconds()), "process time", int(processTime.Milliseconds()))
 
 	return stateSyncs, nil
 }
@@ -1321,6 +1841,30 @@ func validateEventRecord(eventRecord *clerk.EventRecordWithTime, number uint64,
 	return nil
 }
 
+// PauseStateSync pauses the state-sync for a bridge maintenance window. Skipping
+// the state-sync events would produce invalid blocks, so the node stops sealing
+// instead until ResumeStateSync is called. Blocks sealed by the others are
+// still imported along with their state-sync events.
+func (c *Bor) PauseStateSync() {
+	if c.stateSyncPaused.CompareAndSwap(false, true) {
+		stateSyncPausedGauge.Update(1)
+		log.Warn("State-sync paused, sealing is paused until it's resumed")
+	}
+}
+
+// ResumeStateSync resumes the state-sync and the sealing paused by PauseStateSync.
+func (c *Bor) ResumeStateSync() {
+	if c.stateSyncPaused.CompareAndSwap(true, false) {
+		stateSyncPausedGauge.Update(0)
+		log.Info("State-sync resumed, sealing is resumed")
+	}
+}
+
+// IsStateSyncPaused reports whether the state-sync (and the sealing) is paused.
+func (c *Bor) IsStateSyncPaused() bool {
+	return c.stateSyncPaused.Load()
+}
+
 func (c *Bor) SetHeimdallClient(h IHeimdallClient) {
 	c.HeimdallClient = h
 }
@@ -1,19 +1,37 @@
 package bor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil" //nolint:typecheck
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/contract"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/consensus/bor/valset"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func TestGenesisContractChange(t *testing.T) {
@@ -142,3 +160,1780 @@ func TestEncodeSigHeaderJaipur(t *testing.T) {
 	hash = SealHash(h, &params.BorConfig{JaipurBlock: big.NewInt(10)})
 	require.Equal(t, hash, hashWithoutBaseFee)
 }
+
+func TestStrictExtraDataValidation(t *testing.T) {
+	t.Parallel()
+
+	b := &Bor{
+		config: &params.BorConfig{
+			Sprint: map[string]uint64{
+				"0": 4,
+			},
+		},
+		strictExtraData: true,
+	}
+
+	validator := func(address byte, power uint64) []byte {
+		entry := make([]byte, validatorHeaderBytesLength)
+		entry[common.AddressLength-1] = address
+		new(big.Int).SetUint64(power).FillBytes(entry[common.AddressLength:])
+
+		return entry
+	}
+
+	extra := func(validators ...[]byte) []byte {
+		data := make([]byte, types.ExtraVanityLength)
+		for _, v := range validators {
+			data = append(data, v...)
+		}
+
+		return append(data, make([]byte, types.ExtraSealLength)...)
+	}
+
+	testCases := []struct {
+		name   string
+		number uint64
+		extra  []byte
+		field  string
+	}{
+		{"valid non sprint end", 1, extra(), ""},
+		{"valid sprint end", 3, extra(validator(1, 10), validator(2, 10)), ""},
+		{"short vanity", 1, make([]byte, types.ExtraVanityLength-1), extraFieldVanity},
+		{"short seal", 1, make([]byte, types.ExtraVanityLength+types.ExtraSealLength-1), extraFieldSignature},
+		{"validators on non sprint end", 1, extra(validator(1, 10)), extraFieldValidators},
+		{"no validators on sprint end", 3, extra(), extraFieldValidators},
+		{"invalid validator bytes length", 3, extra(validator(1, 10), []byte{0x1}), extraFieldValidators},
+		{"empty address", 3, extra(validator(0, 10)), extraFieldValidators},
+		{"no power", 3, extra(validator(1, 0)), extraFieldValidators},
+		{"unsorted", 3, extra(validator(2, 10), validator(1, 10)), extraFieldValidators},
+		{"duplicated", 3, extra(validator(1, 10), validator(1, 10)), extraFieldValidators},
+	}
+
+	for _, tc := range testCases {
+		header := &types.Header{
+			Number: new(big.Int).SetUint64(tc.number),
+			Extra:  tc.extra,
+		}
+
+		err := b.validateExtraDataStrict(header)
+		if tc.field == "" {
+			require.NoError(t, err, tc.name)
+			continue
+		}
+
+		var extraErr *InvalidExtraDataError
+
+		require.ErrorAs(t, err, &extraErr, tc.name)
+		require.Equal(t, tc.field, extraErr.Field, tc.name)
+		require.Equal(t, tc.number, extraErr.Number, tc.name)
+	}
+}
+
+func TestSystemTxProvider(t *testing.T) {
+	t.Parallel()
+
+	// Stores 1 at slot 0: PUSH1 0x01 PUSH1 0x00 SSTORE STOP
+	target := common.Address{0x2}
+	code := []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	spanner := NewMockSpanner(ctrl)
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), gomock.Any()).Return(&span.Span{ID: 1, StartBlock: 0, EndBlock: 1000}, nil).AnyTimes()
+
+	borConfig := &params.BorConfig{
+		Sprint: map[string]uint64{
+			"0": 10,
+		},
+	}
+
+	b := &Bor{
+		chainConfig: &params.ChainConfig{ChainID: big.NewInt(1), Bor: borConfig},
+		config:      borConfig,
+		spanner:     spanner,
+	}
+
+	var calls []uint64
+
+	WithSystemTxProviders(func(header *types.Header, _ *state.StateDB) ([]SystemTx, error) {
+		calls = append(calls, header.Number.Uint64())
+		return []SystemTx{{To: target}}, nil
+	})(b)
+
+	genspec := &core.Genesis{
+		Alloc: map[common.Address]core.GenesisAccount{
+			target: {
+				Balance: big.NewInt(0),
+				Code:    code,
+			},
+		},
+		Config: &params.ChainConfig{},
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := genspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genspec, nil, b, vm.Config{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	newHeader := func(number int64) *types.Header {
+		return &types.Header{
+			ParentHash: genesis.Hash(),
+			Number:     big.NewInt(number),
+			Difficulty: big.NewInt(1),
+		}
+	}
+
+	// The provider isn't invoked outside of the sprint start
+	statedb, err := state.New(genesis.Root(), state.NewDatabase(db), nil)
+	require.NoError(t, err)
+
+	_, err = b.FinalizeAndAssemble(context.Background(), chain, newHeader(5), statedb, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+	require.Equal(t, common.Hash{}, statedb.GetState(target, common.Hash{}))
+
+	// The system tx is applied in the sealed block at the sprint start
+	statedb, err = state.New(genesis.Root(), state.NewDatabase(db), nil)
+	require.NoError(t, err)
+
+	block, err := b.FinalizeAndAssemble(context.Background(), chain, newHeader(10), statedb, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10}, calls)
+	require.Equal(t, common.BigToHash(big.NewInt(1)), statedb.GetState(target, common.Hash{}))
+
+	// Verifying the block applies the same system tx and yields the same state root
+	statedb, err = state.New(genesis.Root(), state.NewDatabase(db), nil)
+	require.NoError(t, err)
+
+	header := newHeader(10)
+	b.Finalize(chain, header, statedb, nil, nil, nil)
+	require.Equal(t, []uint64{10, 10}, calls)
+	require.Equal(t, block.Root(), header.Root)
+}
+
+func TestFeeRecipient(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		signer   = common.Address{0x1}
+		treasury = common.Address{0x2}
+		gasPrice = big.NewInt(params.GWei)
+	)
+
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	b := &Bor{
+		config: &params.BorConfig{
+			Sprint: map[string]uint64{
+				"0": 10,
+			},
+			FeeRecipient: map[string]string{
+				"0": "",
+				"1": treasury.Hex(),
+			},
+		},
+		signatures: signatures,
+	}
+
+	genspec := &core.Genesis{
+		Alloc: map[common.Address]core.GenesisAccount{
+			sender: {Balance: big.NewInt(params.Ether)},
+		},
+		Config: &params.ChainConfig{},
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := genspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genspec, nil, b, vm.Config{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	chainConfig := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+		EIP155Block:    big.NewInt(0),
+		EIP158Block:    big.NewInt(0),
+		ByzantiumBlock: big.NewInt(0),
+	}
+
+	header := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.TxGas * 2,
+	}
+
+	// The fees stay with the author before the fee recipient fork
+	require.Nil(t, b.FeeRecipient(&types.Header{Number: big.NewInt(0)}))
+	require.Equal(t, &treasury, b.FeeRecipient(header))
+
+	statedb, err := state.New(genesis.Root(), state.NewDatabase(db), nil)
+	require.NoError(t, err)
+
+	// The fees go to the treasury both when mining (explicit author) and verifying
+	for nonce, author := range []*common.Address{&signer, nil} {
+		tx, err := types.SignTx(types.NewTransaction(uint64(nonce), common.Address{0x3}, big.NewInt(1), params.TxGas, gasPrice, nil), types.NewEIP155Signer(chainConfig.ChainID), key)
+		require.NoError(t, err)
+
+		var usedGas uint64
+
+		_, err = core.ApplyTransaction(chainConfig, chain, author, new(core.GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{}, nil)
+		require.NoError(t, err)
+
+		fees := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(params.TxGas*uint64(nonce+1)))
+		require.Equal(t, fees, statedb.GetBalance(treasury))
+		require.Zero(t, statedb.GetBalance(signer).Sign())
+	}
+}
+
+func TestVerifyGenesisContracts(t *testing.T) {
+	t.Parallel()
+
+	config := &params.BorConfig{
+		ValidatorContract:     "0x0000000000000000000000000000000000001000",
+		StateReceiverContract: "0x0000000000000000000000000000000000001001",
+	}
+
+	validatorContract := common.HexToAddress(config.ValidatorContract)
+	stateReceiverContract := common.HexToAddress(config.StateReceiverContract)
+
+	db := rawdb.NewMemoryDatabase()
+	genspec := &core.Genesis{
+		Alloc: map[common.Address]core.GenesisAccount{
+			validatorContract: {Balance: big.NewInt(0), Code: []byte{0x1}},
+		},
+		Config: &params.ChainConfig{},
+	}
+	genspec.MustCommit(db)
+
+	err := VerifyGenesisContracts(db, config)
+
+	var missing *GenesisContractMissingError
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, stateReceiverContract, missing.Address)
+
+	db = rawdb.NewMemoryDatabase()
+	genspec.Alloc[stateReceiverContract] = core.GenesisAccount{Balance: big.NewInt(0), Code: []byte{0x1}}
+	genspec.MustCommit(db)
+
+	require.NoError(t, VerifyGenesisContracts(db, config))
+
+	// A mapped receiver may not run the code of the default one, which requires
+	// contiguous event ids
+	target, mappedReceiver := common.Address{0x30, 0x01}, common.Address{0x20, 0x01}
+	config.StateReceiverContracts = map[string]map[string]string{"0": {target.Hex(): mappedReceiver.Hex()}}
+
+	db = rawdb.NewMemoryDatabase()
+	genspec.Alloc[mappedReceiver] = core.GenesisAccount{Balance: big.NewInt(0), Code: []byte{0x1}}
+	genspec.MustCommit(db)
+
+	require.NoError(t, VerifyGenesisContracts(db, config))
+
+	var contiguous *StateReceiverContiguousIDsError
+	require.ErrorAs(t, ValidateStateReceiverContracts(db, config), &contiguous)
+	require.Equal(t, target, contiguous.Target)
+	require.Equal(t, mappedReceiver, contiguous.Receiver)
+
+	db = rawdb.NewMemoryDatabase()
+	genspec.Alloc[mappedReceiver] = core.GenesisAccount{Balance: big.NewInt(0), Code: []byte{0x2}}
+	genspec.MustCommit(db)
+
+	require.NoError(t, ValidateStateReceiverContracts(db, config))
+
+	// The codes deployed by the block allocs count too
+	config.BlockAlloc = map[string]interface{}{
+		"32": map[string]interface{}{
+			stateReceiverContract.Hex(): map[string]interface{}{"balance": "0x0", "code": "0x02"},
+		},
+	}
+
+	require.ErrorAs(t, ValidateStateReceiverContracts(db, config), &contiguous)
+}
+
+func TestVerifyConcurrently(t *testing.T) {
+	t.Parallel()
+
+	const (
+		items   = 64
+		workers = 4
+	)
+
+	var inflight, peak atomic.Int32
+
+	_, results := verifyConcurrently(items, workers, func(i int) error {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+
+		for {
+			max := peak.Load()
+			if n <= max || peak.CompareAndSwap(max, n) {
+				break
+			}
+		}
+
+		// Finish the items out of order, to check the results are reordered
+		time.Sleep(time.Duration(items-i) * 100 * time.Microsecond)
+
+		if i%5 == 0 {
+			return fmt.Errorf("item %d", i)
+		}
+
+		return nil
+	})
+
+	for i := 0; i < items; i++ {
+		select {
+		case err := <-results:
+			if i%5 == 0 {
+				require.EqualError(t, err, fmt.Sprintf("item %d", i))
+			} else {
+				require.NoError(t, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for result %d", i)
+		}
+	}
+
+	require.LessOrEqual(t, peak.Load(), int32(workers))
+	require.Greater(t, peak.Load(), int32(1))
+}
+
+func TestEligibilityChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		local = common.Address{0x1}
+		other = common.Address{0x2}
+	)
+
+	config := &params.BorConfig{
+		Sprint: map[string]uint64{
+			"0": 16,
+		},
+	}
+
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	spanner := NewMockSpanner(ctrl)
+
+	b := &Bor{
+		config:  config,
+		recents: recents,
+		spanner: spanner,
+	}
+	b.authorizedSigner.Store(&signer{signer: local})
+
+	changes := make(chan EligibilityChange, 4)
+	sub := b.SubscribeEligibilityChange(changes)
+	defer sub.Unsubscribe()
+
+	// sprintStart returns the header of the sprint start block, with a parent
+	// snapshot made of the given validators
+	sprintStart := func(number uint64, validators ...common.Address) *types.Header {
+		parent := common.Hash{byte(number)}
+
+		valz := make([]*valset.Validator, len(validators))
+		for i, val := range validators {
+			valz[i] = valset.NewValidator(val, 10)
+		}
+
+		recents.Add(parent, newSnapshot(config, nil, number-1, parent, valz))
+
+		return &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: parent}
+	}
+
+	// The first sprint start only records the eligibility
+	b.checkEligibility(nil, sprintStart(16, local, other))
+	require.Empty(t, changes)
+
+	// An unchanged eligibility isn't reported
+	b.checkEligibility(nil, sprintStart(32, local, other))
+	require.Empty(t, changes)
+
+	// Removal at a span start
+	header := sprintStart(48, other)
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), header.ParentHash).Return(&span.Span{ID: 2, StartBlock: 32, EndBlock: 47}, nil)
+
+	b.checkEligibility(nil, header)
+	require.Equal(t, EligibilityChange{Signer: local, Previous: true, Eligible: false, SpanID: 3, Number: 48}, <-changes)
+
+	// An older sprint start, e.g. of a sidechain, is ignored
+	b.checkEligibility(nil, sprintStart(32, local, other))
+	require.Empty(t, changes)
+
+	// Addition back
+	header = sprintStart(64, local)
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), header.ParentHash).Return(&span.Span{ID: 3, StartBlock: 48, EndBlock: 111}, nil)
+
+	b.checkEligibility(nil, header)
+	require.Equal(t, EligibilityChange{Signer: local, Previous: false, Eligible: true, SpanID: 3, Number: 64}, <-changes)
+}
+
+// staticSpanProvider serves the same span for any id.
+type staticSpanProvider struct {
+	span *span.HeimdallSpan
+}
+
+func (p *staticSpanProvider) GetSpan(context.Context, uint64) (*span.HeimdallSpan, error) {
+	return p.span, nil
+}
+
+func (p *staticSpanProvider) GetCurrentSpan(context.Context) (*span.HeimdallSpan, error) {
+	return p.span, nil
+}
+
+func TestMinValidators(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	spanner := NewMockSpanner(ctrl)
+	provider := &staticSpanProvider{span: &span.HeimdallSpan{Span: span.Span{ID: 2, StartBlock: 256, EndBlock: 511}, ChainID: "1"}}
+
+	b := &Bor{spanner: spanner, spanProvider: provider, config: &params.BorConfig{Sprint: map[string]uint64{"0": 16}}, chainConfig: &params.ChainConfig{ChainID: big.NewInt(1)}}
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	header := &types.Header{Number: big.NewInt(16)}
+	validator := valset.NewValidator(common.Address{0x1}, 10)
+
+	// An empty validator set is refused, even without a minimum in the chain config,
+	// the span not being committed
+	committed, err := b.fetchAndCommitSpan(context.Background(), 2, statedb, header, nil)
+	require.ErrorIs(t, err, errUndersizedSpan)
+	require.Nil(t, committed)
+
+	// As is a set below the minimum
+	b.config.MinValidators = map[string]uint64{"0": 2}
+	provider.span.ValidatorSet = *valset.NewValidatorSet([]*valset.Validator{validator})
+
+	committed, err = b.fetchAndCommitSpan(context.Background(), 2, statedb, header, nil)
+	require.ErrorIs(t, err, errUndersizedSpan)
+	require.Nil(t, committed)
+
+	// A set reaching it is
+	provider.span.ValidatorSet = *valset.NewValidatorSet([]*valset.Validator{validator, valset.NewValidator(common.Address{0x2}, 10)})
+	spanner.EXPECT().CommitSpan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	committed, err = b.fetchAndCommitSpan(context.Background(), 2, statedb, header, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), committed.ID)
+}
+
+func TestLastSpanChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	spanner := NewMockSpanner(ctrl)
+
+	newSpan := func(id uint64, validators ...*valset.Validator) *span.HeimdallSpan {
+		return &span.HeimdallSpan{
+			Span:         span.Span{ID: id, StartBlock: id*64 + 1, EndBlock: id*64 + 64},
+			ValidatorSet: *valset.NewValidatorSet(validators),
+			ChainID:      "1",
+		}
+	}
+
+	var (
+		a, b, c = common.Address{0x1}, common.Address{0x2}, common.Address{0x3}
+		engine  = &Bor{spanner: spanner}
+		api     = &API{bor: engine}
+	)
+
+	// The previous span and its validators are read from the contract at the
+	// parent block, once the change is queried
+	record := func(s *span.HeimdallSpan, number int64) {
+		engine.recordSpanCommit(&types.Header{ParentHash: common.Hash{byte(number)}, Number: big.NewInt(number)}, s)
+	}
+
+	previous := func(number int64, id uint64, validators ...*valset.Validator) {
+		parentHash := common.Hash{byte(number)}
+
+		spanner.EXPECT().GetCurrentSpan(gomock.Any(), parentHash).Return(&span.Span{ID: id}, nil).Times(1)
+		spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), parentHash, uint64(number)).Return(validators, nil).Times(1)
+	}
+
+	_, err := api.GetLastSpanChange(context.Background())
+	require.ErrorIs(t, err, errNoSpanChange)
+
+	// The first span imported since the start is reported
+	record(newSpan(2, valset.NewValidator(a, 10), valset.NewValidator(b, 20), valset.NewValidator(c, 5)), 113)
+	previous(113, 1, valset.NewValidator(a, 10), valset.NewValidator(b, 10))
+
+	change, err := api.GetLastSpanChange(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &SpanChange{
+		OldSpanID:    1,
+		NewSpanID:    2,
+		Block:        129,
+		CommittedAt:  113,
+		Added:        []SpanValidatorChange{{Address: c, NewPower: 5}},
+		Removed:      []SpanValidatorChange{},
+		PowerChanged: []SpanValidatorChange{{Address: b, OldPower: 10, NewPower: 20}},
+	}, change)
+
+	// The resolved change is kept
+	again, err := api.GetLastSpanChange(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, change, again)
+
+	// The old span is the one in the contract, not necessarily the previous id
+	record(newSpan(4, valset.NewValidator(b, 20)), 177)
+	previous(177, 2, valset.NewValidator(a, 10), valset.NewValidator(b, 20), valset.NewValidator(c, 5))
+
+	change, err = api.GetLastSpanChange(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), change.OldSpanID)
+	require.Equal(t, uint64(4), change.NewSpanID)
+	require.Equal(t, []SpanValidatorChange{{Address: a, OldPower: 10}, {Address: c, OldPower: 5}}, change.Removed)
+	require.Empty(t, change.Added)
+	require.Empty(t, change.PowerChanged)
+
+	// An older span, e.g. imported with a sidechain, keeps the change
+	record(newSpan(2, valset.NewValidator(a, 10)), 113)
+
+	again, err = api.GetLastSpanChange(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, change, again)
+
+	encoded, err := json.Marshal(change)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"oldSpanID":2,"newSpanID":4`)
+}
+
+func TestMaxValidators(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	spanner := NewMockSpanner(ctrl)
+	provider := &staticSpanProvider{span: &span.HeimdallSpan{Span: span.Span{ID: 1, StartBlock: 0, EndBlock: 255}, ChainID: "1"}}
+
+	b := &Bor{spanner: spanner, spanProvider: provider, config: &params.BorConfig{Sprint: map[string]uint64{"0": 16}, MaxValidators: map[string]uint64{"0": 2}}, chainConfig: &params.ChainConfig{ChainID: big.NewInt(1)}}
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	// The committed span is stored in the validator contract, like the system call does
+	contract := common.HexToAddress("0x0000000000000000000000000000000000001000")
+	commit := func(_ context.Context, s span.HeimdallSpan, statedb *state.StateDB, _ *types.Header, _ core.ChainContext) error {
+		statedb.SetState(contract, common.Hash{}, common.BigToHash(new(big.Int).SetUint64(s.ID)))
+		statedb.SetState(contract, common.Hash{0x1}, common.BigToHash(big.NewInt(int64(len(s.ValidatorSet.Validators)))))
+
+		return nil
+	}
+
+	requireCommitted := func(id uint64, validators int64) {
+		require.Equal(t, common.BigToHash(new(big.Int).SetUint64(id)), statedb.GetState(contract, common.Hash{}))
+		require.Equal(t, common.BigToHash(big.NewInt(validators)), statedb.GetState(contract, common.Hash{0x1}))
+	}
+
+	header := &types.Header{Number: big.NewInt(16)}
+	validators := []*valset.Validator{
+		valset.NewValidator(common.Address{0x1}, 10),
+		valset.NewValidator(common.Address{0x2}, 10),
+		valset.NewValidator(common.Address{0x3}, 10),
+	}
+
+	// A set at the maximum is committed
+	provider.span.ValidatorSet = *valset.NewValidatorSet(validators[:2])
+	spanner.EXPECT().CommitSpan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(commit).Times(1)
+
+	committed, err := b.fetchAndCommitSpan(context.Background(), 1, statedb, header, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), committed.ID)
+	requireCommitted(1, 2)
+
+	// An oversized one refuses the block, the previous set being retained in the contract
+	provider.span = &span.HeimdallSpan{Span: span.Span{ID: 2, StartBlock: 256, EndBlock: 511}, ChainID: "1", ValidatorSet: *valset.NewValidatorSet(validators)}
+
+	committed, err = b.fetchAndCommitSpan(context.Background(), 2, statedb, header, nil)
+	require.ErrorIs(t, err, errOversizedSpan)
+	require.Nil(t, committed)
+	requireCommitted(1, 2)
+
+	// Without a maximum, any set is committed
+	b.config.MaxValidators = nil
+	spanner.EXPECT().CommitSpan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(commit).Times(1)
+
+	committed, err = b.fetchAndCommitSpan(context.Background(), 2, statedb, header, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), committed.ID)
+	requireCommitted(2, 3)
+}
+
+func TestSpanSprintAlignment(t *testing.T) {
+	t.Parallel()
+
+	b := &Bor{config: &params.BorConfig{Sprint: map[string]uint64{"0": 64, "1024": 16}}}
+
+	tests := []struct {
+		name       string
+		start, end uint64
+		ok         bool
+	}{
+		{"aligned", 0, 6399, true},
+		{"misaligned", 0, 99, false},
+		{"misaligned before the sprint fork", 0, 6415, false},
+		{"aligned after the sprint fork", 1024, 7439, true},
+		{"end before the start", 100, 99, false},
+	}
+
+	for _, tt := range tests {
+		ok := b.checkSpanSprintAlignment(&span.HeimdallSpan{Span: span.Span{ID: 1, StartBlock: tt.start, EndBlock: tt.end}})
+		require.Equal(t, tt.ok, ok, tt.name)
+	}
+
+	// A zero sprint length is reported rather than dividing by zero
+	b = &Bor{config: &params.BorConfig{Sprint: map[string]uint64{"0": 0}}}
+	require.False(t, b.checkSpanSprintAlignment(&span.HeimdallSpan{Span: span.Span{ID: 1, StartBlock: 0, EndBlock: 6399}}))
+}
+
+func TestValidateSpanSprintAlignment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		sprint map[string]uint64
+		ok     bool
+	}{
+		{"single sprint length", map[string]uint64{"0": 16}, true},
+		{"shorter sprints on a boundary", map[string]uint64{"0": 64, "38189056": 16}, true},
+		{"zero sprint length", map[string]uint64{"0": 64, "1024": 0}, false},
+		{"fork in the middle of a sprint", map[string]uint64{"0": 64, "1000": 16}, false},
+		{"longer sprints", map[string]uint64{"0": 16, "1024": 64}, false},
+		{"sprints not dividing the previous ones", map[string]uint64{"0": 64, "1152": 48}, false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSpanSprintAlignment(&params.BorConfig{Sprint: tt.sprint})
+		if tt.ok {
+			require.NoError(t, err, tt.name)
+		} else {
+			require.Error(t, err, tt.name)
+		}
+	}
+}
+
+func TestGetBorConfigSpan(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		config = &params.BorConfig{Sprint: map[string]uint64{"0": 16}, Period: map[string]uint64{"0": 2}, ProducerDelay: map[string]uint64{"0": 4}, BackupMultiplier: map[string]uint64{"0": 2}}
+		chain  = &headerChain{}
+	)
+
+	for i := 0; i <= 3; i++ {
+		chain.headers = append(chain.headers, &types.Header{Number: big.NewInt(int64(i))})
+	}
+
+	// Without a spanner, the span parameters are left out
+	number := rpc.BlockNumber(2)
+
+	resolved, err := (&API{chain: chain, bor: &Bor{config: config}}).GetBorConfig(context.Background(), &number)
+	require.NoError(t, err)
+	require.Equal(t, uint64(16), resolved.Sprint)
+	require.Zero(t, resolved.SpanSize)
+	require.Zero(t, resolved.Producers)
+
+	// The span is the one of the validator contract at the given block, not the
+	// latest one of heimdall, which isn't asked at all
+	spanner := NewMockSpanner(ctrl)
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), chain.headers[2].Hash()).Return(&span.Span{ID: 3, StartBlock: 0, EndBlock: 6399}, nil)
+	spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), chain.headers[2].Hash(), uint64(2)).Return(make([]*valset.Validator, 7), nil)
+
+	provider := &staticSpanProvider{span: &span.HeimdallSpan{Span: span.Span{ID: 9, StartBlock: 57600, EndBlock: 63999}}}
+	api := &API{chain: chain, bor: &Bor{config: config, spanner: spanner, spanProvider: provider}}
+
+	resolved, err = api.GetBorConfig(context.Background(), &number)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), resolved.SpanID)
+	require.Equal(t, uint64(6400), resolved.SpanSize)
+	require.Equal(t, uint64(7), resolved.Producers)
+
+	// A failing read of the contract leaves them out, without failing the call
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), chain.headers[3].Hash()).Return(nil, fmt.Errorf("missing trie node"))
+
+	resolved, err = api.GetBorConfig(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), resolved.Number)
+	require.Zero(t, resolved.SpanID)
+	require.Zero(t, resolved.SpanSize)
+
+	// So does a block that isn't known yet
+	number = rpc.BlockNumber(100)
+
+	resolved, err = api.GetBorConfig(context.Background(), &number)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), resolved.Number)
+	require.Zero(t, resolved.SpanSize)
+}
+
+func TestOutOfTurnDelays(t *testing.T) {
+	t.Parallel()
+
+	delayed, other := common.Address{0x1}, common.Address{0x2}
+
+	borConfig := &params.BorConfig{
+		Period:           map[string]uint64{"0": 2},
+		ProducerDelay:    map[string]uint64{"0": 6},
+		Sprint:           map[string]uint64{"0": 16},
+		BackupMultiplier: map[string]uint64{"0": 4},
+		OutOfTurnDelays: map[string]map[string]uint64{
+			"0":   {},
+			"100": {delayed.Hex(): 1},
+		},
+	}
+
+	// The in-turn delay is the same for everyone
+	require.Equal(t, uint64(2), CalcSignerProducerDelay(105, 0, delayed, borConfig))
+	require.Equal(t, uint64(6), CalcSignerProducerDelay(112, 0, delayed, borConfig))
+
+	// The configured backup gets its own delay instead of the backup multiplier
+	require.Equal(t, uint64(2+2*1), CalcSignerProducerDelay(105, 2, delayed, borConfig))
+	require.Equal(t, uint64(6+1), CalcSignerProducerDelay(112, 1, delayed, borConfig))
+	require.Equal(t, CalcProducerDelay(105, 2, borConfig), CalcSignerProducerDelay(105, 2, other, borConfig))
+
+	// Only from the fork setting it
+	require.Equal(t, CalcProducerDelay(5, 2, borConfig), CalcSignerProducerDelay(5, 2, delayed, borConfig))
+
+	// And the headers are verified against it
+	parent := &types.Header{Number: big.NewInt(104), Time: 100}
+	header := &types.Header{Number: big.NewInt(105), Time: 104}
+
+	require.False(t, IsBlockOnTime(parent, header, 105, 2, delayed, borConfig))
+	require.True(t, IsBlockOnTime(parent, header, 105, 2, other, borConfig))
+}
+
+// genesisSpanProvider serves the given span 0.
+type genesisSpanProvider struct {
+	span *span.HeimdallSpan
+}
+
+func (p *genesisSpanProvider) GetSpan(_ context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	if spanID != 0 {
+		return nil, fmt.Errorf("unexpected span %d", spanID)
+	}
+
+	return p.span, nil
+}
+
+func (p *genesisSpanProvider) GetCurrentSpan(context.Context) (*span.HeimdallSpan, error) {
+	return p.span, nil
+}
+
+func TestGenesisValidators(t *testing.T) {
+	t.Parallel()
+
+	var (
+		hash     = common.Hash{0x1}
+		contract = []*valset.Validator{valset.NewValidator(common.Address{0x1}, 10), valset.NewValidator(common.Address{0x2}, 20)}
+		heimdall = []*valset.Validator{valset.NewValidator(common.Address{0x1}, 10), valset.NewValidator(common.Address{0x3}, 20)}
+	)
+
+	genesisSpan := &span.HeimdallSpan{}
+	for _, val := range heimdall {
+		genesisSpan.SelectedProducers = append(genesisSpan.SelectedProducers, *val)
+	}
+
+	newBor := func(t *testing.T, source GenesisSpanSource) (*Bor, *MockSpanner) {
+		ctrl := gomock.NewController(t)
+		spanner := NewMockSpanner(ctrl)
+
+		b := &Bor{spanner: spanner}
+		WithSpanProvider(&genesisSpanProvider{span: genesisSpan})(b)
+		WithGenesisSpanSource(source)(b)
+
+		return b, spanner
+	}
+
+	// The genesis contract is read by default
+	b, spanner := newBor(t, "")
+	spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), hash, uint64(1)).Return(contract, nil)
+
+	validators, err := b.genesisValidators(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, contract, validators)
+
+	// Heimdall's span 0 doesn't read the contract
+	b, _ = newBor(t, GenesisSpanSourceHeimdall)
+
+	validators, err = b.genesisValidators(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, heimdall, validators)
+
+	// The strict mode refuses a disagreement
+	b, spanner = newBor(t, GenesisSpanSourceStrict)
+	spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), hash, uint64(1)).Return(contract, nil)
+
+	_, err = b.genesisValidators(context.Background(), hash)
+
+	var mismatchErr *GenesisSpanMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	require.Equal(t, "address of validator 1", mismatchErr.Field)
+
+	// And accepts the contract if both sources agree
+	b, spanner = newBor(t, GenesisSpanSourceStrict)
+	spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), hash, uint64(1)).Return(heimdall, nil)
+
+	validators, err = b.genesisValidators(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, heimdall, validators)
+
+	// Unknown sources are rejected
+	_, err = ParseGenesisSpanSource("both")
+	require.Error(t, err)
+}
+
+func TestParallelStateSync(t *testing.T) {
+	t.Parallel()
+
+	targets := []common.Address{{0x10}, {0x11}, {0x12}, {0x13}}
+	receivers := []common.Address{{0x20}, {0x21}}
+	counter := common.Address{0x30}
+
+	// The events alternate between the two receivers
+	receiverFor := func(_ uint64, target common.Address) common.Address {
+		return receivers[int(target[0])%len(receivers)]
+	}
+
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+
+	genesis, err := state.New(types.EmptyRootHash, db, nil)
+	require.NoError(t, err)
+
+	for _, contract := range append(append(targets, receivers...), counter) {
+		genesis.SetCode(contract, []byte{0x1})
+	}
+
+	root, err := genesis.Commit(0, true)
+	require.NoError(t, err)
+
+	events := make([]*clerk.EventRecordWithTime, len(targets))
+	for i, target := range targets {
+		events[i] = &clerk.EventRecordWithTime{EventRecord: clerk.EventRecord{ID: uint64(i + 1), Contract: target}}
+	}
+
+	// commitState moves the last state id of the receiver forward like the state
+	// receiver contract, before the write to the target of the event
+	commitState := func(write func(event *clerk.EventRecordWithTime, statedb *state.StateDB)) interface{} {
+		return func(event *clerk.EventRecordWithTime, statedb *state.StateDB, _ *types.Header, _ statefull.ChainContext) (uint64, error) {
+			receiver := receiverFor(16, event.Contract)
+
+			if last := statedb.GetState(receiver, common.Hash{}).Big().Uint64(); last >= event.ID {
+				return 0, fmt.Errorf("event %d already committed, last %d", event.ID, last)
+			}
+
+			statedb.SetState(receiver, common.Hash{}, common.BigToHash(new(big.Int).SetUint64(event.ID)))
+			write(event, statedb)
+			statedb.AddLog(&types.Log{Address: receiver, Data: []byte{byte(event.ID)}})
+
+			return 100, nil
+		}
+	}
+
+	// independent writes to the target of the event
+	independent := commitState(func(event *clerk.EventRecordWithTime, statedb *state.StateDB) {
+		statedb.SetState(event.Contract, common.Hash{0x1}, common.BigToHash(new(big.Int).SetUint64(event.ID)))
+	})
+
+	// increments of a counter shared by all the events
+	shared := commitState(func(_ *clerk.EventRecordWithTime, statedb *state.StateDB) {
+		count := statedb.GetState(counter, common.Hash{}).Big()
+		statedb.SetState(counter, common.Hash{}, common.BigToHash(count.Add(count, common.Big1)))
+	})
+
+	// newEngine expects the given number of commits, any number if negative
+	newEngine := func(t *testing.T, workers int, commitState interface{}, commits int) *Bor {
+		contract := NewMockGenesisContract(gomock.NewController(t))
+
+		call := contract.EXPECT().CommitState(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(commitState)
+		if commits < 0 {
+			call.AnyTimes()
+		} else {
+			call.Times(commits)
+		}
+
+		contract.EXPECT().StateReceiverFor(gomock.Any(), gomock.Any()).DoAndReturn(receiverFor).AnyTimes()
+
+		b := &Bor{GenesisContractsClient: contract}
+		WithParallelStateSync(workers)(b)
+
+		return b
+	}
+
+	// commit returns the root and the logs of the events committed with the given
+	// number of parallel workers
+	commit := func(t *testing.T, workers int, commitState interface{}) (common.Hash, []*types.Log) {
+		b := newEngine(t, workers, commitState, -1)
+
+		statedb, err := state.New(root, db, nil)
+		require.NoError(t, err)
+
+		statedb.SetTxContext(common.Hash{0x1}, 0)
+
+		gasUsed, err := b.commitStateSyncEvents(events, statedb, &types.Header{Number: big.NewInt(16)}, statefull.ChainContext{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(100*len(events)), gasUsed)
+
+		return statedb.IntermediateRoot(true), statedb.GetLogs(common.Hash{0x1}, 16, common.Hash{})
+	}
+
+	for name, commitState := range map[string]interface{}{"independent": independent, "shared": shared} {
+		sequentialRoot, sequentialLogs := commit(t, 0, commitState)
+		parallelRoot, parallelLogs := commit(t, 2, commitState)
+
+		require.Equal(t, sequentialRoot, parallelRoot, name)
+		require.Equal(t, sequentialLogs, parallelLogs, name)
+
+		for i, l := range parallelLogs {
+			require.Equal(t, []byte{byte(i + 1)}, l.Data, name)
+		}
+	}
+
+	// The events of the two receivers are committed from the speculative executions,
+	// each receiver's events in order
+	b := newEngine(t, 2, independent, len(events))
+
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+
+	groups := b.stateSyncEventsByReceiver(16, events)
+	require.Equal(t, [][]int{{0, 2}, {1, 3}}, groups)
+
+	_, ok := b.commitStateSyncEventsInParallel(events, groups, statedb, &types.Header{Number: big.NewInt(16)}, statefull.ChainContext{})
+	require.True(t, ok)
+
+	for i, receiver := range receivers {
+		require.Equal(t, common.BigToHash(big.NewInt(int64(len(events)-len(receivers)+i+1))), statedb.GetState(receiver, common.Hash{}))
+	}
+
+	// The conflicting ones are refused, leaving the state untouched
+	b = newEngine(t, 2, shared, len(events))
+
+	statedb, err = state.New(root, db, nil)
+	require.NoError(t, err)
+
+	_, ok = b.commitStateSyncEventsInParallel(events, b.stateSyncEventsByReceiver(16, events), statedb, &types.Header{Number: big.NewInt(16)}, statefull.ChainContext{})
+	require.False(t, ok)
+	require.Equal(t, root, statedb.IntermediateRoot(true))
+
+	// And the events of a single receiver are committed sequentially right away
+	single := events[:1]
+	single = append(single, &clerk.EventRecordWithTime{EventRecord: clerk.EventRecord{ID: 3, Contract: targets[2]}})
+
+	b = newEngine(t, 2, independent, len(single))
+
+	statedb, err = state.New(root, db, nil)
+	require.NoError(t, err)
+
+	_, err = b.commitStateSyncEvents(single, statedb, &types.Header{Number: big.NewInt(16)}, statefull.ChainContext{})
+	require.NoError(t, err)
+}
+
+func TestParallelStateSyncStateReceiver(t *testing.T) {
+	t.Parallel()
+
+	// The state receiver deployed on mumbai, requiring contiguous ids
+	code := params.MumbaiChainConfig.Bor.BlockAlloc["41874000"].(map[string]interface{})["0x0000000000000000000000000000000000001001"].(map[string]interface{})["code"].(string)
+
+	var (
+		defaultReceiver = common.HexToAddress("0x0000000000000000000000000000000000001001")
+		mappedReceiver  = common.HexToAddress("0x0000000000000000000000000000000000001002")
+		mappedTarget    = common.Address{0x11}
+	)
+
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+
+	genesis, err := state.New(types.EmptyRootHash, db, nil)
+	require.NoError(t, err)
+
+	genesis.SetCode(defaultReceiver, common.FromHex(code))
+	genesis.SetCode(mappedReceiver, common.FromHex(code))
+
+	// The mapped receiver starts past the ids of the default one
+	genesis.SetState(mappedReceiver, common.Hash{}, common.BigToHash(big.NewInt(2)))
+
+	root, err := genesis.Commit(0, true)
+	require.NoError(t, err)
+
+	config := *params.TestChainConfig
+	borConfig := *config.Bor
+	borConfig.StateReceiverContracts = map[string]map[string]string{"0": {mappedTarget.Hex(): mappedReceiver.Hex()}}
+	config.Bor = &borConfig
+
+	client := contract.NewGenesisContractsClient(&config, "", defaultReceiver.Hex(), nil)
+
+	events := []*clerk.EventRecordWithTime{
+		{EventRecord: clerk.EventRecord{ID: 1, Contract: common.Address{0x10}, ChainID: "1"}, Time: time.Unix(1, 0)},
+		{EventRecord: clerk.EventRecord{ID: 2, Contract: common.Address{0x12}, ChainID: "1"}, Time: time.Unix(1, 0)},
+		{EventRecord: clerk.EventRecord{ID: 3, Contract: mappedTarget, ChainID: "1"}, Time: time.Unix(1, 0)},
+		{EventRecord: clerk.EventRecord{ID: 4, Contract: mappedTarget, ChainID: "1"}, Time: time.Unix(1, 0)},
+	}
+
+	header := &types.Header{Number: big.NewInt(16), Difficulty: big.NewInt(1)}
+
+	newState := func() *state.StateDB {
+		statedb, err := state.New(root, db, nil)
+		require.NoError(t, err)
+
+		statedb.SetTxContext(common.Hash{0x1}, 0)
+
+		return statedb
+	}
+
+	b := &Bor{GenesisContractsClient: client}
+	WithParallelStateSync(2)(b)
+
+	// The calls from the system address don't keep the receivers from being
+	// committed in parallel
+	parallel := newState()
+
+	_, ok := b.commitStateSyncEventsInParallel(events, b.stateSyncEventsByReceiver(16, events), parallel, header, statefull.ChainContext{})
+	require.True(t, ok)
+
+	sequential := newState()
+
+	for _, event := range events {
+		_, err := client.CommitState(event, sequential, header, statefull.ChainContext{})
+		require.NoError(t, err)
+	}
+
+	// All the events were committed, to the same state as the sequential commit
+	require.Equal(t, common.BigToHash(big.NewInt(2)), sequential.GetState(defaultReceiver, common.Hash{}))
+	require.Equal(t, common.BigToHash(big.NewInt(4)), sequential.GetState(mappedReceiver, common.Hash{}))
+
+	require.Equal(t, sequential.IntermediateRoot(true), parallel.IntermediateRoot(true))
+	require.Equal(t, sequential.GetLogs(common.Hash{0x1}, 16, common.Hash{}), parallel.GetLogs(common.Hash{0x1}, 16, common.Hash{}))
+	require.Len(t, parallel.GetLogs(common.Hash{0x1}, 16, common.Hash{}), len(events))
+}
+
+func TestValidateSignerDifficulty(t *testing.T) {
+	t.Parallel()
+
+	validators := []*valset.Validator{
+		valset.NewValidator(common.Address{0x1}, 10),
+		valset.NewValidator(common.Address{0x2}, 10),
+		valset.NewValidator(common.Address{0x3}, 10),
+	}
+	snap := newSnapshot(&params.BorConfig{}, nil, 15, common.Hash{}, validators)
+
+	header := func(difficulty *big.Int) *types.Header {
+		return &types.Header{Number: big.NewInt(16), Difficulty: difficulty}
+	}
+
+	for _, validator := range validators {
+		expected := Difficulty(snap.ValidatorSet, validator.Address)
+
+		// Only the difficulty of the signer's turn is accepted
+		for difficulty := uint64(0); difficulty <= 4; difficulty++ {
+			err := validateSignerDifficulty(snap, header(new(big.Int).SetUint64(difficulty)), validator.Address)
+			if difficulty == expected {
+				require.NoError(t, err)
+				continue
+			}
+
+			var wrongErr *WrongDifficultyError
+			require.ErrorAs(t, err, &wrongErr)
+			require.Equal(t, expected, wrongErr.Expected)
+		}
+
+		// Even if it matches once truncated to 64 bits
+		overflowing := new(big.Int).Add(new(big.Int).Lsh(common.Big1, 64), new(big.Int).SetUint64(expected))
+
+		var wrongErr *WrongDifficultyError
+		require.ErrorAs(t, validateSignerDifficulty(snap, header(overflowing), validator.Address), &wrongErr)
+	}
+}
+
+// headerChain is a chain of headers, serving the headers by number.
+type headerChain struct {
+	consensus.ChainHeaderReader
+	headers []*types.Header
+}
+
+func (c *headerChain) CurrentHeader() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *headerChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+
+	return c.headers[number]
+}
+
+func (c *headerChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+
+	return nil
+}
+
+func TestGetSealerForBlock(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := &params.BorConfig{Sprint: map[string]uint64{"0": 4}}
+	chain := &headerChain{}
+
+	for i := 0; i <= 5; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i))}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+
+		chain.headers = append(chain.headers, header)
+	}
+
+	valz := []*valset.Validator{
+		valset.NewValidator(common.Address{0x1}, 10),
+		valset.NewValidator(common.Address{0x2}, 10),
+		valset.NewValidator(common.Address{0x3}, 10),
+	}
+
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	head := chain.CurrentHeader()
+	headSnap := newSnapshot(config, nil, 5, head.Hash(), valz)
+	recents.Add(head.Hash(), headSnap)
+
+	parentSnap := newSnapshot(config, nil, 2, chain.headers[2].Hash(), valz)
+	parentSnap.ValidatorSet.IncrementProposerPriority(1)
+	recents.Add(chain.headers[2].Hash(), parentSnap)
+
+	spanner := NewMockSpanner(ctrl)
+	spanner.EXPECT().GetCurrentSpan(gomock.Any(), head.Hash()).Return(&span.Span{ID: 1, StartBlock: 0, EndBlock: 19}, nil).AnyTimes()
+
+	b := &Bor{config: config, recents: recents, spanner: spanner}
+	b.authorizedSigner.Store(&signer{})
+
+	api := &API{chain: chain, bor: b}
+
+	// Expected proposer after the given number of sprint ends past the head
+	proposer := func(rotations int) common.Address {
+		validatorSet := headSnap.ValidatorSet.Copy()
+		for i := 0; i < rotations; i++ {
+			validatorSet.IncrementProposerPriority(1)
+		}
+
+		return validatorSet.GetProposer().Address
+	}
+
+	// An existing block is sealed by the proposer of its parent's snapshot
+	sealer, err := api.GetSealerForBlock(3)
+	require.NoError(t, err)
+	require.Equal(t, parentSnap.ValidatorSet.GetProposer().Address, sealer)
+
+	// Future blocks rotate at every sprint end, up to the end of the span
+	for number, rotations := range map[uint64]int{6: 0, 7: 0, 8: 1, 11: 1, 12: 2, 16: 3, 19: 3} {
+		sealer, err := api.GetSealerForBlock(number)
+		require.NoError(t, err)
+		require.Equal(t, proposer(rotations), sealer, "block %d", number)
+	}
+
+	require.NotEqual(t, proposer(0), proposer(1))
+
+	_, err = api.GetSealerForBlock(20)
+	require.Error(t, err)
+}
+
+func TestInTurnSlot(t *testing.T) {
+	t.Parallel()
+
+	config := &params.BorConfig{
+		Sprint:        map[string]uint64{"0": 4},
+		Period:        map[string]uint64{"0": 2},
+		ProducerDelay: map[string]uint64{"0": 6},
+	}
+
+	valz := []*valset.Validator{valset.NewValidator(common.Address{0x1}, 10)}
+
+	recents, _ := lru.NewARC(inmemorySnapshots)
+
+	b := &Bor{config: config, recents: recents}
+	b.authorizedSigner.Store(&signer{})
+
+	slot := func(number uint64) *SealingSlot {
+		parent := &types.Header{Number: new(big.Int).SetUint64(number - 1), Time: 100}
+		recents.Add(parent.Hash(), newSnapshot(config, nil, number-1, parent.Hash(), valz))
+
+		slot, err := b.InTurnSlot(nil, parent)
+		require.NoError(t, err)
+
+		return slot
+	}
+
+	// Without a signer, or with another one, the node has no slot
+	require.Nil(t, slot(2))
+
+	b.Authorize(common.Address{0x2}, nil)
+	require.Nil(t, slot(2))
+
+	b.Authorize(common.Address{0x1}, nil)
+	require.Equal(t, &SealingSlot{Number: 2, Signer: common.Address{0x1}, Due: 102}, slot(2))
+
+	// The first block of a sprint is delayed by the producer delay
+	require.Equal(t, uint64(106), slot(4).Due)
+}
+
+func TestMaxSnapshotWalkback(t *testing.T) {
+	t.Parallel()
+
+	config := &params.BorConfig{Sprint: map[string]uint64{"0": 16}}
+	chain := &headerChain{}
+
+	for i := 0; i <= 1100; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i))}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+
+		chain.headers = append(chain.headers, header)
+	}
+
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	recents, _ := lru.NewARC(inmemorySnapshots)
+
+	db := rawdb.NewMemoryDatabase()
+	b := &Bor{config: config, db: db, signatures: signatures, recents: recents, maxSnapshotWalkback: 40}
+	b.authorizedSigner.Store(&signer{})
+
+	// The checkpoint snapshot at 1024 is deliberately missing
+	head := chain.CurrentHeader()
+
+	_, err := b.snapshot(chain, 1100, head.Hash(), nil)
+
+	var walkbackErr *SnapshotWalkbackError
+	require.ErrorAs(t, err, &walkbackErr)
+	require.Equal(t, uint64(40), walkbackErr.Walked)
+	require.Equal(t, uint64(1060), walkbackErr.Number)
+	require.Equal(t, chain.headers[1060].Hash(), walkbackErr.Hash)
+	require.Contains(t, err.Error(), "checkpoint missing")
+
+	// Unbounded by default, walking back down to the genesis
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errGenesis := errors.New("genesis reached")
+	spanner := NewMockSpanner(ctrl)
+	spanner.EXPECT().GetCurrentValidatorsByHash(gomock.Any(), chain.headers[0].Hash(), uint64(1)).Return(nil, errGenesis)
+
+	unbounded := &Bor{config: config, db: db, signatures: signatures, recents: recents, spanner: spanner}
+	unbounded.authorizedSigner.Store(&signer{})
+
+	_, err = unbounded.snapshot(chain, 1100, head.Hash(), nil)
+	require.ErrorIs(t, err, errGenesis)
+
+	// Once the checkpoint snapshot is available, the snapshot is reconstructed
+	checkpoint := chain.headers[1024]
+	require.NoError(t, newSnapshot(config, signatures, 1024, checkpoint.Hash(), nil).store(db))
+
+	snap, err := b.snapshot(chain, 1024, checkpoint.Hash(), nil)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint.Hash(), snap.Hash)
+}
+
+func TestWarmSignerCache(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := &params.BorConfig{}
+	chain := &headerChain{}
+
+	for i := 0; i < 100; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Extra: make([]byte, types.ExtraVanityLength+types.ExtraSealLength)}
+
+		sig, err := crypto.Sign(SealHash(header, config).Bytes(), key)
+		require.NoError(t, err)
+
+		copy(header.Extra[types.ExtraVanityLength:], sig)
+		chain.headers = append(chain.headers, header)
+	}
+
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	b := &Bor{config: config, signatures: signatures}
+
+	// The genesis header is skipped, the batches don't line up with the range
+	warmup, err := b.WarmSignerCache(chain, 0, 49, 4, 7)
+	require.NoError(t, err)
+	require.Equal(t, uint64(49), warmup.Warmed)
+	require.Zero(t, warmup.Cached)
+	require.Zero(t, warmup.Failed)
+
+	for i := 1; i < 50; i++ {
+		author, ok := signatures.Get(chain.headers[i].Hash())
+		require.True(t, ok)
+		require.Equal(t, signer, author)
+	}
+
+	// The cached signers aren't recovered again
+	warmup, err = b.WarmSignerCache(chain, 40, 99, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), warmup.Warmed)
+	require.Equal(t, uint64(10), warmup.Cached)
+
+	_, err = b.WarmSignerCache(chain, 50, 100, 0, 0)
+	require.Error(t, err)
+}
+
+// configHeaderChain is a headerChain with a chain config.
+type configHeaderChain struct {
+	*headerChain
+	config *params.ChainConfig
+}
+
+func (c *configHeaderChain) Config() *params.ChainConfig {
+	return c.config
+}
+
+func TestPeriodViolation(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	author := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := &params.BorConfig{
+		Period:           map[string]uint64{"0": 2},
+		ProducerDelay:    map[string]uint64{"0": 6},
+		Sprint:           map[string]uint64{"0": 16},
+		BackupMultiplier: map[string]uint64{"0": 2},
+	}
+	chain := &configHeaderChain{headerChain: &headerChain{}, config: &params.ChainConfig{ChainID: big.NewInt(1), Bor: config}}
+
+	for i := 0; i <= 4; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: 100 + uint64(i)*2, GasLimit: 30_000_000}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+
+		chain.headers = append(chain.headers, header)
+	}
+
+	parent := chain.CurrentHeader()
+
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	recents.Add(parent.Hash(), newSnapshot(config, nil, 4, parent.Hash(), []*valset.Validator{valset.NewValidator(author, 10)}))
+
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	b := &Bor{config: config, recents: recents, signatures: signatures}
+	b.authorizedSigner.Store(&signer{})
+
+	sealed := func(time uint64) *types.Header {
+		header := &types.Header{
+			Number:     big.NewInt(5),
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Time:       time,
+			GasLimit:   parent.GasLimit,
+			Difficulty: big.NewInt(1),
+			Extra:      make([]byte, types.ExtraVanityLength+types.ExtraSealLength),
+		}
+
+		sig, err := crypto.Sign(SealHash(header, config).Bytes(), key)
+		require.NoError(t, err)
+
+		copy(header.Extra[types.ExtraVanityLength:], sig)
+
+		return header
+	}
+
+	require.NoError(t, b.VerifyHeader(chain, sealed(parent.Time+2)))
+
+	// The headers sealed faster than the period are rejected
+	for _, time := range []uint64{parent.Time + 1, parent.Time} {
+		require.ErrorIs(t, b.VerifyHeader(chain, sealed(time)), ErrInvalidTimestamp)
+	}
+}
+
+func TestVerifyOutOfTurnFromPeer(t *testing.T) {
+	t.Parallel()
+
+	inTurnKey, _ := crypto.GenerateKey()
+	backupKey, _ := crypto.GenerateKey()
+
+	inTurn := crypto.PubkeyToAddress(inTurnKey.PublicKey)
+	backup := crypto.PubkeyToAddress(backupKey.PublicKey)
+
+	config := &params.BorConfig{
+		Period:           map[string]uint64{"0": 2},
+		ProducerDelay:    map[string]uint64{"0": 6},
+		Sprint:           map[string]uint64{"0": 16},
+		BackupMultiplier: map[string]uint64{"0": 2},
+	}
+	chain := &configHeaderChain{headerChain: &headerChain{}, config: &params.ChainConfig{ChainID: big.NewInt(1), Bor: config}}
+
+	for i := 0; i <= 4; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: 100 + uint64(i)*2, GasLimit: 30_000_000}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+
+		chain.headers = append(chain.headers, header)
+	}
+
+	parent := chain.CurrentHeader()
+
+	snap := newSnapshot(config, nil, 4, parent.Hash(), []*valset.Validator{valset.NewValidator(inTurn, 20), valset.NewValidator(backup, 10)})
+	require.Equal(t, inTurn, snap.ValidatorSet.GetProposer().Address)
+
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	recents.Add(parent.Hash(), snap)
+
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	b := &Bor{config: config, recents: recents, signatures: signatures}
+	b.authorizedSigner.Store(&signer{})
+
+	// Disabling out-of-turn sealing locally
+	WithAllowOutOfTurn(false)(b)
+
+	// A block sealed out-of-turn by a peer, once the backup delay is over
+	header := &types.Header{
+		Number:     big.NewInt(5),
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Time:       parent.Time + CalcProducerDelay(5, 1, config),
+		GasLimit:   parent.GasLimit,
+		Difficulty: new(big.Int).SetUint64(Difficulty(snap.ValidatorSet, backup)),
+		Extra:      make([]byte, types.ExtraVanityLength+types.ExtraSealLength),
+	}
+
+	sig, err := crypto.Sign(SealHash(header, config).Bytes(), backupKey)
+	require.NoError(t, err)
+
+	copy(header.Extra[types.ExtraVanityLength:], sig)
+
+	// Is still accepted, the setting only applies to the local sealing
+	require.NoError(t, b.VerifyHeader(chain, header))
+}
+
+func TestFutureBlockTolerance(t *testing.T) {
+	t.Parallel()
+
+	config := &params.BorConfig{Period: map[string]uint64{"0": 5, "100": 2}}
+	b := &Bor{config: config}
+
+	// A few seconds ahead, as sealed by a validator whose clock is skewed
+	header := &types.Header{Number: big.NewInt(16), Time: uint64(time.Now().Unix()) + 2}
+
+	require.ErrorIs(t, b.verifyHeader(nil, header, nil), consensus.ErrFutureBlock)
+
+	// The tolerance lets it through to the other checks, not the headers further ahead
+	b.futureBlockTolerance = 2
+
+	require.NotErrorIs(t, b.verifyHeader(nil, header, nil), consensus.ErrFutureBlock)
+
+	header.Time += 10
+	require.ErrorIs(t, b.verifyHeader(nil, header, nil), consensus.ErrFutureBlock)
+
+	// The tolerance can't exceed the shortest period
+	require.NoError(t, ValidateFutureBlockTolerance(config, 2))
+	require.Error(t, ValidateFutureBlockTolerance(config, 3))
+}
+
+// swapHeimdallFake is a heimdall client serving the milestone count, optionally
+// blocking the calls until unblocked.
+type swapHeimdallFake struct {
+	IHeimdallClient
+
+	count   int64
+	entered chan struct{}
+	unblock chan struct{}
+	closed  atomic.Bool
+}
+
+func (h *swapHeimdallFake) FetchMilestoneCount(ctx context.Context) (int64, error) {
+	if h.unblock != nil {
+		h.entered <- struct{}{}
+		<-h.unblock
+	}
+
+	return h.count, nil
+}
+
+func (h *swapHeimdallFake) Close() {
+	h.closed.Store(true)
+}
+
+func TestSwapHeimdallClient(t *testing.T) {
+	t.Parallel()
+
+	var (
+		old       = &swapHeimdallFake{count: 1, entered: make(chan struct{}), unblock: make(chan struct{})}
+		next      = &swapHeimdallFake{count: 2}
+		swappable = NewHeimdallSwappableClient(old)
+		engine    = &Bor{HeimdallClient: swappable}
+		done      = make(chan int64)
+	)
+
+	// A call in flight on the old client
+	go func() {
+		count, _ := swappable.FetchMilestoneCount(context.Background())
+		done <- count
+	}()
+	<-old.entered
+
+	prev, err := engine.SwapHeimdallClient(next)
+	require.NoError(t, err)
+	require.Same(t, old, prev)
+	require.Same(t, next, swappable.Current())
+
+	// The new calls go to the new client, the old one is kept open for the call in flight
+	count, err := swappable.FetchMilestoneCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	require.Never(t, old.closed.Load, 50*time.Millisecond, time.Millisecond)
+
+	close(old.unblock)
+	require.Equal(t, int64(1), <-done)
+	require.Eventually(t, old.closed.Load, time.Second, time.Millisecond)
+	require.False(t, next.closed.Load())
+
+	// Only the engines created with a swappable client can swap it
+	_, err = (&Bor{HeimdallClient: next}).SwapHeimdallClient(old)
+	require.ErrorIs(t, err, errHeimdallClientNotSwappable)
+}
+
+// stuckHeimdallFake is a heimdall client whose calls retry until it's closed.
+type stuckHeimdallFake struct {
+	IHeimdallClient
+
+	entered chan struct{}
+	closeCh chan struct{}
+}
+
+func (h *stuckHeimdallFake) FetchMilestoneCount(context.Context) (int64, error) {
+	h.entered <- struct{}{}
+	<-h.closeCh
+
+	return 0, heimdall.ErrShutdownDetected
+}
+
+func (h *stuckHeimdallFake) Close() {
+	close(h.closeCh)
+}
+
+func TestSwapHeimdallClientStuckCall(t *testing.T) {
+	t.Parallel()
+
+	var (
+		old       = &stuckHeimdallFake{entered: make(chan struct{}), closeCh: make(chan struct{})}
+		next      = &swapHeimdallFake{count: 2}
+		swappable = NewHeimdallSwappableClient(old)
+		engine    = &Bor{HeimdallClient: NewHeimdallLimitedClient(swappable, 2)}
+		done      = make(chan error)
+	)
+
+	WithSwappableHeimdallClient(swappable)(engine)
+
+	swappable.drainTimeout = 50 * time.Millisecond
+
+	// A call retrying against the old client, which never finishes by itself
+	go func() {
+		_, err := engine.HeimdallClient.FetchMilestoneCount(context.Background())
+		done <- err
+	}()
+	<-old.entered
+
+	// The client under the wrappers is swapped, the new calls going through them
+	prev, err := engine.SwapHeimdallClient(next)
+	require.NoError(t, err)
+	require.Same(t, old, prev)
+
+	count, err := engine.HeimdallClient.FetchMilestoneCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// The old client is closed after the drain timeout, aborting the stuck call
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, heimdall.ErrShutdownDetected)
+	case <-time.After(time.Second):
+		t.Fatal("call on the swapped out client still stuck")
+	}
+}
+
+func TestMilestoneVoteHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = &Bor{db: db}
+		api    = &API{bor: engine}
+	)
+
+	engine.RecordMilestoneVote("id1", 1, 16, "0x01", true)
+	engine.RecordMilestoneVote("id2", 17, 32, "0x02", false)
+
+	votes, err := api.GetMilestoneVoteHistory(0)
+	require.NoError(t, err)
+	require.Len(t, votes, 2)
+	require.Equal(t, "id2", votes[0].MilestoneID)
+	require.False(t, votes[0].VoteYes)
+	require.Equal(t, "id1", votes[1].MilestoneID)
+	require.Equal(t, uint64(1), votes[1].StartBlock)
+	require.Equal(t, uint64(16), votes[1].EndBlock)
+	require.Equal(t, "0x01", votes[1].RootHash)
+	require.True(t, votes[1].VoteYes)
+
+	// The history survives a restart of the engine
+	votes, err = (&API{bor: &Bor{db: db}}).GetMilestoneVoteHistory(1)
+	require.NoError(t, err)
+	require.Len(t, votes, 1)
+	require.Equal(t, "id2", votes[0].MilestoneID)
+}
+
+// recordHeimdallFake is a heimdall client serving spans, a milestone count
+// increasing at every call and a single known milestone id.
+type recordHeimdallFake struct {
+	IHeimdallClient
+
+	count atomic.Int64
+}
+
+func (h *recordHeimdallFake) Span(_ context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	return &span.HeimdallSpan{Span: span.Span{ID: spanID, StartBlock: spanID * 100}, ChainID: "137"}, nil
+}
+
+func (h *recordHeimdallFake) FetchMilestoneCount(context.Context) (int64, error) {
+	return h.count.Add(1), nil
+}
+
+func (h *recordHeimdallFake) FetchMilestoneID(_ context.Context, milestoneID string) error {
+	if milestoneID != "known" {
+		return heimdall.ErrNotInMilestoneList
+	}
+
+	return nil
+}
+
+// latestSpanHeimdallFake is a recordHeimdallFake also serving the latest span.
+type latestSpanHeimdallFake struct {
+	recordHeimdallFake
+}
+
+func (h *latestSpanHeimdallFake) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	return h.Span(ctx, 7)
+}
+
+func TestHeimdallLimitedLatestSpan(t *testing.T) {
+	t.Parallel()
+
+	// The latest span goes through the limiter to the wrapped client
+	res, err := NewHeimdallSpanProvider(NewHeimdallLimitedClient(&latestSpanHeimdallFake{}, 1)).GetCurrentSpan(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), res.ID)
+
+	// Unless the wrapped client can't fetch it
+	_, err = NewHeimdallSpanProvider(NewHeimdallLimitedClient(&recordHeimdallFake{}, 1)).GetCurrentSpan(context.Background())
+	require.ErrorIs(t, err, errLatestSpanNotSupported)
+}
+
+// failingLatestSpanHeimdallFake fails to fetch the latest span.
+type failingLatestSpanHeimdallFake struct {
+	recordHeimdallFake
+}
+
+func (h *failingLatestSpanHeimdallFake) LatestSpan(context.Context) (*span.HeimdallSpan, error) {
+	return nil, heimdall.ErrServiceUnavailable
+}
+
+func TestHeimdallProxyLatestSpan(t *testing.T) {
+	t.Parallel()
+
+	direct := &latestSpanHeimdallFake{}
+
+	// Served by the proxy, by the direct client if the proxy fails or can't serve it
+	for _, proxy := range []IHeimdallClient{&latestSpanHeimdallFake{}, &failingLatestSpanHeimdallFake{}, &recordHeimdallFake{}} {
+		res, err := NewHeimdallSpanProvider(NewHeimdallProxyClient(proxy, direct)).GetCurrentSpan(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), res.ID)
+	}
+
+	_, err := NewHeimdallProxyClient(&failingLatestSpanHeimdallFake{}, &recordHeimdallFake{}).LatestSpan(context.Background())
+	require.ErrorIs(t, err, errLatestSpanNotSupported)
+
+	// Nor is the direct client tried once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = NewHeimdallProxyClient(&failingLatestSpanHeimdallFake{}, direct).LatestSpan(ctx)
+	require.ErrorIs(t, err, heimdall.ErrServiceUnavailable)
+}
+
+func TestHeimdallRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	recorder, err := NewHeimdallRecordingClient(&recordHeimdallFake{}, dir)
+	require.NoError(t, err)
+
+	// Concurrent requests, recorded once per distinct response
+	var wg sync.WaitGroup
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := recorder.Span(context.Background(), uint64(i%4))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 3; i++ {
+		_, err = recorder.FetchMilestoneCount(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, recorder.FetchMilestoneID(context.Background(), "known"))
+	require.ErrorIs(t, recorder.FetchMilestoneID(context.Background(), "unknown"), heimdall.ErrNotInMilestoneList)
+
+	replay, err := NewHeimdallReplayClient(dir)
+	require.NoError(t, err)
+
+	for i := uint64(0); i < 4; i++ {
+		res, err := replay.Span(context.Background(), i)
+		require.NoError(t, err)
+		require.Equal(t, i, res.ID)
+		require.Equal(t, i*100, res.StartBlock)
+		require.Equal(t, "137", res.ChainID)
+	}
+
+	_, err = replay.Span(context.Background(), 4)
+	require.ErrorIs(t, err, ErrHeimdallResponseNotRecorded)
+
+	// The responses are served in the order recorded, then the last one again
+	for _, want := range []int64{1, 2, 3, 3} {
+		count, err := replay.FetchMilestoneCount(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, want, count)
+	}
+
+	require.NoError(t, replay.FetchMilestoneID(context.Background(), "known"))
+	require.ErrorIs(t, replay.FetchMilestoneID(context.Background(), "unknown"), heimdall.ErrNotInMilestoneList)
+}
@@ -1,9 +1,12 @@
 package contract
 
 import (
+	"bytes"
 	"context"
 	"math"
 	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -16,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -24,6 +28,9 @@ import (
 var (
 	vABI, _ = abi.JSON(strings.NewReader(validatorsetABI))
 	sABI, _ = abi.JSON(strings.NewReader(stateReceiverABI))
+
+	// stateReceiverCallFailures counts the failed reads of the state receiver contract
+	stateReceiverCallFailures = metrics.NewRegisteredCounter("bor/contract/statereceiver/failures", nil)
 )
 
 func ValidatorSet() abi.ABI {
@@ -64,6 +71,50 @@ func NewGenesisContractsClient(
 	}
 }
 
+// StateReceiverFor returns the state receiver contract the events targeting the
+// given contract are committed to at the given block.
+func (gc *GenesisContractsClient) StateReceiverFor(number uint64, target common.Address) common.Address {
+	for contract, receiver := range gc.chainConfig.Bor.CalculateStateReceiverContracts(number) {
+		if common.HexToAddress(contract) == target {
+			return common.HexToAddress(receiver)
+		}
+	}
+
+	return common.HexToAddress(gc.StateReceiverContract)
+}
+
+// stateReceiverContracts returns all the distinct state receiver contracts events
+// may have been committed to up to the given block, the default one first and the
+// others in address order. The receivers of the earlier forks are kept, as they
+// still hold the ids of the events committed to them.
+func (gc *GenesisContractsClient) stateReceiverContracts(number uint64) []common.Address {
+	receivers := []common.Address{common.HexToAddress(gc.StateReceiverContract)}
+
+	seen := map[common.Address]bool{receivers[0]: true}
+
+	var extra []common.Address
+
+	for fork, stateReceivers := range gc.chainConfig.Bor.StateReceiverContracts {
+		if block, err := strconv.ParseUint(fork, 10, 64); err != nil || block > number {
+			continue
+		}
+
+		for _, receiver := range stateReceivers {
+			address := common.HexToAddress(receiver)
+			if !seen[address] {
+				seen[address] = true
+				extra = append(extra, address)
+			}
+		}
+	}
+
+	sort.Slice(extra, func(i, j int) bool {
+		return bytes.Compare(extra[i][:], extra[j][:]) < 0
+	})
+
+	return append(receivers, extra...)
+}
+
 func (gc *GenesisContractsClient) CommitState(
 	event *clerk.EventRecordWithTime,
 	state *state.StateDB,
@@ -87,9 +138,10 @@ func (gc *GenesisContractsClient) CommitState(
 		return 0, err
 	}
 
-	msg := statefull.GetSystemMessage(common.HexToAddress(gc.StateReceiverContract), data)
+	receiver := gc.StateReceiverFor(header.Number.Uint64(), event.Contract)
+	msg := statefull.GetSystemMessage(receiver, data)
 
-	log.Info("→ committing new state", "eventRecord", event.ID)
+	log.Info("→ committing new state", "eventRecord", event.ID, "receiver", receiver)
 
 	gasUsed, err := statefull.ApplyMessage(context.Background(), msg, state, header, gc.chainConfig, chCtx)
 
@@ -103,7 +155,29 @@ func (gc *GenesisContractsClient) CommitState(
 	return gasUsed, nil
 }
 
+// LastStateId returns the ID of the last state-sync event committed, which is the
+// highest one across the state receiver contracts when the events are split over
+// several of them. Each receiver then sees gaps in the ids, so none of them may
+// require contiguous ids: an event whose commit reverts is behind the highest id,
+// and isn't fetched again.
 func (gc *GenesisContractsClient) LastStateId(state *state.StateDB, number uint64, hash common.Hash) (*big.Int, error) {
+	var last *big.Int
+
+	for _, receiver := range gc.stateReceiverContracts(number) {
+		id, err := gc.lastStateId(receiver, state, number, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if last == nil || id.Cmp(last) > 0 {
+			last = id
+		}
+	}
+
+	return last, nil
+}
+
+func (gc *GenesisContractsClient) lastStateId(receiver common.Address, state *state.StateDB, number uint64, hash common.Hash) (*big.Int, error) {
 	blockNr := rpc.BlockNumber(number)
 
 	const method = "lastStateId"
@@ -116,7 +190,7 @@ func (gc *GenesisContractsClient) LastStateId(state *state.StateDB, number uint6
 	}
 
 	msgData := (hexutil.Bytes)(data)
-	toAddress := common.HexToAddress(gc.StateReceiverContract)
+	toAddress := receiver
 	gas := (hexutil.Uint64)(uint64(math.MaxUint64 / 2))
 
 	// Do a call with state so that we can fetch the last state ID from a given (incoming)
@@ -127,6 +201,7 @@ func (gc *GenesisContractsClient) LastStateId(state *state.StateDB, number uint6
 		Data: &msgData,
 	}, rpc.BlockNumberOrHash{BlockNumber: &blockNr, BlockHash: &hash}, state, nil, nil)
 	if err != nil {
+		stateReceiverCallFailures.Inc(1)
 		return nil, err
 	}
 
@@ -0,0 +1,81 @@
+package contract
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/bor/api"
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastStateIdWithRevertedCommit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		defaultReceiver = common.HexToAddress("0x0000000000000000000000000000000000001001")
+		mappedReceiver  = common.HexToAddress("0x0000000000000000000000000000000000002001")
+		mappedTarget    = common.HexToAddress("0x0000000000000000000000000000000000003001")
+		otherTarget     = common.HexToAddress("0x0000000000000000000000000000000000003002")
+	)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	// The default receiver stores 1 at the slot 0, the mapped one reverts like a
+	// receiver requiring contiguous ids given an id past a gap
+	statedb.SetCode(defaultReceiver, common.FromHex("0x600160005500"))
+	statedb.SetCode(mappedReceiver, common.FromHex("0x60006000fd"))
+
+	caller := api.NewMockCaller(ctrl)
+	config := *params.TestChainConfig
+	borConfig := *config.Bor
+	borConfig.StateReceiverContracts = map[string]map[string]string{"0": {mappedTarget.Hex(): mappedReceiver.Hex()}}
+	config.Bor = &borConfig
+
+	gc := NewGenesisContractsClient(&config, "", defaultReceiver.Hex(), caller)
+
+	header := &types.Header{Number: big.NewInt(16), Difficulty: big.NewInt(1)}
+	event := func(id uint64, target common.Address) *clerk.EventRecordWithTime {
+		return &clerk.EventRecordWithTime{EventRecord: clerk.EventRecord{ID: id, Contract: target}, Time: time.Unix(1, 0)}
+	}
+
+	// The reverted commit doesn't fail the block, it's only missing from the state
+	_, err = gc.CommitState(event(2, mappedTarget), statedb, header, statefull.ChainContext{})
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, statedb.GetState(mappedReceiver, common.Hash{}))
+
+	_, err = gc.CommitState(event(3, otherTarget), statedb, header, statefull.ChainContext{})
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(common.Big1), statedb.GetState(defaultReceiver, common.Hash{}))
+
+	// The mapped receiver stays at the id before the reverted one, the highest id
+	// is the one of the default receiver, so the reverted event isn't fetched again
+	lastStateIds := map[common.Address]int64{defaultReceiver: 3, mappedReceiver: 1}
+
+	caller.EXPECT().CallWithState(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, args ethapi.TransactionArgs, _ rpc.BlockNumberOrHash, _ *state.StateDB, _ *ethapi.StateOverride, _ *ethapi.BlockOverrides) (hexutil.Bytes, error) {
+			return StateReceiver().Methods["lastStateId"].Outputs.Pack(big.NewInt(lastStateIds[*args.To]))
+		},
+	).Times(2)
+
+	last, err := gc.LastStateId(statedb, 15, common.Hash{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), last.Uint64())
+}
@@ -0,0 +1,108 @@
+package bor
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// EligibilityChange is posted when a change of the validator set adds the local
+// signer to, or removes it from, the active set.
+type EligibilityChange struct {
+	Signer   common.Address `json:"signer"`
+	Previous bool           `json:"previous"` // Whether the signer was in the set before Number
+	Eligible bool           `json:"eligible"` // Whether the signer is in the set from Number on
+	SpanID   uint64         `json:"spanID"`   // Span of the validator set taking effect
+	Number   uint64         `json:"number"`   // First block of the validator set taking effect
+}
+
+// eligibility is the last known membership of the local signer in the active set.
+type eligibility struct {
+	signer   common.Address
+	number   uint64
+	eligible bool
+}
+
+// SubscribeEligibilityChange registers a subscription for the changes of the
+// sealing eligibility of the local signer.
+func (c *Bor) SubscribeEligibilityChange(ch chan<- EligibilityChange) event.Subscription {
+	return c.eligibilityFeed.Subscribe(ch)
+}
+
+// checkEligibility is run at every sprint start, where the validator set can
+// change, and posts an EligibilityChange if the membership of the local signer
+// in the active set differs from the one of the last sprint start seen. The first
+// sprint start seen after startup (or after the signer changes) only records the
+// membership.
+func (c *Bor) checkEligibility(chain consensus.ChainHeaderReader, header *types.Header) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return
+	}
+
+	authorized := c.authorizedSigner.Load()
+	if authorized == nil || authorized.signer == (common.Address{}) {
+		return
+	}
+
+	signer := authorized.signer
+
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		log.Debug("Failed to get snapshot for the signer eligibility", "number", number, "err", err)
+		return
+	}
+
+	current := &eligibility{
+		signer:   signer,
+		number:   number,
+		eligible: snap.ValidatorSet.HasAddress(signer),
+	}
+
+	c.eligibilityLock.Lock()
+	last := c.eligibility
+
+	// Sprints already seen, e.g. a block finalized again while mining it, or an
+	// older block of a sidechain, don't change the eligibility
+	if last != nil && last.signer == signer && number <= last.number {
+		c.eligibilityLock.Unlock()
+		return
+	}
+
+	c.eligibility = current
+	c.eligibilityLock.Unlock()
+
+	if last == nil || last.signer != signer || last.eligible == current.eligible {
+		return
+	}
+
+	change := EligibilityChange{
+		Signer:   signer,
+		Previous: last.eligible,
+		Eligible: current.eligible,
+		Number:   number,
+	}
+
+	// The span of the block is the one at its parent, or the next one if the
+	// block starts a new span
+	if currentSpan, err := c.spanner.GetCurrentSpan(context.Background(), header.ParentHash); err == nil {
+		change.SpanID = currentSpan.ID
+		if number > currentSpan.EndBlock {
+			change.SpanID++
+		}
+	} else {
+		log.Debug("Failed to get the span of the signer eligibility change", "number", number, "err", err)
+	}
+
+	if change.Eligible {
+		log.Info("Local signer added to the validator set", "signer", signer, "number", number, "span", change.SpanID)
+	} else {
+		log.Warn("Local signer removed from the validator set", "signer", signer, "number", number, "span", change.SpanID)
+	}
+
+	c.eligibilityFeed.Send(change)
+}
@@ -2,8 +2,10 @@ package bor
 
 import (
 	"fmt"
+	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
 )
 
@@ -79,6 +81,23 @@ func (e *UnauthorizedSignerError) Error() string {
 	)
 }
 
+// OutOfTurnError is returned if a block is to be sealed by a signer which isn't the
+// in-turn proposer while out-of-turn sealing is disabled.
+type OutOfTurnError struct {
+	Number     uint64
+	Signer     []byte
+	Succession int
+}
+
+func (e *OutOfTurnError) Error() string {
+	return fmt.Sprintf(
+		"Signer 0x%x is out-of-turn (succession %d) at block %d and out-of-turn sealing is disabled",
+		e.Signer,
+		e.Succession,
+		e.Number,
+	)
+}
+
 // WrongDifficultyError is returned if the difficulty of a block doesn't match the
 // turn of the signer.
 type WrongDifficultyError struct {
@@ -114,3 +133,200 @@ func (e *InvalidStateReceivedError) Error() string {
 		e.LastStateID,
 	)
 }
+
+// StateSyncPayloadTooLargeError is returned when a state-sync event to apply has a
+// payload over the configured limit.
+type StateSyncPayloadTooLargeError struct {
+	Number  uint64
+	EventID uint64
+	Size    int
+	Limit   uint64
+}
+
+func (e *StateSyncPayloadTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"State-sync event %d at block %d has a payload of %d bytes, over the limit of %d bytes",
+		e.EventID,
+		e.Number,
+		e.Size,
+		e.Limit,
+	)
+}
+
+// InvalidExtraDataError is returned by the strict extra-data validation if a
+// part of the header's extra-data is malformed.
+type InvalidExtraDataError struct {
+	Number uint64
+	Field  string
+	Reason string
+}
+
+func (e *InvalidExtraDataError) Error() string {
+	return fmt.Sprintf(
+		"Invalid extra-data at block %d, field %s: %s",
+		e.Number,
+		e.Field,
+		e.Reason,
+	)
+}
+
+// ValidatorSetUnavailableError is returned if the validator set couldn't be read
+// from the validator contract, even after retrying against older states.
+type ValidatorSetUnavailableError struct {
+	Number   uint64
+	Attempts int
+	Err      error
+}
+
+func (e *ValidatorSetUnavailableError) Error() string {
+	return fmt.Sprintf(
+		"Unable to read validator set for block %d after %d attempts: %v",
+		e.Number,
+		e.Attempts,
+		e.Err,
+	)
+}
+
+func (e *ValidatorSetUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// ChainIDMismatchError is returned if a caller asks for data tied to a chain id
+// other than the one of the node's chain.
+type ChainIDMismatchError struct {
+	Expected *big.Int
+	Actual   *big.Int
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf(
+		"Chain id mismatch, expected: %v, actual: %v",
+		e.Expected,
+		e.Actual,
+	)
+}
+
+// StaleSpanError is returned by Seal if the head is close to the end of the current
+// span and the next span couldn't be fetched from heimdall.
+type StaleSpanError struct {
+	Number  uint64
+	SpanID  uint64
+	SpanEnd uint64
+	Err     error
+}
+
+func (e *StaleSpanError) Error() string {
+	return fmt.Sprintf(
+		"Refusing to seal block %d, span %d ends at block %d and the next span couldn't be fetched: %v",
+		e.Number,
+		e.SpanID,
+		e.SpanEnd,
+		e.Err,
+	)
+}
+
+func (e *StaleSpanError) Unwrap() error {
+	return e.Err
+}
+
+// SealStopBeforeSpanChangeError is returned by Seal close to the end of the span if
+// the signer isn't a producer of the next span.
+type SealStopBeforeSpanChangeError struct {
+	Number    uint64
+	SpanID    uint64
+	SpanStart uint64
+}
+
+func (e *SealStopBeforeSpanChangeError) Error() string {
+	return fmt.Sprintf(
+		"Refusing to seal block %d, the signer isn't a producer of span %d starting at block %d",
+		e.Number,
+		e.SpanID,
+		e.SpanStart,
+	)
+}
+
+// SpanCommitMismatchError is returned when building a block if the span committed
+// at the span boundary doesn't match the one reported by heimdall.
+type SpanCommitMismatchError struct {
+	Number uint64
+	SpanID uint64
+	Field  string
+}
+
+func (e *SpanCommitMismatchError) Error() string {
+	return fmt.Sprintf(
+		"Refusing to seal block %d, the %s of the committed span %d doesn't match heimdall",
+		e.Number,
+		e.Field,
+		e.SpanID,
+	)
+}
+
+// GenesisContractMissingError is returned at startup if one of the system contracts
+// of the bor config has no code in the genesis state.
+type GenesisContractMissingError struct {
+	Name    string
+	Address common.Address
+}
+
+func (e *GenesisContractMissingError) Error() string {
+	return fmt.Sprintf(
+		"No code for the %s contract at %s in the genesis state, check the bor config of the genesis",
+		e.Name,
+		e.Address,
+	)
+}
+
+// StateReceiverContiguousIDsError is returned at startup if a state receiver mapped
+// by the bor config runs the code of the default one, which requires the event ids
+// to be contiguous while the mapped receivers only get some of them.
+type StateReceiverContiguousIDsError struct {
+	Target   common.Address
+	Receiver common.Address
+}
+
+func (e *StateReceiverContiguousIDsError) Error() string {
+	return fmt.Sprintf(
+		"The state receiver %s of %s runs the code of the default state receiver, which requires contiguous event ids",
+		e.Receiver,
+		e.Target,
+	)
+}
+
+// GenesisSpanMismatchError is returned when building the genesis snapshot in strict
+// mode if the validator set of the genesis contract doesn't match heimdall's span 0.
+type GenesisSpanMismatchError struct {
+	Field    string
+	Contract string
+	Heimdall string
+}
+
+func (e *GenesisSpanMismatchError) Error() string {
+	return fmt.Sprintf(
+		"Genesis span mismatch, %s from the genesis contract: %s, from heimdall's span 0: %s",
+		e.Field,
+		e.Contract,
+		e.Heimdall,
+	)
+}
+
+// SnapshotWalkbackError is returned when a snapshot can't be reconstructed within
+// the maximum walk-back, as the checkpoint snapshot it would start from is missing
+// from the database.
+type SnapshotWalkbackError struct {
+	Number uint64
+	Hash   common.Hash
+	Walked uint64
+	Max    uint64
+}
+
+func (e *SnapshotWalkbackError) Error() string {
+	return fmt.Sprintf(
+		"Cannot reconstruct snapshot, checkpoint missing: no snapshot found walking back %d headers to block %d (%s), maximum %d",
+		e.Walked,
+		e.Number,
+		e.Hash,
+		e.Max,
+	)
+}
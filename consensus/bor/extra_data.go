@@ -0,0 +1,104 @@
+package bor
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Parts of the header's extra-data which are checked by the strict validation.
+const (
+	extraFieldVanity         = "vanity"
+	extraFieldSignature      = "signature"
+	extraFieldBlockExtraData = "blockExtraData"
+	extraFieldValidators     = "validators"
+)
+
+// validateExtraDataStrict checks the whole layout of the header's extra-data:
+// header.Extra = header.Vanity + header.ProducerBytes (optional) + header.Seal
+//
+// On top of the length checks done by validateHeaderExtraField, it makes sure that
+// the (optionally rlp encoded) validator bytes are only present at the end of a
+// sprint and that each entry is a well-formed, sorted (address, power) pair.
+func (c *Bor) validateExtraDataStrict(header *types.Header) error {
+	number := header.Number.Uint64()
+	extra := header.Extra
+
+	if len(extra) < types.ExtraVanityLength {
+		return newInvalidExtraDataError(number, extraFieldVanity, "have %d bytes, want at least %d", len(extra), types.ExtraVanityLength)
+	}
+
+	if len(extra) < types.ExtraVanityLength+types.ExtraSealLength {
+		return newInvalidExtraDataError(number, extraFieldSignature, "have %d bytes after the vanity, want at least %d", len(extra)-types.ExtraVanityLength, types.ExtraSealLength)
+	}
+
+	validatorBytes := extra[types.ExtraVanityLength : len(extra)-types.ExtraSealLength]
+
+	if c.config.IsParallelUniverse(header.Number) {
+		var blockExtraData types.BlockExtraData
+		if err := rlp.DecodeBytes(validatorBytes, &blockExtraData); err != nil {
+			return newInvalidExtraDataError(number, extraFieldBlockExtraData, "failed to decode: %v", err)
+		}
+
+		validatorBytes = blockExtraData.ValidatorBytes
+	}
+
+	isSprintEnd := IsSprintStart(number+1, c.config.CalculateSprint(number))
+
+	if !isSprintEnd {
+		if len(validatorBytes) != 0 {
+			return newInvalidExtraDataError(number, extraFieldValidators, "non-sprint-end block contains %d bytes of validators", len(validatorBytes))
+		}
+
+		return nil
+	}
+
+	if len(validatorBytes) == 0 {
+		return newInvalidExtraDataError(number, extraFieldValidators, "sprint-end block contains no validators")
+	}
+
+	if len(validatorBytes)%validatorHeaderBytesLength != 0 {
+		return newInvalidExtraDataError(number, extraFieldValidators, "length %d is not a multiple of %d", len(validatorBytes), validatorHeaderBytesLength)
+	}
+
+	var previous common.Address
+
+	for i := 0; i < len(validatorBytes)/validatorHeaderBytesLength; i++ {
+		entry := validatorBytes[i*validatorHeaderBytesLength : (i+1)*validatorHeaderBytesLength]
+		address := common.BytesToAddress(entry[:common.AddressLength])
+
+		if address == (common.Address{}) {
+			return newInvalidExtraDataError(number, extraFieldValidators, "entry %d has an empty address", i)
+		}
+
+		if new(big.Int).SetBytes(entry[common.AddressLength:]).Sign() == 0 {
+			return newInvalidExtraDataError(number, extraFieldValidators, "entry %d (%s) has no voting power", i, address)
+		}
+
+		if i > 0 && bytes.Compare(previous.Bytes(), address.Bytes()) >= 0 {
+			return newInvalidExtraDataError(number, extraFieldValidators, "entry %d (%s) is not sorted by address or is duplicated", i, address)
+		}
+
+		previous = address
+	}
+
+	return nil
+}
+
+// newInvalidExtraDataError builds an InvalidExtraDataError and accounts for
+// it in the metrics of the offending field.
+func newInvalidExtraDataError(number uint64, field string, format string, args ...interface{}) error {
+	if counter, ok := extraDataInvalidCounters[field]; ok {
+		counter.Inc(1)
+	}
+
+	return &InvalidExtraDataError{
+		Number: number,
+		Field:  field,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
@@ -1,17 +1,139 @@
 package bor
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
 	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 //go:generate mockgen -destination=./genesis_contract_mock.go -package=bor . GenesisContract
 type GenesisContract interface {
 	CommitState(event *clerk.EventRecordWithTime, state *state.StateDB, header *types.Header, chCtx statefull.ChainContext) (uint64, error)
 	LastStateId(state *state.StateDB, number uint64, hash common.Hash) (*big.Int, error)
+	StateReceiverFor(number uint64, target common.Address) common.Address
+}
+
+// VerifyGenesisContracts checks that the validator set and state receiver contracts
+// of the bor config host code in the genesis state stored in db, so that a wrong
+// contract address fails at startup rather than leaving the node unable to seal.
+func VerifyGenesisContracts(db ethdb.Database, config *params.BorConfig) error {
+	statedb, err := genesisState(db)
+	if err != nil {
+		return err
+	}
+
+	contracts := []struct {
+		name    string
+		address string
+	}{
+		{"validator set", config.ValidatorContract},
+		{"state receiver", config.StateReceiverContract},
+	}
+
+	// The receivers of the later forks may be deployed later on
+	for target, receiver := range config.CalculateStateReceiverContracts(0) {
+		contracts = append(contracts, struct {
+			name    string
+			address string
+		}{"state receiver of " + target, receiver})
+	}
+
+	for _, contract := range contracts {
+		address := common.HexToAddress(contract.address)
+		if statedb.GetCodeSize(address) == 0 {
+			return &GenesisContractMissingError{Name: contract.name, Address: address}
+		}
+	}
+
+	return nil
+}
+
+// ValidateStateReceiverContracts checks that none of the state receivers mapped by
+// StateReceiverContracts runs a code the default state receiver ever had, in the
+// genesis state stored in db or in the block allocs. The mapped receivers get gaps
+// in the event ids, while the default receiver requires contiguous ids: its commits
+// would revert, silently dropping the events, as the last state id is the highest
+// one across the receivers.
+func ValidateStateReceiverContracts(db ethdb.Database, config *params.BorConfig) error {
+	if len(config.StateReceiverContracts) == 0 {
+		return nil
+	}
+
+	statedb, err := genesisState(db)
+	if err != nil {
+		return err
+	}
+
+	var allocs []core.GenesisAlloc
+
+	for number, blockAlloc := range config.BlockAlloc {
+		alloc, err := decodeGenesisAlloc(blockAlloc)
+		if err != nil {
+			return fmt.Errorf("failed to decode the block alloc of %s: %w", number, err)
+		}
+
+		allocs = append(allocs, alloc)
+	}
+
+	// codeHashes returns the hashes of all the codes the contract had
+	codeHashes := func(address common.Address) map[common.Hash]bool {
+		hashes := make(map[common.Hash]bool)
+
+		if statedb.GetCodeSize(address) > 0 {
+			hashes[statedb.GetCodeHash(address)] = true
+		}
+
+		for _, alloc := range allocs {
+			if account, ok := alloc[address]; ok && len(account.Code) > 0 {
+				hashes[crypto.Keccak256Hash(account.Code)] = true
+			}
+		}
+
+		return hashes
+	}
+
+	defaultReceiver := common.HexToAddress(config.StateReceiverContract)
+	defaultCodes := codeHashes(defaultReceiver)
+
+	for _, stateReceivers := range config.StateReceiverContracts {
+		for target, receiver := range stateReceivers {
+			address := common.HexToAddress(receiver)
+			if address == defaultReceiver {
+				continue
+			}
+
+			for hash := range codeHashes(address) {
+				if defaultCodes[hash] {
+					return &StateReceiverContiguousIDsError{Target: common.HexToAddress(target), Receiver: address}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// genesisState opens the genesis state stored in db.
+func genesisState(db ethdb.Database) (*state.StateDB, error) {
+	header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, 0), 0)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	statedb, err := state.New(header.Root, state.NewDatabase(db), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the genesis state: %w", err)
+	}
+
+	return statedb, nil
 }
@@ -68,3 +68,17 @@ func (mr *MockGenesisContractMockRecorder) LastStateId(arg0, arg1, arg2 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastStateId", reflect.TypeOf((*MockGenesisContract)(nil).LastStateId), arg0, arg1, arg2)
 }
+
+// StateReceiverFor mocks base method.
+func (m *MockGenesisContract) StateReceiverFor(arg0 uint64, arg1 common.Address) common.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateReceiverFor", arg0, arg1)
+	ret0, _ := ret[0].(common.Address)
+	return ret0
+}
+
+// StateReceiverFor indicates an expected call of StateReceiverFor.
+func (mr *MockGenesisContractMockRecorder) StateReceiverFor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateReceiverFor", reflect.TypeOf((*MockGenesisContract)(nil).StateReceiverFor), arg0, arg1)
+}
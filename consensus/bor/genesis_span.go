@@ -0,0 +1,139 @@
+package bor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor/valset"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// GenesisSpanSource is the source of the validator set of the genesis span (span 0),
+// used for the genesis snapshot.
+type GenesisSpanSource string
+
+const (
+	// GenesisSpanSourceContract reads the validator set from the validator contract
+	// of the genesis state (default).
+	GenesisSpanSourceContract GenesisSpanSource = "contract"
+
+	// GenesisSpanSourceHeimdall uses the producers of the span 0 fetched from heimdall.
+	GenesisSpanSourceHeimdall GenesisSpanSource = "heimdall"
+
+	// GenesisSpanSourceStrict reads the validator set from the genesis contract and
+	// refuses to start if it doesn't match the producers of heimdall's span 0.
+	GenesisSpanSourceStrict GenesisSpanSource = "strict"
+)
+
+// ParseGenesisSpanSource parses a genesis span source, the empty string selects the default one.
+func ParseGenesisSpanSource(s string) (GenesisSpanSource, error) {
+	switch GenesisSpanSource(s) {
+	case "", GenesisSpanSourceContract:
+		return GenesisSpanSourceContract, nil
+	case GenesisSpanSourceHeimdall, GenesisSpanSourceStrict:
+		return GenesisSpanSource(s), nil
+	}
+
+	return "", fmt.Errorf("unknown genesis span source %q", s)
+}
+
+// genesisValidators returns the validator set of the genesis snapshot, taken from
+// the configured genesis span source.
+func (c *Bor) genesisValidators(ctx context.Context, hash common.Hash) ([]*valset.Validator, error) {
+	source := c.genesisSpanSource
+	if source == "" {
+		source = GenesisSpanSourceContract
+	}
+
+	var (
+		contractValidators []*valset.Validator
+		heimdallValidators []*valset.Validator
+		err                error
+	)
+
+	if source != GenesisSpanSourceHeimdall {
+		contractValidators, err = c.spanner.GetCurrentValidatorsByHash(ctx, hash, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if source != GenesisSpanSourceContract {
+		heimdallValidators, err = c.heimdallGenesisValidators(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch source {
+	case GenesisSpanSourceHeimdall:
+		log.Info("Using heimdall's span 0 for the genesis validator set", "validators", len(heimdallValidators))
+		return heimdallValidators, nil
+
+	case GenesisSpanSourceStrict:
+		if err := matchGenesisValidators(contractValidators, heimdallValidators); err != nil {
+			return nil, err
+		}
+
+		log.Info("Using the genesis contract for the genesis validator set, matching heimdall's span 0", "validators", len(contractValidators))
+
+		return contractValidators, nil
+	}
+
+	log.Info("Using the genesis contract for the genesis validator set", "validators", len(contractValidators))
+
+	return contractValidators, nil
+}
+
+// heimdallGenesisValidators returns the producers of the span 0 fetched from heimdall.
+func (c *Bor) heimdallGenesisValidators(ctx context.Context) ([]*valset.Validator, error) {
+	provider := c.getSpanProvider()
+	if provider == nil {
+		return nil, errHeimdallClientUnavailable
+	}
+
+	genesisSpan, err := provider.GetSpan(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make([]*valset.Validator, len(genesisSpan.SelectedProducers))
+	for i, producer := range genesisSpan.SelectedProducers {
+		validators[i] = valset.NewValidator(producer.Address, producer.VotingPower)
+	}
+
+	return validators, nil
+}
+
+// matchGenesisValidators checks that the validator sets read from the genesis
+// contract and from heimdall's span 0 are the same, in the same order.
+func matchGenesisValidators(contract, heimdall []*valset.Validator) error {
+	if len(contract) != len(heimdall) {
+		return &GenesisSpanMismatchError{
+			Field:    "validator count",
+			Contract: fmt.Sprint(len(contract)),
+			Heimdall: fmt.Sprint(len(heimdall)),
+		}
+	}
+
+	for i := range contract {
+		if contract[i].Address != heimdall[i].Address {
+			return &GenesisSpanMismatchError{
+				Field:    fmt.Sprintf("address of validator %d", i),
+				Contract: contract[i].Address.Hex(),
+				Heimdall: heimdall[i].Address.Hex(),
+			}
+		}
+
+		if contract[i].VotingPower != heimdall[i].VotingPower {
+			return &GenesisSpanMismatchError{
+				Field:    fmt.Sprintf("voting power of validator %s", contract[i].Address),
+				Contract: fmt.Sprint(contract[i].VotingPower),
+				Heimdall: fmt.Sprint(heimdall[i].VotingPower),
+			}
+		}
+	}
+
+	return nil
+}
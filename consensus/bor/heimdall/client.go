@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"sort"
 	"time"
 
@@ -27,6 +28,8 @@ var (
 	ErrNotInRejectedList     = errors.New("milestoneID doesn't exist in rejected list")
 	ErrNotInMilestoneList    = errors.New("milestoneID doesn't exist in Heimdall")
 	ErrServiceUnavailable    = errors.New("service unavailable")
+	ErrUnknownAPIVersion     = errors.New("unknown heimdall api version")
+	ErrNoServerTime          = errors.New("heimdall response without a valid date")
 )
 
 const (
@@ -47,6 +50,7 @@ type SpanResponse struct {
 
 type HeimdallClient struct {
 	urlString string
+	paths     apiPaths
 	client    http.Client
 	closeCh   chan struct{}
 }
@@ -60,6 +64,7 @@ type Request struct {
 func NewHeimdallClient(urlString string) *HeimdallClient {
 	return &HeimdallClient{
 		urlString: urlString,
+		paths:     apiVersions[DefaultAPIVersion],
 		client: http.Client{
 			Timeout: apiHeimdallTimeout,
 		},
@@ -82,13 +87,108 @@ const (
 	fetchMilestoneID        = "/milestone/ID/%s"
 
 	fetchSpanFormat = "bor/span/%d"
+	fetchLatestSpan = "bor/latest-span"
 )
 
+// The paths of the newer heimdall REST api, which renamed the span, milestone
+// and state sync endpoints. The checkpoint and the no-ack milestone endpoints
+// are shared with the older one.
+const (
+	fetchStateSyncEventsFormatV2 = "from_id=%d&to_time=%d&pagination.limit=%d"
+	fetchStateSyncEventsPathV2   = "clerk/time"
+
+	fetchMilestoneV2      = "/milestones/latest"
+	fetchMilestoneCountV2 = "/milestones/count"
+
+	fetchSpanFormatV2 = "bor/spans/%d"
+	fetchLatestSpanV2 = "bor/spans/latest"
+)
+
+// DefaultAPIVersion is the version of the heimdall REST api used by default.
+const DefaultAPIVersion = "v1"
+
+// apiPaths are the paths (and query formats) of the heimdall REST endpoints.
+type apiPaths struct {
+	stateSyncEventsFormat string
+	stateSyncEvents       string
+
+	checkpoint      string
+	checkpointCount string
+
+	milestone      string
+	milestoneCount string
+
+	lastNoAckMilestone string
+	noAckMilestone     string
+	milestoneID        string
+
+	spanFormat string
+	latestSpan string
+}
+
+// apiVersions are the known versions of the heimdall REST api.
+var apiVersions = map[string]apiPaths{
+	"v1": {
+		stateSyncEventsFormat: fetchStateSyncEventsFormat,
+		stateSyncEvents:       fetchStateSyncEventsPath,
+		checkpoint:            fetchCheckpoint,
+		checkpointCount:       fetchCheckpointCount,
+		milestone:             fetchMilestone,
+		milestoneCount:        fetchMilestoneCount,
+		lastNoAckMilestone:    fetchLastNoAckMilestone,
+		noAckMilestone:        fetchNoAckMilestone,
+		milestoneID:           fetchMilestoneID,
+		spanFormat:            fetchSpanFormat,
+		latestSpan:            fetchLatestSpan,
+	},
+	"v2": {
+		stateSyncEventsFormat: fetchStateSyncEventsFormatV2,
+		stateSyncEvents:       fetchStateSyncEventsPathV2,
+		checkpoint:            fetchCheckpoint,
+		checkpointCount:       fetchCheckpointCount,
+		milestone:             fetchMilestoneV2,
+		milestoneCount:        fetchMilestoneCountV2,
+		lastNoAckMilestone:    fetchLastNoAckMilestone,
+		noAckMilestone:        fetchNoAckMilestone,
+		milestoneID:           fetchMilestoneID,
+		spanFormat:            fetchSpanFormatV2,
+		latestSpan:            fetchLatestSpanV2,
+	},
+}
+
+// NewHeimdallClientWithAPIVersion creates a client of the given version of the
+// heimdall REST api, an empty version selecting the default one.
+func NewHeimdallClientWithAPIVersion(urlString string, version string) (*HeimdallClient, error) {
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+
+	paths, ok := apiVersions[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAPIVersion, version)
+	}
+
+	h := NewHeimdallClient(urlString)
+	h.paths = paths
+
+	return h, nil
+}
+
+// ValidateAPIVersion returns an error if the given version of the heimdall REST
+// api is unknown. An empty version selects the default one.
+func ValidateAPIVersion(version string) error {
+	if _, ok := apiVersions[version]; version != "" && !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAPIVersion, version)
+	}
+
+	return nil
+}
+
 func (h *HeimdallClient) StateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
 	eventRecords := make([]*clerk.EventRecordWithTime, 0)
 
 	for {
-		url, err := stateSyncURL(h.urlString, fromID, to)
+		url, err := h.paths.stateSyncURL(h.urlString, fromID, to)
 		if err != nil {
 			return nil, err
 		}
@@ -124,7 +224,24 @@ func (h *HeimdallClient) StateSyncEvents(ctx context.Context, fromID uint64, to
 }
 
 func (h *HeimdallClient) Span(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
-	url, err := spanURL(h.urlString, spanID)
+	url, err := h.paths.spanURL(h.urlString, spanID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = withRequestType(ctx, spanRequest)
+
+	response, err := FetchWithRetry[SpanResponse](ctx, h.client, url, h.closeCh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Result, nil
+}
+
+// LatestSpan fetches the latest span from heimdall
+func (h *HeimdallClient) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	url, err := h.paths.latestSpanURL(h.urlString)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +258,7 @@ func (h *HeimdallClient) Span(ctx context.Context, spanID uint64) (*span.Heimdal
 
 // FetchCheckpoint fetches the checkpoint from heimdall
 func (h *HeimdallClient) FetchCheckpoint(ctx context.Context, number int64) (*checkpoint.Checkpoint, error) {
-	url, err := checkpointURL(h.urlString, number)
+	url, err := h.paths.checkpointURL(h.urlString, number)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +275,7 @@ func (h *HeimdallClient) FetchCheckpoint(ctx context.Context, number int64) (*ch
 
 // FetchMilestone fetches the checkpoint from heimdall
 func (h *HeimdallClient) FetchMilestone(ctx context.Context) (*milestone.Milestone, error) {
-	url, err := milestoneURL(h.urlString)
+	url, err := h.paths.milestoneURL(h.urlString)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +292,7 @@ func (h *HeimdallClient) FetchMilestone(ctx context.Context) (*milestone.Milesto
 
 // FetchCheckpointCount fetches the checkpoint count from heimdall
 func (h *HeimdallClient) FetchCheckpointCount(ctx context.Context) (int64, error) {
-	url, err := checkpointCountURL(h.urlString)
+	url, err := h.paths.checkpointCountURL(h.urlString)
 	if err != nil {
 		return 0, err
 	}
@@ -192,7 +309,7 @@ func (h *HeimdallClient) FetchCheckpointCount(ctx context.Context) (int64, error
 
 // FetchMilestoneCount fetches the milestone count from heimdall
 func (h *HeimdallClient) FetchMilestoneCount(ctx context.Context) (int64, error) {
-	url, err := milestoneCountURL(h.urlString)
+	url, err := h.paths.milestoneCountURL(h.urlString)
 	if err != nil {
 		return 0, err
 	}
@@ -209,7 +326,7 @@ func (h *HeimdallClient) FetchMilestoneCount(ctx context.Context) (int64, error)
 
 // FetchLastNoAckMilestone fetches the last no-ack-milestone from heimdall
 func (h *HeimdallClient) FetchLastNoAckMilestone(ctx context.Context) (string, error) {
-	url, err := lastNoAckMilestoneURL(h.urlString)
+	url, err := h.paths.lastNoAckMilestoneURL(h.urlString)
 	if err != nil {
 		return "", err
 	}
@@ -226,7 +343,7 @@ func (h *HeimdallClient) FetchLastNoAckMilestone(ctx context.Context) (string, e
 
 // FetchNoAckMilestone fetches the last no-ack-milestone from heimdall
 func (h *HeimdallClient) FetchNoAckMilestone(ctx context.Context, milestoneID string) error {
-	url, err := noAckMilestoneURL(h.urlString, milestoneID)
+	url, err := h.paths.noAckMilestoneURL(h.urlString, milestoneID)
 	if err != nil {
 		return err
 	}
@@ -248,7 +365,7 @@ func (h *HeimdallClient) FetchNoAckMilestone(ctx context.Context, milestoneID st
 // FetchMilestoneID fetches the bool result from Heimdal whether the ID corresponding
 // to the given milestone is in process in Heimdall
 func (h *HeimdallClient) FetchMilestoneID(ctx context.Context, milestoneID string) error {
-	url, err := milestoneIDURL(h.urlString, milestoneID)
+	url, err := h.paths.milestoneIDURL(h.urlString, milestoneID)
 	if err != nil {
 		return err
 	}
@@ -334,6 +451,47 @@ retryLoop:
 	}
 }
 
+// ClockSkew measures the offset of the local clock from heimdall's, read from the
+// Date header of a single request (not retried). As the header has a resolution
+// of a second, the measure is accurate to about half a second. A positive skew
+// means that the local clock is ahead.
+func (h *HeimdallClient) ClockSkew(ctx context.Context) (time.Duration, error) {
+	url, err := h.paths.milestoneCountURL(h.urlString)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiHeimdallTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	end := time.Now()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNoServerTime, err)
+	}
+
+	// The date was taken while the request was in flight, anywhere within its second
+	local := start.Add(end.Sub(start) / 2)
+
+	return local.Sub(date.Add(500 * time.Millisecond)), nil
+}
+
 // Fetch returns data from heimdall
 func Fetch[T any](ctx context.Context, request *Request) (*T, error) {
 	isSuccessful := false
@@ -365,52 +523,56 @@ func Fetch[T any](ctx context.Context, request *Request) (*T, error) {
 	return result, nil
 }
 
-func spanURL(urlString string, spanID uint64) (*url.URL, error) {
-	return makeURL(urlString, fmt.Sprintf(fetchSpanFormat, spanID), "")
+func (p apiPaths) spanURL(urlString string, spanID uint64) (*url.URL, error) {
+	return makeURL(urlString, fmt.Sprintf(p.spanFormat, spanID), "")
 }
 
-func stateSyncURL(urlString string, fromID uint64, to int64) (*url.URL, error) {
-	queryParams := fmt.Sprintf(fetchStateSyncEventsFormat, fromID, to, stateFetchLimit)
+func (p apiPaths) latestSpanURL(urlString string) (*url.URL, error) {
+	return makeURL(urlString, p.latestSpan, "")
+}
+
+func (p apiPaths) stateSyncURL(urlString string, fromID uint64, to int64) (*url.URL, error) {
+	queryParams := fmt.Sprintf(p.stateSyncEventsFormat, fromID, to, stateFetchLimit)
 
-	return makeURL(urlString, fetchStateSyncEventsPath, queryParams)
+	return makeURL(urlString, p.stateSyncEvents, queryParams)
 }
 
-func checkpointURL(urlString string, number int64) (*url.URL, error) {
+func (p apiPaths) checkpointURL(urlString string, number int64) (*url.URL, error) {
 	url := ""
 	if number == -1 {
-		url = fmt.Sprintf(fetchCheckpoint, "latest")
+		url = fmt.Sprintf(p.checkpoint, "latest")
 	} else {
-		url = fmt.Sprintf(fetchCheckpoint, fmt.Sprint(number))
+		url = fmt.Sprintf(p.checkpoint, fmt.Sprint(number))
 	}
 
 	return makeURL(urlString, url, "")
 }
 
-func milestoneURL(urlString string) (*url.URL, error) {
-	url := fetchMilestone
+func (p apiPaths) milestoneURL(urlString string) (*url.URL, error) {
+	url := p.milestone
 
 	return makeURL(urlString, url, "")
 }
 
-func checkpointCountURL(urlString string) (*url.URL, error) {
-	return makeURL(urlString, fetchCheckpointCount, "")
+func (p apiPaths) checkpointCountURL(urlString string) (*url.URL, error) {
+	return makeURL(urlString, p.checkpointCount, "")
 }
 
-func milestoneCountURL(urlString string) (*url.URL, error) {
-	return makeURL(urlString, fetchMilestoneCount, "")
+func (p apiPaths) milestoneCountURL(urlString string) (*url.URL, error) {
+	return makeURL(urlString, p.milestoneCount, "")
 }
 
-func lastNoAckMilestoneURL(urlString string) (*url.URL, error) {
-	return makeURL(urlString, fetchLastNoAckMilestone, "")
+func (p apiPaths) lastNoAckMilestoneURL(urlString string) (*url.URL, error) {
+	return makeURL(urlString, p.lastNoAckMilestone, "")
 }
 
-func noAckMilestoneURL(urlString string, id string) (*url.URL, error) {
-	url := fmt.Sprintf(fetchNoAckMilestone, id)
+func (p apiPaths) noAckMilestoneURL(urlString string, id string) (*url.URL, error) {
+	url := fmt.Sprintf(p.noAckMilestone, id)
 	return makeURL(urlString, url, "")
 }
 
-func milestoneIDURL(urlString string, id string) (*url.URL, error) {
-	url := fmt.Sprintf(fetchMilestoneID, id)
+func (p apiPaths) milestoneIDURL(urlString string, id string) (*url.URL, error) {
+	url := fmt.Sprintf(p.milestoneID, id)
 	return makeURL(urlString, url, "")
 }
 
@@ -420,7 +582,9 @@ func makeURL(urlString, rawPath, rawQuery string) (*url.URL, error) {
 		return nil, err
 	}
 
-	u.Path = rawPath
+	// Keep the path of the base url (if any), so that heimdall can be served
+	// behind a prefix, e.g. by a caching proxy.
+	u.Path = path.Join(u.Path, rawPath)
 	u.RawQuery = rawQuery
 
 	return u, err
@@ -476,3 +640,8 @@ func (h *HeimdallClient) Close() {
 	close(h.closeCh)
 	h.client.CloseIdleConnections()
 }
+
+// ClientType returns the type of the client and the url of the heimdall REST server.
+func (h *HeimdallClient) ClientType() (string, string) {
+	return "http", h.urlString
+}
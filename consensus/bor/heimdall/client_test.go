@@ -8,7 +8,10 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -391,7 +394,7 @@ func TestContext(t *testing.T) {
 func TestSpanURL(t *testing.T) {
 	t.Parallel()
 
-	url, err := spanURL("http://bor0", 1)
+	url, err := apiVersions[DefaultAPIVersion].spanURL("http://bor0", 1)
 	if err != nil {
 		t.Fatal("got an error", err)
 	}
@@ -406,7 +409,7 @@ func TestSpanURL(t *testing.T) {
 func TestStateSyncURL(t *testing.T) {
 	t.Parallel()
 
-	url, err := stateSyncURL("http://bor0", 10, 100)
+	url, err := apiVersions[DefaultAPIVersion].stateSyncURL("http://bor0", 10, 100)
 	if err != nil {
 		t.Fatal("got an error", err)
 	}
@@ -417,3 +420,147 @@ func TestStateSyncURL(t *testing.T) {
 		t.Fatalf("expected URL %q, got %q", url.String(), expected)
 	}
 }
+
+func TestSpanURLWithBasePath(t *testing.T) {
+	t.Parallel()
+
+	url, err := apiVersions[DefaultAPIVersion].spanURL("http://proxy:8080/heimdall", 1)
+	if err != nil {
+		t.Fatal("got an error", err)
+	}
+
+	const expected = "http://proxy:8080/heimdall/bor/span/1"
+
+	if url.String() != expected {
+		t.Fatalf("expected URL %q, got %q", url.String(), expected)
+	}
+}
+
+func TestLatestSpanURL(t *testing.T) {
+	t.Parallel()
+
+	url, err := apiVersions[DefaultAPIVersion].latestSpanURL("http://bor0")
+	if err != nil {
+		t.Fatal("got an error", err)
+	}
+
+	const expected = "http://bor0/bor/latest-span"
+
+	if url.String() != expected {
+		t.Fatalf("expected URL %q, got %q", url.String(), expected)
+	}
+}
+
+func TestAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateAPIVersion(""))
+	require.NoError(t, ValidateAPIVersion(DefaultAPIVersion))
+	require.ErrorIs(t, ValidateAPIVersion("v0"), ErrUnknownAPIVersion)
+
+	h, err := NewHeimdallClientWithAPIVersion("http://bor0", "")
+	require.NoError(t, err)
+
+	url, err := h.paths.spanURL(h.urlString, 1)
+	require.NoError(t, err)
+	require.Equal(t, "http://bor0/bor/span/1", url.String())
+
+	_, err = NewHeimdallClientWithAPIVersion("http://bor0", "v0")
+	require.ErrorIs(t, err, ErrUnknownAPIVersion)
+}
+
+func TestAPIVersionURLs(t *testing.T) {
+	t.Parallel()
+
+	type urls struct {
+		stateSync, span, latestSpan, checkpoint, checkpointCount, milestone, milestoneCount string
+	}
+
+	expected := map[string]urls{
+		"v1": {
+			stateSync:       "http://bor0/clerk/event-record/list?from-id=1&to-time=2&limit=50",
+			span:            "http://bor0/bor/span/3",
+			latestSpan:      "http://bor0/bor/latest-span",
+			checkpoint:      "http://bor0/checkpoints/4",
+			checkpointCount: "http://bor0/checkpoints/count",
+			milestone:       "http://bor0/milestone/latest",
+			milestoneCount:  "http://bor0/milestone/count",
+		},
+		"v2": {
+			stateSync:       "http://bor0/clerk/time?from_id=1&to_time=2&pagination.limit=50",
+			span:            "http://bor0/bor/spans/3",
+			latestSpan:      "http://bor0/bor/spans/latest",
+			checkpoint:      "http://bor0/checkpoints/4",
+			checkpointCount: "http://bor0/checkpoints/count",
+			milestone:       "http://bor0/milestones/latest",
+			milestoneCount:  "http://bor0/milestones/count",
+		},
+	}
+
+	require.Len(t, apiVersions, len(expected))
+
+	for version, want := range expected {
+		version, want := version, want
+
+		t.Run(version, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, ValidateAPIVersion(version))
+
+			h, err := NewHeimdallClientWithAPIVersion("http://bor0", version)
+			require.NoError(t, err)
+
+			resolve := func(u *url.URL, err error) string {
+				t.Helper()
+				require.NoError(t, err)
+
+				return u.String()
+			}
+
+			require.Equal(t, want.stateSync, resolve(h.paths.stateSyncURL(h.urlString, 1, 2)))
+			require.Equal(t, want.span, resolve(h.paths.spanURL(h.urlString, 3)))
+			require.Equal(t, want.latestSpan, resolve(h.paths.latestSpanURL(h.urlString)))
+			require.Equal(t, want.checkpoint, resolve(h.paths.checkpointURL(h.urlString, 4)))
+			require.Equal(t, want.checkpointCount, resolve(h.paths.checkpointCountURL(h.urlString)))
+			require.Equal(t, want.milestone, resolve(h.paths.milestoneURL(h.urlString)))
+			require.Equal(t, want.milestoneCount, resolve(h.paths.milestoneCountURL(h.urlString)))
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	t.Parallel()
+
+	var offset atomic.Int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if offset := time.Duration(offset.Load()); offset != 0 {
+			w.Header().Set("Date", time.Now().Add(-offset).UTC().Format(http.TimeFormat))
+		} else {
+			w.Header()["Date"] = nil
+		}
+	}))
+	defer srv.Close()
+
+	client := NewHeimdallClient(srv.URL)
+
+	// The local clock is ahead of heimdall's
+	offset.Store(int64(10 * time.Second))
+
+	skew, err := client.ClockSkew(context.Background())
+	require.NoError(t, err)
+	require.InDelta(t, float64(10*time.Second), float64(skew), float64(time.Second))
+
+	// Or behind
+	offset.Store(int64(-10 * time.Second))
+
+	skew, err = client.ClockSkew(context.Background())
+	require.NoError(t, err)
+	require.InDelta(t, float64(-10*time.Second), float64(skew), float64(time.Second))
+
+	// Without a date, the skew can't be measured
+	offset.Store(0)
+
+	_, err = client.ClockSkew(context.Background())
+	require.ErrorIs(t, err, ErrNoServerTime)
+}
@@ -8,12 +8,13 @@ import (
 
 // milestone defines a response object type of bor milestone
 type Milestone struct {
-	Proposer   common.Address `json:"proposer"`
-	StartBlock *big.Int       `json:"start_block"`
-	EndBlock   *big.Int       `json:"end_block"`
-	Hash       common.Hash    `json:"hash"`
-	BorChainID string         `json:"bor_chain_id"`
-	Timestamp  uint64         `json:"timestamp"`
+	Proposer    common.Address `json:"proposer"`
+	StartBlock  *big.Int       `json:"start_block"`
+	EndBlock    *big.Int       `json:"end_block"`
+	Hash        common.Hash    `json:"hash"`
+	BorChainID  string         `json:"bor_chain_id"`
+	MilestoneID string         `json:"milestone_id"`
+	Timestamp   uint64         `json:"timestamp"`
 }
 
 type MilestoneResponse struct {
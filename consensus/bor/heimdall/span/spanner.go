@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/consensus/bor/abi"
 	"github.com/ethereum/go-ethereum/consensus/bor/api"
 	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
@@ -17,16 +18,36 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+var (
+	// validatorSetCallFailures counts the failed reads of the validator contract
+	validatorSetCallFailures = metrics.NewRegisteredCounter("bor/contract/validatorset/failures", nil)
+
+	// Metrics for the hits and misses of the validator set reads cache
+	validatorSetCacheHits   = metrics.NewRegisteredCounter("bor/contract/validatorset/cache/hits", nil)
+	validatorSetCacheMisses = metrics.NewRegisteredCounter("bor/contract/validatorset/cache/misses", nil)
+)
+
+// validatorSetCacheKey identifies a read of the validator set, the state being
+// identified by the block hash it's read at.
+type validatorSetCacheKey struct {
+	contract    common.Address
+	blockHash   common.Hash
+	blockNumber uint64
+}
+
 type ChainSpanner struct {
 	ethAPI                   api.Caller
 	validatorSet             abi.ABI
 	chainConfig              *params.ChainConfig
 	validatorContractAddress common.Address
+
+	validatorSetCache *lru.Cache[validatorSetCacheKey, []*valset.Validator] // Validator set reads by block hash, nil if disabled
 }
 
 func NewChainSpanner(ethAPI api.Caller, validatorSet abi.ABI, chainConfig *params.ChainConfig, validatorContractAddress common.Address) *ChainSpanner {
@@ -38,6 +59,19 @@ func NewChainSpanner(ethAPI api.Caller, validatorSet abi.ABI, chainConfig *param
 	}
 }
 
+// EnableValidatorSetCache caches up to size validator set reads done at a block
+// hash, saving the repeated contract calls for the same block. The reads at a
+// block number aren't cached. As the entries are keyed by block hash, a reorg
+// can't make them stale. A size of 0 disables the cache.
+func (c *ChainSpanner) EnableValidatorSetCache(size int) {
+	if size <= 0 {
+		c.validatorSetCache = nil
+		return
+	}
+
+	c.validatorSetCache = lru.NewCache[validatorSetCacheKey, []*valset.Validator](size)
+}
+
 // GetCurrentSpan get current span from contract
 func (c *ChainSpanner) GetCurrentSpan(ctx context.Context, headerHash common.Hash) (*Span, error) {
 	// block
@@ -64,6 +98,7 @@ func (c *ChainSpanner) GetCurrentSpan(ctx context.Context, headerHash common.Has
 		Data: &msgData,
 	}, blockNr, nil, nil)
 	if err != nil {
+		validatorSetCallFailures.Inc(1)
 		return nil, err
 	}
 
@@ -90,6 +125,24 @@ func (c *ChainSpanner) GetCurrentSpan(ctx context.Context, headerHash common.Has
 
 // GetCurrentValidators get current validators
 func (c *ChainSpanner) GetCurrentValidatorsByBlockNrOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, blockNumber uint64) ([]*valset.Validator, error) {
+	blockHash, cacheable := blockNrOrHash.Hash()
+	cacheable = cacheable && c.validatorSetCache != nil
+
+	key := validatorSetCacheKey{
+		contract:    c.validatorContractAddress,
+		blockHash:   blockHash,
+		blockNumber: blockNumber,
+	}
+
+	if cacheable {
+		if valz, ok := c.validatorSetCache.Get(key); ok {
+			validatorSetCacheHits.Inc(1)
+			return copyValidators(valz), nil
+		}
+
+		validatorSetCacheMisses.Inc(1)
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -113,6 +166,7 @@ func (c *ChainSpanner) GetCurrentValidatorsByBlockNrOrHash(ctx context.Context,
 		Data: &msgData,
 	}, blockNrOrHash, nil, nil)
 	if err != nil {
+		validatorSetCallFailures.Inc(1)
 		return nil, err
 	}
 
@@ -138,9 +192,24 @@ func (c *ChainSpanner) GetCurrentValidatorsByBlockNrOrHash(ctx context.Context,
 		}
 	}
 
+	if cacheable {
+		c.validatorSetCache.Add(key, copyValidators(valz))
+	}
+
 	return valz, nil
 }
 
+// copyValidators deep copies the validators, so that the cached ones aren't
+// modified by the callers.
+func copyValidators(valz []*valset.Validator) []*valset.Validator {
+	cpy := make([]*valset.Validator, len(valz))
+	for i, val := range valz {
+		cpy[i] = val.Copy()
+	}
+
+	return cpy
+}
+
 func (c *ChainSpanner) GetCurrentValidatorsByHash(ctx context.Context, headerHash common.Hash, blockNumber uint64) ([]*valset.Validator, error) {
 	blockNr := rpc.BlockNumberOrHashWithHash(headerHash, false)
 
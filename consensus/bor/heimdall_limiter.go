@@ -0,0 +1,154 @@
+package bor
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/checkpoint"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+)
+
+// HeimdallLimitedClient bounds the number of outstanding calls to the wrapped
+// heimdall client. Calls beyond the limit wait for a free slot until their
+// context is done.
+type HeimdallLimitedClient struct {
+	client IHeimdallClient
+	sem    chan struct{}
+}
+
+// NewHeimdallLimitedClient wraps the given client so that at most limit calls
+// are in flight at any time.
+func NewHeimdallLimitedClient(client IHeimdallClient, limit int) *HeimdallLimitedClient {
+	return &HeimdallLimitedClient{
+		client: client,
+		sem:    make(chan struct{}, limit),
+	}
+}
+
+// acquire waits for a free slot, it returns the context's error if it's done first.
+func (h *HeimdallLimitedClient) acquire(ctx context.Context) error {
+	select {
+	case h.sem <- struct{}{}:
+		heimdallInFlightGauge.Inc(1)
+		return nil
+	default:
+	}
+
+	heimdallQueuedGauge.Inc(1)
+	defer heimdallQueuedGauge.Dec(1)
+
+	select {
+	case h.sem <- struct{}{}:
+		heimdallInFlightGauge.Inc(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *HeimdallLimitedClient) release() {
+	heimdallInFlightGauge.Dec(1)
+	<-h.sem
+}
+
+func (h *HeimdallLimitedClient) StateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
+	if err := h.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.release()
+
+	return h.client.StateSyncEvents(ctx, fromID, to)
+}
+
+func (h *HeimdallLimitedClient) Span(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	if err := h.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.release()
+
+	return h.client.Span(ctx, spanID)
+}
+
+func (h *HeimdallLimitedClient) FetchCheckpoint(ctx context.Context, number int64) (*checkpoint.Checkpoint, error) {
+	if err := h.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.release()
+
+	return h.client.FetchCheckpoint(ctx, number)
+}
+
+func (h *HeimdallLimitedClient) FetchCheckpointCount(ctx context.Context) (int64, error) {
+	if err := h.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer h.release()
+
+	return h.client.FetchCheckpointCount(ctx)
+}
+
+func (h *HeimdallLimitedClient) FetchMilestone(ctx context.Context) (*milestone.Milestone, error) {
+	if err := h.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.release()
+
+	return h.client.FetchMilestone(ctx)
+}
+
+func (h *HeimdallLimitedClient) FetchMilestoneCount(ctx context.Context) (int64, error) {
+	if err := h.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer h.release()
+
+	return h.client.FetchMilestoneCount(ctx)
+}
+
+func (h *HeimdallLimitedClient) FetchNoAckMilestone(ctx context.Context, milestoneID string) error {
+	if err := h.acquire(ctx); err != nil {
+		return err
+	}
+	defer h.release()
+
+	return h.client.FetchNoAckMilestone(ctx, milestoneID)
+}
+
+func (h *HeimdallLimitedClient) FetchLastNoAckMilestone(ctx context.Context) (string, error) {
+	if err := h.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer h.release()
+
+	return h.client.FetchLastNoAckMilestone(ctx)
+}
+
+func (h *HeimdallLimitedClient) FetchMilestoneID(ctx context.Context, milestoneID string) error {
+	if err := h.acquire(ctx); err != nil {
+		return err
+	}
+	defer h.release()
+
+	return h.client.FetchMilestoneID(ctx, milestoneID)
+}
+
+// LatestSpan fetches the latest span, if supported by the wrapped client.
+func (h *HeimdallLimitedClient) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	fetcher, ok := h.client.(latestSpanFetcher)
+	if !ok {
+		return nil, errLatestSpanNotSupported
+	}
+
+	if err := h.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.release()
+
+	return fetcher.LatestSpan(ctx)
+}
+
+// Close closes the wrapped client.
+func (h *HeimdallLimitedClient) Close() {
+	h.client.Close()
+}
@@ -0,0 +1,150 @@
+package bor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/checkpoint"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HeimdallProxyClient routes all the heimdall calls through a (caching) proxy
+// serving the heimdall REST api, so that repeated fetches across a fleet of nodes
+// can be deduplicated upstream. If the proxy fails to serve a call, the call is
+// retried against the wrapped client.
+type HeimdallProxyClient struct {
+	proxy  IHeimdallClient
+	client IHeimdallClient
+}
+
+// NewHeimdallProxyClient wraps the given client so that its calls are served by proxy.
+func NewHeimdallProxyClient(proxy IHeimdallClient, client IHeimdallClient) *HeimdallProxyClient {
+	return &HeimdallProxyClient{
+		proxy:  proxy,
+		client: client,
+	}
+}
+
+// fallback reports whether a failed proxy call should be retried on the wrapped client.
+// Answers from heimdall itself (e.g. an unknown milestone id) are not retried.
+func (h *HeimdallProxyClient) fallback(ctx context.Context, method string, err error) bool {
+	if ctx.Err() != nil ||
+		errors.Is(err, heimdall.ErrShutdownDetected) ||
+		errors.Is(err, heimdall.ErrNotInRejectedList) ||
+		errors.Is(err, heimdall.ErrNotInMilestoneList) {
+		return false
+	}
+
+	log.Debug("Heimdall proxy call failed, falling back to the direct client", "method", method, "err", err)
+
+	return true
+}
+
+func (h *HeimdallProxyClient) StateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
+	events, err := h.proxy.StateSyncEvents(ctx, fromID, to)
+	if err != nil && h.fallback(ctx, "StateSyncEvents", err) {
+		return h.client.StateSyncEvents(ctx, fromID, to)
+	}
+
+	return events, err
+}
+
+func (h *HeimdallProxyClient) Span(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	res, err := h.proxy.Span(ctx, spanID)
+	if err != nil && h.fallback(ctx, "Span", err) {
+		return h.client.Span(ctx, spanID)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchCheckpoint(ctx context.Context, number int64) (*checkpoint.Checkpoint, error) {
+	res, err := h.proxy.FetchCheckpoint(ctx, number)
+	if err != nil && h.fallback(ctx, "FetchCheckpoint", err) {
+		return h.client.FetchCheckpoint(ctx, number)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchCheckpointCount(ctx context.Context) (int64, error) {
+	res, err := h.proxy.FetchCheckpointCount(ctx)
+	if err != nil && h.fallback(ctx, "FetchCheckpointCount", err) {
+		return h.client.FetchCheckpointCount(ctx)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchMilestone(ctx context.Context) (*milestone.Milestone, error) {
+	res, err := h.proxy.FetchMilestone(ctx)
+	if err != nil && h.fallback(ctx, "FetchMilestone", err) {
+		return h.client.FetchMilestone(ctx)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchMilestoneCount(ctx context.Context) (int64, error) {
+	res, err := h.proxy.FetchMilestoneCount(ctx)
+	if err != nil && h.fallback(ctx, "FetchMilestoneCount", err) {
+		return h.client.FetchMilestoneCount(ctx)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchNoAckMilestone(ctx context.Context, milestoneID string) error {
+	err := h.proxy.FetchNoAckMilestone(ctx, milestoneID)
+	if err != nil && h.fallback(ctx, "FetchNoAckMilestone", err) {
+		return h.client.FetchNoAckMilestone(ctx, milestoneID)
+	}
+
+	return err
+}
+
+func (h *HeimdallProxyClient) FetchLastNoAckMilestone(ctx context.Context) (string, error) {
+	res, err := h.proxy.FetchLastNoAckMilestone(ctx)
+	if err != nil && h.fallback(ctx, "FetchLastNoAckMilestone", err) {
+		return h.client.FetchLastNoAckMilestone(ctx)
+	}
+
+	return res, err
+}
+
+func (h *HeimdallProxyClient) FetchMilestoneID(ctx context.Context, milestoneID string) error {
+	err := h.proxy.FetchMilestoneID(ctx, milestoneID)
+	if err != nil && h.fallback(ctx, "FetchMilestoneID", err) {
+		return h.client.FetchMilestoneID(ctx, milestoneID)
+	}
+
+	return err
+}
+
+// LatestSpan fetches the latest span through the proxy, falling back to the wrapped
+// client as the other calls. Either is skipped if it can't fetch the latest span.
+func (h *HeimdallProxyClient) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	if proxy, ok := h.proxy.(latestSpanFetcher); ok {
+		res, err := proxy.LatestSpan(ctx)
+		if err == nil || !h.fallback(ctx, "LatestSpan", err) {
+			return res, err
+		}
+	}
+
+	client, ok := h.client.(latestSpanFetcher)
+	if !ok {
+		return nil, errLatestSpanNotSupported
+	}
+
+	return client.LatestSpan(ctx)
+}
+
+// Close closes both the proxy and the wrapped client.
+func (h *HeimdallProxyClient) Close() {
+	h.proxy.Close()
+	h.client.Close()
+}
@@ -0,0 +1,338 @@
+package bor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/checkpoint"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrHeimdallResponseNotRecorded is returned by the replay client for a request
+// whose response wasn't recorded.
+var ErrHeimdallResponseNotRecorded = errors.New("heimdall response not recorded")
+
+// Paths (and parameters) of the heimdall requests, indexing the recorded responses.
+const (
+	recordStateSyncEventsFormat = "clerk/event-record/list?from-id=%d&to-time=%d"
+	recordSpanFormat            = "bor/span/%d"
+	recordLatestSpan            = "bor/latest-span"
+	recordCheckpointFormat      = "checkpoints/%d"
+	recordCheckpointCount       = "checkpoints/count"
+	recordMilestone             = "milestone/latest"
+	recordMilestoneCount        = "milestone/count"
+	recordLastNoAckMilestone    = "milestone/lastNoAck"
+	recordNoAckMilestoneFormat  = "milestone/noAck/%s"
+	recordMilestoneIDFormat     = "milestone/ID/%s"
+)
+
+// heimdallRecordFile returns the file of the given directory holding the responses
+// recorded for a request.
+func heimdallRecordFile(dir string, request string) string {
+	return filepath.Join(dir, url.QueryEscape(request)+".json")
+}
+
+// readHeimdallRecord reads the responses recorded for a request, in the order
+// they were received.
+func readHeimdallRecord(dir string, request string) ([]json.RawMessage, error) {
+	data, err := os.ReadFile(heimdallRecordFile(dir, request))
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []json.RawMessage
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("corrupt heimdall record of %s: %w", request, err)
+	}
+
+	return responses, nil
+}
+
+// HeimdallRecordingClient mirrors every successful response of the wrapped heimdall
+// client to a directory, one file per request path and parameters, so that the
+// interactions with heimdall can be replayed with a HeimdallReplayClient. The
+// responses of a request are kept in the order received, a response identical to
+// the previous one of the same request being recorded once.
+type HeimdallRecordingClient struct {
+	client IHeimdallClient
+	dir    string
+	lock   sync.Mutex // Serializes the writes to the record files
+}
+
+// NewHeimdallRecordingClient wraps the given client so that its responses are
+// recorded to dir, which is created if missing.
+func NewHeimdallRecordingClient(client IHeimdallClient, dir string) (*HeimdallRecordingClient, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &HeimdallRecordingClient{client: client, dir: dir}, nil
+}
+
+// record appends the response of a successful request to its record file. A
+// failure to record is logged, not returned, as it doesn't affect the call.
+func (h *HeimdallRecordingClient) record(request string, response any, err error) {
+	if err != nil {
+		return
+	}
+
+	enc, err := json.Marshal(response)
+	if err != nil {
+		log.Warn("Failed to encode the heimdall response to record", "request", request, "err", err)
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	responses, err := readHeimdallRecord(h.dir, request)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warn("Failed to read the heimdall record", "request", request, "err", err)
+		return
+	}
+
+	if len(responses) > 0 && string(responses[len(responses)-1]) == string(enc) {
+		return
+	}
+
+	data, err := json.Marshal(append(responses, enc))
+	if err != nil {
+		log.Warn("Failed to encode the heimdall record", "request", request, "err", err)
+		return
+	}
+
+	// Write through a temporary file, so that a record is never left half written
+	file := heimdallRecordFile(h.dir, request)
+	if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+		log.Warn("Failed to write the heimdall record", "request", request, "err", err)
+		return
+	}
+
+	if err := os.Rename(file+".tmp", file); err != nil {
+		log.Warn("Failed to write the heimdall record", "request", request, "err", err)
+	}
+}
+
+func (h *HeimdallRecordingClient) StateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
+	events, err := h.client.StateSyncEvents(ctx, fromID, to)
+	h.record(fmt.Sprintf(recordStateSyncEventsFormat, fromID, to), events, err)
+
+	return events, err
+}
+
+func (h *HeimdallRecordingClient) Span(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	res, err := h.client.Span(ctx, spanID)
+	h.record(fmt.Sprintf(recordSpanFormat, spanID), res, err)
+
+	return res, err
+}
+
+// LatestSpan fetches the latest span, if supported by the wrapped client.
+func (h *HeimdallRecordingClient) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	fetcher, ok := h.client.(latestSpanFetcher)
+	if !ok {
+		return nil, errLatestSpanNotSupported
+	}
+
+	res, err := fetcher.LatestSpan(ctx)
+	h.record(recordLatestSpan, res, err)
+
+	return res, err
+}
+
+func (h *HeimdallRecordingClient) FetchCheckpoint(ctx context.Context, number int64) (*checkpoint.Checkpoint, error) {
+	res, err := h.client.FetchCheckpoint(ctx, number)
+	h.record(fmt.Sprintf(recordCheckpointFormat, number), res, err)
+
+	return res, err
+}
+
+func (h *HeimdallRecordingClient) FetchCheckpointCount(ctx context.Context) (int64, error) {
+	count, err := h.client.FetchCheckpointCount(ctx)
+	h.record(recordCheckpointCount, count, err)
+
+	return count, err
+}
+
+func (h *HeimdallRecordingClient) FetchMilestone(ctx context.Context) (*milestone.Milestone, error) {
+	res, err := h.client.FetchMilestone(ctx)
+	h.record(recordMilestone, res, err)
+
+	return res, err
+}
+
+func (h *HeimdallRecordingClient) FetchMilestoneCount(ctx context.Context) (int64, error) {
+	count, err := h.client.FetchMilestoneCount(ctx)
+	h.record(recordMilestoneCount, count, err)
+
+	return count, err
+}
+
+func (h *HeimdallRecordingClient) FetchNoAckMilestone(ctx context.Context, milestoneID string) error {
+	err := h.client.FetchNoAckMilestone(ctx, milestoneID)
+	h.record(fmt.Sprintf(recordNoAckMilestoneFormat, milestoneID), nil, err)
+
+	return err
+}
+
+func (h *HeimdallRecordingClient) FetchLastNoAckMilestone(ctx context.Context) (string, error) {
+	milestoneID, err := h.client.FetchLastNoAckMilestone(ctx)
+	h.record(recordLastNoAckMilestone, milestoneID, err)
+
+	return milestoneID, err
+}
+
+func (h *HeimdallRecordingClient) FetchMilestoneID(ctx context.Context, milestoneID string) error {
+	err := h.client.FetchMilestoneID(ctx, milestoneID)
+	h.record(fmt.Sprintf(recordMilestoneIDFormat, milestoneID), nil, err)
+
+	return err
+}
+
+// Close closes the wrapped client.
+func (h *HeimdallRecordingClient) Close() {
+	h.client.Close()
+}
+
+// HeimdallReplayClient serves the heimdall responses recorded by a
+// HeimdallRecordingClient, without any heimdall. The responses of a request are
+// served in the order they were recorded, the last one being served again once
+// all of them were. A request without any recorded response fails with
+// ErrHeimdallResponseNotRecorded, or the "not in list" error of heimdall for the
+// milestone id checks.
+type HeimdallReplayClient struct {
+	dir    string
+	served map[string]int // Number of responses served per request
+	lock   sync.Mutex     // Protects served
+}
+
+// NewHeimdallReplayClient creates a client replaying the heimdall responses
+// recorded to dir.
+func NewHeimdallReplayClient(dir string) (*HeimdallReplayClient, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	return &HeimdallReplayClient{dir: dir, served: make(map[string]int)}, nil
+}
+
+// replay decodes the next recorded response of a request into result.
+func (h *HeimdallReplayClient) replay(request string, result any) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	responses, err := readHeimdallRecord(h.dir, request)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(responses) == 0) {
+		return fmt.Errorf("%w: %s", ErrHeimdallResponseNotRecorded, request)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	next := min(h.served[request], len(responses)-1)
+	h.served[request] = next + 1
+
+	return json.Unmarshal(responses[next], result)
+}
+
+func (h *HeimdallReplayClient) StateSyncEvents(_ context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
+	var events []*clerk.EventRecordWithTime
+	if err := h.replay(fmt.Sprintf(recordStateSyncEventsFormat, fromID, to), &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (h *HeimdallReplayClient) Span(_ context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	res := new(span.HeimdallSpan)
+	if err := h.replay(fmt.Sprintf(recordSpanFormat, spanID), res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (h *HeimdallReplayClient) LatestSpan(_ context.Context) (*span.HeimdallSpan, error) {
+	res := new(span.HeimdallSpan)
+	if err := h.replay(recordLatestSpan, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (h *HeimdallReplayClient) FetchCheckpoint(_ context.Context, number int64) (*checkpoint.Checkpoint, error) {
+	res := new(checkpoint.Checkpoint)
+	if err := h.replay(fmt.Sprintf(recordCheckpointFormat, number), res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (h *HeimdallReplayClient) FetchCheckpointCount(_ context.Context) (int64, error) {
+	var count int64
+	err := h.replay(recordCheckpointCount, &count)
+
+	return count, err
+}
+
+func (h *HeimdallReplayClient) FetchMilestone(_ context.Context) (*milestone.Milestone, error) {
+	res := new(milestone.Milestone)
+	if err := h.replay(recordMilestone, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (h *HeimdallReplayClient) FetchMilestoneCount(_ context.Context) (int64, error) {
+	var count int64
+	err := h.replay(recordMilestoneCount, &count)
+
+	return count, err
+}
+
+func (h *HeimdallReplayClient) FetchNoAckMilestone(_ context.Context, milestoneID string) error {
+	var res any
+	if err := h.replay(fmt.Sprintf(recordNoAckMilestoneFormat, milestoneID), &res); errors.Is(err, ErrHeimdallResponseNotRecorded) {
+		return fmt.Errorf("%w: milestoneID %q", heimdall.ErrNotInRejectedList, milestoneID)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *HeimdallReplayClient) FetchLastNoAckMilestone(_ context.Context) (string, error) {
+	var milestoneID string
+	err := h.replay(recordLastNoAckMilestone, &milestoneID)
+
+	return milestoneID, err
+}
+
+func (h *HeimdallReplayClient) FetchMilestoneID(_ context.Context, milestoneID string) error {
+	var res any
+	if err := h.replay(fmt.Sprintf(recordMilestoneIDFormat, milestoneID), &res); errors.Is(err, ErrHeimdallResponseNotRecorded) {
+		return fmt.Errorf("%w: milestoneID %q", heimdall.ErrNotInMilestoneList, milestoneID)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close is a no-op, the replay client holds no connection.
+func (h *HeimdallReplayClient) Close() {}
@@ -0,0 +1,195 @@
+package bor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/checkpoint"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// heimdallSwapDrainTimeout is the time the calls in flight on a swapped out client
+// are given to finish before it's closed anyway, which aborts their retries.
+const heimdallSwapDrainTimeout = 30 * time.Second
+
+// swappedClient is a client of HeimdallSwappableClient along with the calls in
+// flight on it.
+type swappedClient struct {
+	client IHeimdallClient
+	calls  sync.RWMutex // Held for reading by the calls in flight, for writing to close the client
+	closed bool         // Whether the client was closed after being swapped out
+}
+
+// HeimdallSwappableClient routes the calls to a heimdall client which can be
+// swapped at runtime. A swapped out client is closed once the calls in flight on
+// it are done, or after a timeout as a call may keep retrying against an
+// unreachable heimdall until the client is closed. The calls made meanwhile go to
+// the new client.
+type HeimdallSwappableClient struct {
+	current      atomic.Pointer[swappedClient]
+	drainTimeout time.Duration // Time given to the calls in flight on a swapped out client before closing it
+}
+
+// NewHeimdallSwappableClient wraps the given client so that it can be swapped.
+func NewHeimdallSwappableClient(client IHeimdallClient) *HeimdallSwappableClient {
+	h := &HeimdallSwappableClient{drainTimeout: heimdallSwapDrainTimeout}
+	h.current.Store(&swappedClient{client: client})
+
+	return h
+}
+
+// Current returns the client the calls are currently routed to.
+func (h *HeimdallSwappableClient) Current() IHeimdallClient {
+	return h.current.Load().client
+}
+
+// Swap routes the calls to the given client and returns the previous one, which
+// is closed in the background once the calls in flight on it are done, or once
+// the drain timeout elapsed.
+func (h *HeimdallSwappableClient) Swap(client IHeimdallClient) IHeimdallClient {
+	old := h.current.Swap(&swappedClient{client: client})
+
+	go func() {
+		drained := make(chan struct{})
+
+		go func() {
+			old.calls.Lock()
+			old.closed = true
+			old.calls.Unlock()
+
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-time.After(h.drainTimeout):
+			log.Warn("Closing the swapped out heimdall client with calls still in flight", "timeout", h.drainTimeout)
+		}
+
+		old.client.Close()
+	}()
+
+	return old.client
+}
+
+// acquire returns the current client, which isn't closed until release is called.
+func (h *HeimdallSwappableClient) acquire() *swappedClient {
+	for {
+		current := h.current.Load()
+
+		current.calls.RLock()
+		if !current.closed {
+			return current
+		}
+		current.calls.RUnlock()
+	}
+}
+
+func (c *swappedClient) release() {
+	c.calls.RUnlock()
+}
+
+func (h *HeimdallSwappableClient) StateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*clerk.EventRecordWithTime, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.StateSyncEvents(ctx, fromID, to)
+}
+
+func (h *HeimdallSwappableClient) Span(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.Span(ctx, spanID)
+}
+
+func (h *HeimdallSwappableClient) FetchCheckpoint(ctx context.Context, number int64) (*checkpoint.Checkpoint, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchCheckpoint(ctx, number)
+}
+
+func (h *HeimdallSwappableClient) FetchCheckpointCount(ctx context.Context) (int64, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchCheckpointCount(ctx)
+}
+
+func (h *HeimdallSwappableClient) FetchMilestone(ctx context.Context) (*milestone.Milestone, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchMilestone(ctx)
+}
+
+func (h *HeimdallSwappableClient) FetchMilestoneCount(ctx context.Context) (int64, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchMilestoneCount(ctx)
+}
+
+func (h *HeimdallSwappableClient) FetchNoAckMilestone(ctx context.Context, milestoneID string) error {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchNoAckMilestone(ctx, milestoneID)
+}
+
+func (h *HeimdallSwappableClient) FetchLastNoAckMilestone(ctx context.Context) (string, error) {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchLastNoAckMilestone(ctx)
+}
+
+func (h *HeimdallSwappableClient) FetchMilestoneID(ctx context.Context, milestoneID string) error {
+	c := h.acquire()
+	defer c.release()
+
+	return c.client.FetchMilestoneID(ctx, milestoneID)
+}
+
+// LatestSpan fetches the latest span through the current client, if it supports it.
+func (h *HeimdallSwappableClient) LatestSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	c := h.acquire()
+	defer c.release()
+
+	fetcher, ok := c.client.(latestSpanFetcher)
+	if !ok {
+		return nil, errLatestSpanNotSupported
+	}
+
+	return fetcher.LatestSpan(ctx)
+}
+
+// Close closes the current client.
+func (h *HeimdallSwappableClient) Close() {
+	c := h.acquire()
+	defer c.release()
+
+	c.client.Close()
+}
+
+// SwapHeimdallClient routes the heimdall calls of the engine to the given client
+// and returns the previous one, closed once the calls in flight on it are done.
+// The client swapped is the one set through WithSwappableHeimdallClient, so that
+// the new one is wrapped as the previous one, or else the engine's own client.
+func (c *Bor) SwapHeimdallClient(client IHeimdallClient) (IHeimdallClient, error) {
+	swappable := c.swappableHeimdall
+	if swappable == nil {
+		var ok bool
+		if swappable, ok = c.HeimdallClient.(*HeimdallSwappableClient); !ok {
+			return nil, errHeimdallClientNotSwappable
+		}
+	}
+
+	return swappable.Swap(client), nil
+}
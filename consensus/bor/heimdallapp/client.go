@@ -30,6 +30,12 @@ func (h *HeimdallAppClient) Close() {
 	log.Warn("Shutdown detected, Closing Heimdall App conn")
 }
 
+// ClientType returns the type of the client, the data is read from the heimdall
+// app running as a child process.
+func (h *HeimdallAppClient) ClientType() (string, string) {
+	return "heimdallapp", "childprocess"
+}
+
 func (h *HeimdallAppClient) NewContext() types.Context {
 	return h.hApp.NewContext(true, abci.Header{Height: h.hApp.LastBlockHeight()})
 }
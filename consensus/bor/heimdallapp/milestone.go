@@ -72,11 +72,12 @@ func (h *HeimdallAppClient) FetchMilestoneID(_ context.Context, milestoneID stri
 
 func toBorMilestone(hdMilestone *hmTypes.Milestone) *milestone.Milestone {
 	return &milestone.Milestone{
-		Proposer:   hdMilestone.Proposer.EthAddress(),
-		StartBlock: big.NewInt(int64(hdMilestone.StartBlock)),
-		EndBlock:   big.NewInt(int64(hdMilestone.EndBlock)),
-		Hash:       hdMilestone.Hash.EthHash(),
-		BorChainID: hdMilestone.BorChainID,
-		Timestamp:  hdMilestone.TimeStamp,
+		Proposer:    hdMilestone.Proposer.EthAddress(),
+		StartBlock:  big.NewInt(int64(hdMilestone.StartBlock)),
+		EndBlock:    big.NewInt(int64(hdMilestone.EndBlock)),
+		Hash:        hdMilestone.Hash.EthHash(),
+		BorChainID:  hdMilestone.BorChainID,
+		MilestoneID: hdMilestone.MilestoneID,
+		Timestamp:   hdMilestone.TimeStamp,
 	}
 }
@@ -50,3 +50,8 @@ func (h *HeimdallGRPCClient) Close() {
 	log.Debug("Shutdown detected, Closing Heimdall gRPC client")
 	h.conn.Close()
 }
+
+// ClientType returns the type of the client and the address of the heimdall gRPC server.
+func (h *HeimdallGRPCClient) ClientType() (string, string) {
+	return "grpc", h.conn.Target()
+}
@@ -0,0 +1,84 @@
+package bor
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// Metrics for the number of heimdall calls in flight and waiting for a free slot
+	heimdallInFlightGauge = metrics.NewRegisteredGauge("bor/heimdall/inflight", nil)
+	heimdallQueuedGauge   = metrics.NewRegisteredGauge("bor/heimdall/queued", nil)
+
+	// Metric for the distance between the head and the end of the current span, measured before sealing
+	spanStalenessGauge = metrics.NewRegisteredGauge("bor/span/staleness", nil)
+
+	// Metric for counting the spans from heimdall which aren't a whole number of sprints long
+	misalignedSpanCounter = metrics.NewRegisteredCounter("bor/span/misaligned", nil)
+
+	// Metric for counting the spans from heimdall refused for having too few validators
+	undersizedSpanCounter = metrics.NewRegisteredCounter("bor/span/undersized", nil)
+
+	// Metric for counting the spans from heimdall refused for having too many validators
+	oversizedSpanCounter = metrics.NewRegisteredCounter("bor/span/oversized", nil)
+
+	// Metric for the time spent rebuilding a snapshot from the last one stored on disk
+	snapshotRebuildTimer = metrics.NewRegisteredTimer("bor/snapshot/rebuild", nil)
+
+	// Metric for counting the snapshots which couldn't be reconstructed within the maximum walk-back
+	snapshotWalkbackExceededCounter = metrics.NewRegisteredCounter("bor/snapshot/walkbackexceeded", nil)
+
+	// Metric for counting the state-sync events deferred to a later sprint
+	stateSyncDeferredCounter = metrics.NewRegisteredCounter("bor/statesync/deferred", nil)
+
+	// Metric for counting the state-sync events refused for a payload over the limit
+	stateSyncOversizedCounter = metrics.NewRegisteredCounter("bor/statesync/oversized", nil)
+
+	// Metrics for counting the state-sync events committed in parallel, and the sprints
+	// falling back to the sequential commit on a conflict
+	stateSyncParallelCounter = metrics.NewRegisteredCounter("bor/statesync/parallel", nil)
+	stateSyncConflictCounter = metrics.NewRegisteredCounter("bor/statesync/parallel/conflicts", nil)
+
+	// Metric for whether the state-sync (and the sealing) is paused by an operator
+	stateSyncPausedGauge = metrics.NewRegisteredGauge("bor/statesync/paused", nil)
+
+	// Metric for counting the headers deferred for a timestamp ahead of the local clock beyond the tolerance
+	futureBlockDeferredCounter = metrics.NewRegisteredCounter("bor/futureblock/deferred", nil)
+
+	// Metric for counting the headers rejected for being sealed faster than the period or the producer delay
+	periodViolationCounter = metrics.NewRegisteredCounter("bor/period/violations", nil)
+
+	// Metric for counting the headers rejected by the strict difficulty validation
+	wrongDifficultyCounter = metrics.NewRegisteredCounter("bor/difficulty/wrong", nil)
+
+	// Metrics for counting the headers rejected by the strict extra-data validation, by offending field
+	extraDataInvalidCounters = map[string]metrics.Counter{
+		extraFieldVanity:         metrics.NewRegisteredCounter("bor/extradata/invalid/vanity", nil),
+		extraFieldSignature:      metrics.NewRegisteredCounter("bor/extradata/invalid/signature", nil),
+		extraFieldBlockExtraData: metrics.NewRegisteredCounter("bor/extradata/invalid/blockextradata", nil),
+		extraFieldValidators:     metrics.NewRegisteredCounter("bor/extradata/invalid/validators", nil),
+	}
+
+	// Metrics for the breakdown of the time spent processing a block, for the sprint
+	// start blocks and the other blocks
+	sprintBlockTimers  = newBlockTimers("sprint")
+	regularBlockTimers = newBlockTimers("regular")
+)
+
+// blockTimers break down the time spent processing a block: the transaction
+// execution (for the blocks built locally only), the span commit, the state-sync
+// and the seal, not counting the wait for the block's slot.
+type blockTimers struct {
+	txs        metrics.Timer
+	spanCommit metrics.Timer
+	stateSync  metrics.Timer
+	seal       metrics.Timer
+}
+
+func newBlockTimers(kind string) *blockTimers {
+	return &blockTimers{
+		txs:        metrics.NewRegisteredTimer("bor/block/"+kind+"/txs", nil),
+		spanCommit: metrics.NewRegisteredTimer("bor/block/"+kind+"/spancommit", nil),
+		stateSync:  metrics.NewRegisteredTimer("bor/block/"+kind+"/statesync", nil),
+		seal:       metrics.NewRegisteredTimer("bor/block/"+kind+"/seal", nil),
+	}
+}
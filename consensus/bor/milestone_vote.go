@@ -0,0 +1,37 @@
+package bor
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxMilestoneVoteHistory is the number of milestone votes kept in the vote
+// history, the oldest ones being dropped.
+const maxMilestoneVoteHistory = 1024
+
+// RecordMilestoneVote appends the vote cast by the node on the milestone of the
+// given id to the persisted vote history, letting the operator audit the votes of
+// the node through GetMilestoneVoteHistory.
+func (c *Bor) RecordMilestoneVote(milestoneID string, start uint64, end uint64, rootHash string, voteYes bool) {
+	if c.db == nil {
+		return
+	}
+
+	c.milestoneVoteLock.Lock()
+	defer c.milestoneVoteLock.Unlock()
+
+	entry := &rawdb.MilestoneVoteEntry{
+		MilestoneID: milestoneID,
+		StartBlock:  start,
+		EndBlock:    end,
+		RootHash:    rootHash,
+		VoteYes:     voteYes,
+		Timestamp:   uint64(time.Now().Unix()),
+	}
+
+	if err := rawdb.WriteMilestoneVote(c.db, entry, maxMilestoneVoteHistory); err != nil {
+		log.Warn("Failed to record the milestone vote", "milestoneID", milestoneID, "err", err)
+	}
+}
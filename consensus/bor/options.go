@@ -0,0 +1,158 @@
+package bor
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Option is a functional option which tweaks the behaviour of the bor
+// consensus engine. Options are applied by New after the defaults are set.
+type Option func(c *Bor)
+
+// WithStrictExtraDataValidation enables (or disables) the strict validation
+// of the header's extra-data layout (vanity, validator bytes and seal) which
+// is performed early in VerifyHeader.
+func WithStrictExtraDataValidation(strict bool) Option {
+	return func(c *Bor) {
+		c.strictExtraData = strict
+	}
+}
+
+// WithStrictDifficultyValidation enables (or disables) the check of the difficulty
+// against the turn of the header's signer right after loading the snapshot, before
+// the more expensive checks of the validator list. Without it, the same check is
+// only done last, when verifying the seal.
+func WithStrictDifficultyValidation(strict bool) Option {
+	return func(c *Bor) {
+		c.strictDifficulty = strict
+	}
+}
+
+// WithFutureBlockTolerance sets the number of seconds a header's timestamp may be
+// ahead of the local clock before it's deferred as a future block, to absorb the
+// clock skew of the validators. It must not exceed the block period, see
+// ValidateFutureBlockTolerance.
+func WithFutureBlockTolerance(seconds uint64) Option {
+	return func(c *Bor) {
+		c.futureBlockTolerance = seconds
+	}
+}
+
+// WithMaxSnapshotWalkback sets the most headers walked back to reconstruct a
+// snapshot before giving up, as the checkpoint snapshot it starts from is missing.
+// 0 walks back unbounded.
+func WithMaxSnapshotWalkback(headers uint64) Option {
+	return func(c *Bor) {
+		c.maxSnapshotWalkback = headers
+	}
+}
+
+// WithSpanProvider overrides the source of the spans committed by the engine,
+// independently of the heimdall client used for milestones and state-syncs.
+func WithSpanProvider(provider SpanProvider) Option {
+	return func(c *Bor) {
+		c.spanProvider = provider
+	}
+}
+
+// WithSnapshotCheckpointInterval sets the number of blocks after which a snapshot
+// is stored to the database. A lower interval makes the snapshots faster to rebuild
+// on startup at the cost of more disk writes, 0 keeps the default.
+func WithSnapshotCheckpointInterval(interval uint64) Option {
+	return func(c *Bor) {
+		c.snapshotCheckpointInterval = interval
+	}
+}
+
+// WithMaxSpanStaleness pauses sealing once the head is within the given number of
+// blocks of the end of the current span, until the next span is fetched from
+// heimdall. 0 disables the check.
+func WithMaxSpanStaleness(blocks uint64) Option {
+	return func(c *Bor) {
+		c.maxSpanStaleness = blocks
+	}
+}
+
+// WithRecentsLimitPercent bounds the recents of the snapshots to the given
+// percentage of the validator set size, plus one. 0 selects the protocol default
+// of half the validator set.
+func WithRecentsLimitPercent(percent uint64) Option {
+	return func(c *Bor) {
+		c.recentsLimitPercent = percent
+	}
+}
+
+// WithVerifySpanCommit sets whether the span committed when building a span
+// boundary block is checked against the one reported by heimdall, refusing to
+// seal the block on a mismatch.
+func WithVerifySpanCommit(verify bool) Option {
+	return func(c *Bor) {
+		c.verifySpanCommit = verify
+	}
+}
+
+// WithVerifyConcurrency bounds the number of headers verified concurrently in a
+// batch, 0 selects the number of CPUs.
+func WithVerifyConcurrency(workers int) Option {
+	return func(c *Bor) {
+		c.verifyConcurrency = workers
+	}
+}
+
+// WithSealStopBeforeSpanChange stops sealing the given number of blocks before the
+// end of the span if the signer isn't a producer of the next span. 0 disables it.
+func WithSealStopBeforeSpanChange(blocks uint64) Option {
+	return func(c *Bor) {
+		c.sealStopBeforeSpanChange = blocks
+	}
+}
+
+// WithParallelStateSync executes the state-sync events of a sprint speculatively on
+// up to the given number of workers, one per state receiver contract, the events of
+// a receiver depending on each other through its last state id. The events are
+// committed if the receivers are independent and sequentially otherwise. 0 disables
+// it.
+func WithParallelStateSync(workers int) Option {
+	return func(c *Bor) {
+		c.parallelStateSync = workers
+	}
+}
+
+// WithGenesisSpanSource sets the source of the validator set of the genesis span,
+// read from the genesis contract by default.
+func WithGenesisSpanSource(source GenesisSpanSource) Option {
+	return func(c *Bor) {
+		c.genesisSpanSource = source
+	}
+}
+
+// WithSwappableHeimdallClient sets the client swapped by SwapHeimdallClient when
+// it's wrapped by the engine's heimdall client, e.g. by the proxy, recording or
+// concurrency limiting clients, which then also wrap the swapped in clients.
+func WithSwappableHeimdallClient(client *HeimdallSwappableClient) Option {
+	return func(c *Bor) {
+		c.swappableHeimdall = client
+	}
+}
+
+// WithDevFakeAuthors sets the fake authors the proposer rotates through at every
+// sprint in DevFakeAuthor mode. It has no effect outside of that mode.
+func WithDevFakeAuthors(authors ...common.Address) Option {
+	return func(c *Bor) {
+		c.devFakeAuthors = append(c.devFakeAuthors, authors...)
+	}
+}
+
+// WithSystemTxProviders registers providers of extra system transactions, applied
+// at the start of every sprint in the given order.
+func WithSystemTxProviders(providers ...SystemTxProvider) Option {
+	return func(c *Bor) {
+		c.systemTxProviders = append(c.systemTxProviders, providers...)
+	}
+}
+
+// WithAllowOutOfTurn sets whether blocks can be sealed out-of-turn. If not, the
+// engine only seals when in-turn. It's local to the node, the out-of-turn blocks
+// of the other signers are still accepted.
+func WithAllowOutOfTurn(allow bool) Option {
+	return func(c *Bor) {
+		c.disallowOutOfTurn = !allow
+	}
+}
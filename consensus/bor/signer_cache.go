@@ -0,0 +1,133 @@
+package bor
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/bor/valset"
+)
+
+const (
+	// maxSignerCacheWarmupWorkers is the maximum number of concurrent signer
+	// recoveries of a signer cache warmup.
+	maxSignerCacheWarmupWorkers = 64
+
+	// defaultSignerCacheWarmupBatch is the default number of consecutive headers
+	// recovered by a worker at once.
+	defaultSignerCacheWarmupBatch = 64
+)
+
+// SignerCacheWarmup is the outcome of a signer cache warmup.
+type SignerCacheWarmup struct {
+	Start   uint64 `json:"start"`
+	End     uint64 `json:"end"`
+	Workers int    `json:"workers"`
+	Batch   int    `json:"batch"`
+	Warmed  uint64 `json:"warmed"`  // Number of signers recovered and cached
+	Cached  uint64 `json:"cached"`  // Number of signers already cached
+	Failed  uint64 `json:"failed"`  // Number of headers whose signer couldn't be recovered
+	Elapsed string `json:"elapsed"` // Time taken by the warmup
+}
+
+// WarmSignerCache recovers the signers of the headers in [start, end] into the
+// signer cache, so that the author lookups over the range don't pay for the
+// recovery. The range is limited to the capacity of the cache. Workers and batch
+// are the number of concurrent recoveries and of consecutive headers handed to a
+// worker at once, the defaults are used if not positive.
+func (c *Bor) WarmSignerCache(chain consensus.ChainHeaderReader, start uint64, end uint64, workers int, batch int) (*SignerCacheWarmup, error) {
+	current := chain.CurrentHeader().Number.Uint64()
+
+	if start > end || end > current {
+		return nil, &valset.InvalidStartEndBlockError{Start: start, End: end, CurrentHeader: current}
+	}
+
+	if end-start+1 > inmemorySignatures {
+		return nil, errors.New("range larger than the signer cache")
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	if workers > maxSignerCacheWarmupWorkers {
+		workers = maxSignerCacheWarmupWorkers
+	}
+
+	if batch <= 0 {
+		batch = defaultSignerCacheWarmupBatch
+	}
+
+	var (
+		began   = time.Now()
+		batches = make(chan uint64)
+		wg      sync.WaitGroup
+
+		warmed, cached, failed atomic.Uint64
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for from := range batches {
+				to := end
+				if end-from >= uint64(batch) {
+					to = from + uint64(batch) - 1
+				}
+
+				for number := from; number <= to; number++ {
+					header := chain.GetHeaderByNumber(number)
+
+					switch {
+					case header == nil:
+						failed.Add(1)
+					case c.signatures.Contains(header.Hash()):
+						cached.Add(1)
+					default:
+						if _, err := ecrecover(header, c.signatures, c.config); err != nil {
+							failed.Add(1)
+						} else {
+							warmed.Add(1)
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	// The genesis header isn't signed
+	from := start
+	if from == 0 {
+		from = 1
+	}
+
+	for from <= end {
+		batches <- from
+
+		if end-from < uint64(batch) {
+			break
+		}
+
+		from += uint64(batch)
+	}
+
+	close(batches)
+	wg.Wait()
+
+	return &SignerCacheWarmup{
+		Start:   start,
+		End:     end,
+		Workers: workers,
+		Batch:   batch,
+		Warmed:  warmed.Load(),
+		Cached:  cached.Load(),
+		Failed:  failed.Load(),
+		Elapsed: time.Since(began).String(),
+	}, nil
+}
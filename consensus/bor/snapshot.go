@@ -2,6 +2,7 @@ package bor
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/consensus/bor/valset"
 
@@ -99,7 +100,32 @@ func (s *Snapshot) copy() *Snapshot {
 	return cpy
 }
 
-func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+// defaultRecentsLimitPercent bounds the recents to half the validator set plus
+// one, the protocol default.
+const defaultRecentsLimitPercent = 50
+
+// ValidateRecentsLimitPercent checks that the recents limit is a percentage of the
+// validator set, 0 selecting the default.
+func ValidateRecentsLimitPercent(percent uint64) error {
+	if percent > 100 {
+		return fmt.Errorf("recents limit %d%% exceeds the validator set", percent)
+	}
+
+	return nil
+}
+
+// recentsLimit returns the maximum number of recent signers kept for a validator
+// set of the given size: the given percentage of the validators, plus one. 0
+// selects the default percentage, see ValidateRecentsLimitPercent for the others.
+func recentsLimit(validators int, percent uint64) int {
+	if percent == 0 {
+		percent = defaultRecentsLimitPercent
+	}
+
+	return validators*int(percent)/100 + 1
+}
+
+func (s *Snapshot) apply(headers []*types.Header, recentsLimitPercent uint64) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -144,6 +170,16 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 		// add recents
 		snap.Recents[number] = signer
 
+		// Keep the recents bounded by the size of the validator set, so that the
+		// snapshot copies stay cheap with large sprints
+		if limit := recentsLimit(len(snap.ValidatorSet.Validators), recentsLimitPercent); len(snap.Recents) > limit {
+			for recent := range snap.Recents {
+				if recent+uint64(limit) <= number {
+					delete(snap.Recents, recent)
+				}
+			}
+		}
+
 		// change validator set and change proposer
 		if number > 0 && (number+1)%s.config.CalculateSprint(number) == 0 {
 			if err := validateHeaderExtraField(header.Extra); err != nil {
@@ -192,6 +228,34 @@ func (s *Snapshot) GetSignerSuccessionNumber(signer common.Address) (int, error)
 	return tempIndex - proposerIndex, nil
 }
 
+// RecentsLockout returns the number of blocks until the given signer drops out of
+// the recents, i.e. until the next block is the one removing its latest entry.
+// It's 0 if the signer isn't in the recents.
+func (s *Snapshot) RecentsLockout(signer common.Address, recentsLimitPercent uint64) uint64 {
+	var (
+		next   = s.Number + 1
+		sprint = s.config.CalculateSprint(next)
+		latest uint64
+		found  bool
+	)
+
+	if limit := uint64(recentsLimit(len(s.ValidatorSet.Validators), recentsLimitPercent)); limit < sprint {
+		sprint = limit
+	}
+
+	for number, recent := range s.Recents {
+		if recent == signer && (!found || number > latest) {
+			latest, found = number, true
+		}
+	}
+
+	if !found || latest+sprint <= next {
+		return 0
+	}
+
+	return latest + sprint - next
+}
+
 // signers retrieves the list of authorized signers in ascending order.
 func (s *Snapshot) signers() []common.Address {
 	sigs := make([]common.Address, 0, len(s.ValidatorSet.Validators))
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"testing"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/maticnetwork/crand"
 	"github.com/stretchr/testify/require"
 	"pgregory.net/rapid"
@@ -12,6 +13,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	unique "github.com/ethereum/go-ethereum/common/set"
 	"github.com/ethereum/go-ethereum/consensus/bor/valset"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 const (
@@ -199,6 +202,69 @@ func randomAddresses(n int) []common.Address {
 	}
 }
 
+func TestRecentsLockout(t *testing.T) {
+	t.Parallel()
+
+	signer := common.HexToAddress("0x1")
+	snap := Snapshot{
+		config:       &params.BorConfig{Sprint: map[string]uint64{"0": 16}},
+		Number:       100,
+		ValidatorSet: valset.NewValidatorSet(buildRandomValidatorSet(numVals)),
+		Recents: map[uint64]common.Address{
+			90:  signer,
+			95:  common.HexToAddress("0x2"),
+			98:  signer,
+			100: common.HexToAddress("0x3"),
+		},
+	}
+
+	// the entry at 98 is removed when block 114 is applied
+	require.Equal(t, uint64(13), snap.RecentsLockout(signer, 0))
+	require.Equal(t, uint64(0), snap.RecentsLockout(common.HexToAddress("0x4"), 0))
+
+	snap.Number = 113
+	require.Equal(t, uint64(0), snap.RecentsLockout(signer, 0))
+}
+
+func TestRecentsLimit(t *testing.T) {
+	t.Parallel()
+
+	const validators = 10
+
+	sigcache, err := lru.NewARC(inmemorySignatures)
+	require.NoError(t, err)
+
+	vals := buildRandomValidatorSet(validators)
+	snap := newSnapshot(&params.BorConfig{Sprint: map[string]uint64{"0": 64}}, sigcache, 0, common.Hash{}, vals)
+
+	limit := validators/2 + 1
+
+	// Stay within the first sprint, the recents would hold every block otherwise
+	for number := uint64(1); number < 60; number++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: snap.Hash}
+		sigcache.Add(header.Hash(), vals[number%validators].Address)
+
+		snap, err = snap.apply([]*types.Header{header}, 0)
+		require.NoError(t, err)
+
+		require.LessOrEqual(t, len(snap.Recents), limit, "block %d", number)
+		require.Equal(t, vals[number%validators].Address, snap.Recents[number])
+	}
+
+	require.Len(t, snap.Recents, limit)
+}
+
+func TestValidateRecentsLimitPercent(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateRecentsLimitPercent(0))
+	require.NoError(t, ValidateRecentsLimitPercent(100))
+	require.Error(t, ValidateRecentsLimitPercent(101))
+
+	require.Equal(t, recentsLimit(10, defaultRecentsLimitPercent), recentsLimit(10, 0))
+	require.Equal(t, 11, recentsLimit(10, 100))
+}
+
 func TestRandomAddresses(t *testing.T) {
 	t.Parallel()
 
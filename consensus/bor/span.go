@@ -2,16 +2,28 @@ package bor
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
 	"github.com/ethereum/go-ethereum/consensus/bor/valset"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// validatorSetCallRetries is the number of ancestor states the validator set
+// is read from if the read at the requested state fails.
+const validatorSetCallRetries = 3
+
+// nextSpanFetchTimeout bounds the fetch of the next span done before sealing
+// close to the end of the current span.
+const nextSpanFetchTimeout = 5 * time.Second
+
 //go:generate mockgen -destination=./span_mock.go -package=bor . Spanner
 type Spanner interface {
 	GetCurrentSpan(ctx context.Context, headerHash common.Hash) (*span.Span, error)
@@ -19,3 +31,195 @@ type Spanner interface {
 	GetCurrentValidatorsByBlockNrOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, blockNumber uint64) ([]*valset.Validator, error)
 	CommitSpan(ctx context.Context, heimdallSpan span.HeimdallSpan, state *state.StateDB, header *types.Header, chainContext core.ChainContext) error
 }
+
+// getValidatorsWithRetry reads the validator set for blockNumber from the state of
+// header. If the read fails, it's retried against the states of a few ancestors.
+// Only ancestors which already contain the span of blockNumber are used, i.e. the
+// ones not older than a sprint before blockNumber (the span is committed in the
+// first block of the last sprint of the previous span).
+func (c *Bor) getValidatorsWithRetry(ctx context.Context, chain consensus.ChainHeaderReader, header *types.Header, blockNumber uint64) ([]*valset.Validator, error) {
+	validators, err := c.spanner.GetCurrentValidatorsByHash(ctx, header.Hash(), blockNumber)
+	if err == nil {
+		return validators, nil
+	}
+
+	var (
+		attempts = 1
+		oldest   uint64
+	)
+
+	if sprint := c.config.CalculateSprint(blockNumber); blockNumber > sprint {
+		oldest = blockNumber - sprint
+	}
+
+	for ancestor := header; attempts <= validatorSetCallRetries && ancestor.Number.Uint64() > oldest; attempts++ {
+		ancestor = chain.GetHeader(ancestor.ParentHash, ancestor.Number.Uint64()-1)
+		if ancestor == nil {
+			break
+		}
+
+		log.Warn("Failed to read validator set, retrying against older state", "number", blockNumber, "state", ancestor.Number.Uint64(), "err", err)
+
+		validators, err = c.spanner.GetCurrentValidatorsByHash(ctx, ancestor.Hash(), blockNumber)
+		if err == nil {
+			return validators, nil
+		}
+	}
+
+	return nil, &ValidatorSetUnavailableError{
+		Number:   blockNumber,
+		Attempts: attempts,
+		Err:      err,
+	}
+}
+
+// checkSpanStaleness makes sure the span following the current one can be fetched
+// from heimdall once the head gets within maxSpanStaleness blocks of the end of the
+// current span, so that a validator cut off from heimdall doesn't keep sealing
+// with a stale validator set up to the span boundary.
+func (c *Bor) checkSpanStaleness(ctx context.Context, header *types.Header) error {
+	if c.maxSpanStaleness == 0 {
+		return nil
+	}
+
+	currentSpan, err := c.spanner.GetCurrentSpan(ctx, header.ParentHash)
+	if err != nil {
+		return err
+	}
+
+	head := header.Number.Uint64() - 1
+	spanStalenessGauge.Update(int64(head) - int64(currentSpan.EndBlock))
+
+	if currentSpan.EndBlock >= head+c.maxSpanStaleness || c.latestFetchedSpanID.Load() > currentSpan.ID {
+		return nil
+	}
+
+	spanProvider := c.getSpanProvider()
+	if spanProvider == nil {
+		return nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, nextSpanFetchTimeout)
+	defer cancel()
+
+	if _, err := spanProvider.GetSpan(fetchCtx, currentSpan.ID+1); err != nil {
+		log.Warn("Sealing paused, the next span couldn't be fetched", "number", header.Number.Uint64(), "span", currentSpan.ID, "spanEnd", currentSpan.EndBlock, "err", err)
+
+		return &StaleSpanError{
+			Number:  header.Number.Uint64(),
+			SpanID:  currentSpan.ID,
+			SpanEnd: currentSpan.EndBlock,
+			Err:     err,
+		}
+	}
+
+	c.recordFetchedSpan(currentSpan.ID + 1)
+
+	return nil
+}
+
+// checkSealStopBeforeSpanChange stops sealing the last sealStopBeforeSpanChange
+// blocks of the current span if the local signer isn't a producer of the next
+// span, so that the node doesn't seal a block the incoming validator set might
+// consider out-of-turn during the handoff.
+func (c *Bor) checkSealStopBeforeSpanChange(ctx context.Context, header *types.Header, signer common.Address) error {
+	if c.sealStopBeforeSpanChange == 0 {
+		return nil
+	}
+
+	currentSpan, err := c.spanner.GetCurrentSpan(ctx, header.ParentHash)
+	if err != nil {
+		return err
+	}
+
+	number := header.Number.Uint64()
+	if number > currentSpan.EndBlock || number+c.sealStopBeforeSpanChange <= currentSpan.EndBlock {
+		return nil
+	}
+
+	spanProvider := c.getSpanProvider()
+	if spanProvider == nil {
+		return nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, nextSpanFetchTimeout)
+	defer cancel()
+
+	nextSpan, err := spanProvider.GetSpan(fetchCtx, currentSpan.ID+1)
+	if err != nil {
+		// Not knowing the next span isn't a reason to stop early, the staleness
+		// check takes care of it
+		log.Debug("Failed to fetch the next span for the seal stop", "number", number, "span", currentSpan.ID+1, "err", err)
+		return nil
+	}
+
+	for _, producer := range nextSpan.SelectedProducers {
+		if producer.Address == signer {
+			return nil
+		}
+	}
+
+	log.Info("Sealing stopped, the signer isn't a producer of the next span", "number", number, "span", nextSpan.ID, "spanStart", nextSpan.StartBlock)
+
+	return &SealStopBeforeSpanChangeError{
+		Number:    number,
+		SpanID:    nextSpan.ID,
+		SpanStart: nextSpan.StartBlock,
+	}
+}
+
+// recordFetchedSpan keeps track of the newest span fetched from heimdall.
+func (c *Bor) recordFetchedSpan(spanID uint64) {
+	for {
+		latest := c.latestFetchedSpanID.Load()
+		if latest >= spanID || c.latestFetchedSpanID.CompareAndSwap(latest, spanID) {
+			return
+		}
+	}
+}
+
+// verifyCommittedSpan fetches the committed span again straight from heimdall,
+// bypassing any span provider override, and checks that both agree.
+func (c *Bor) verifyCommittedSpan(ctx context.Context, header *types.Header, committed *span.HeimdallSpan) error {
+	if c.HeimdallClient == nil {
+		return nil
+	}
+
+	expected, err := c.HeimdallClient.Span(ctx, committed.ID)
+	if err != nil {
+		return err
+	}
+
+	mismatch := func(field string) error {
+		return &SpanCommitMismatchError{Number: header.Number.Uint64(), SpanID: committed.ID, Field: field}
+	}
+
+	switch {
+	case expected.StartBlock != committed.StartBlock || expected.EndBlock != committed.EndBlock:
+		return mismatch("block range")
+	case !reflect.DeepEqual(minimalValidators(expected.ValidatorSet.Validators), minimalValidators(committed.ValidatorSet.Validators)):
+		return mismatch("validator set")
+	case !reflect.DeepEqual(minimalProducers(expected.SelectedProducers), minimalProducers(committed.SelectedProducers)):
+		return mismatch("selected producers")
+	}
+
+	return nil
+}
+
+func minimalValidators(validators []*valset.Validator) []valset.MinimalVal {
+	vals := make([]valset.MinimalVal, 0, len(validators))
+	for _, val := range validators {
+		vals = append(vals, val.MinimalVal())
+	}
+
+	return vals
+}
+
+func minimalProducers(producers []valset.Validator) []valset.MinimalVal {
+	vals := make([]valset.MinimalVal, 0, len(producers))
+	for _, val := range producers {
+		vals = append(vals, val.MinimalVal())
+	}
+
+	return vals
+}
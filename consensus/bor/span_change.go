@@ -0,0 +1,148 @@
+package bor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/consensus/bor/valset"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errNoSpanChange is returned by GetLastSpanChange until the node imported a block
+// committing a span since it started.
+var errNoSpanChange = errors.New("no span change observed yet")
+
+// SpanValidatorChange is a validator added to, removed from, or whose voting power
+// changed in the validator set by a span change.
+type SpanValidatorChange struct {
+	Address  common.Address `json:"address"`
+	OldPower int64          `json:"oldPower"` // 0 if added
+	NewPower int64          `json:"newPower"` // 0 if removed
+}
+
+// SpanChange describes the validator set changes of a span transition.
+type SpanChange struct {
+	OldSpanID    uint64                `json:"oldSpanID"`
+	NewSpanID    uint64                `json:"newSpanID"`
+	Block        uint64                `json:"block"`       // First block of the new span, where the change takes effect
+	CommittedAt  uint64                `json:"committedAt"` // Block which committed the new span
+	Added        []SpanValidatorChange `json:"added"`
+	Removed      []SpanValidatorChange `json:"removed"`
+	PowerChanged []SpanValidatorChange `json:"powerChanged"`
+}
+
+// diffSpans returns the changes of the validator set from the validators of the
+// old span to the new span, ordered by address.
+func diffSpans(oldID uint64, old []*valset.Validator, new *span.HeimdallSpan, committedAt uint64) *SpanChange {
+	change := &SpanChange{
+		OldSpanID:    oldID,
+		NewSpanID:    new.ID,
+		Block:        new.StartBlock,
+		CommittedAt:  committedAt,
+		Added:        []SpanValidatorChange{},
+		Removed:      []SpanValidatorChange{},
+		PowerChanged: []SpanValidatorChange{},
+	}
+
+	powers := func(validators []*valset.Validator) map[common.Address]int64 {
+		res := make(map[common.Address]int64, len(validators))
+		for _, v := range validators {
+			res[v.Address] = v.VotingPower
+		}
+
+		return res
+	}
+
+	oldPowers, newPowers := powers(old), powers(new.ValidatorSet.Validators)
+
+	for address, power := range newPowers {
+		oldPower, ok := oldPowers[address]
+
+		switch {
+		case !ok:
+			change.Added = append(change.Added, SpanValidatorChange{Address: address, NewPower: power})
+		case oldPower != power:
+			change.PowerChanged = append(change.PowerChanged, SpanValidatorChange{Address: address, OldPower: oldPower, NewPower: power})
+		}
+	}
+
+	for address, power := range oldPowers {
+		if _, ok := newPowers[address]; !ok {
+			change.Removed = append(change.Removed, SpanValidatorChange{Address: address, OldPower: power})
+		}
+	}
+
+	for _, changes := range [][]SpanValidatorChange{change.Added, change.Removed, change.PowerChanged} {
+		sort.Slice(changes, func(i, j int) bool {
+			return bytes.Compare(changes[i].Address[:], changes[j].Address[:]) < 0
+		})
+	}
+
+	return change
+}
+
+// spanCommit is a span committed by an imported block.
+type spanCommit struct {
+	parentHash common.Hash
+	number     uint64
+	span       *span.HeimdallSpan
+}
+
+// recordSpanCommit keeps the span committed in the imported header, the changes
+// of the validator set it makes being resolved against the contract only when
+// queried, off the import path. A span older than the kept one, e.g. of a
+// sidechain, is ignored.
+func (c *Bor) recordSpanCommit(header *types.Header, committed *span.HeimdallSpan) {
+	c.spanChangeLock.Lock()
+	defer c.spanChangeLock.Unlock()
+
+	if c.lastSpanCommit != nil && c.lastSpanCommit.span.ID > committed.ID {
+		return
+	}
+
+	c.lastSpanCommit = &spanCommit{parentHash: header.ParentHash, number: header.Number.Uint64(), span: committed}
+	c.lastSpanChange = nil
+
+	log.Info("Validator set changing with the span", "span", committed.ID, "block", committed.StartBlock,
+		"validators", len(committed.ValidatorSet.Validators))
+}
+
+// LastSpanChange returns the changes of the validator set made by the latest
+// span committed by an imported block, compared with the span and its validators
+// read from the contract at the parent block. It's nil if no span is known yet.
+func (c *Bor) LastSpanChange(ctx context.Context) (*SpanChange, error) {
+	c.spanChangeLock.Lock()
+	commit, change := c.lastSpanCommit, c.lastSpanChange
+	c.spanChangeLock.Unlock()
+
+	if commit == nil || change != nil {
+		return change, nil
+	}
+
+	previous, err := c.spanner.GetCurrentSpan(ctx, commit.parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the previous span: %w", err)
+	}
+
+	validators, err := c.spanner.GetCurrentValidatorsByHash(ctx, commit.parentHash, commit.number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the validators of the previous span: %w", err)
+	}
+
+	change = diffSpans(previous.ID, validators, commit.span, commit.number)
+
+	c.spanChangeLock.Lock()
+	defer c.spanChangeLock.Unlock()
+
+	if c.lastSpanCommit == commit {
+		c.lastSpanChange = change
+	}
+
+	return change, nil
+}
@@ -0,0 +1,63 @@
+package bor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+)
+
+// errLatestSpanNotSupported is returned if the heimdall client backing the default
+// span provider can't fetch the latest span.
+var errLatestSpanNotSupported = errors.New("heimdall client doesn't support fetching the latest span")
+
+// SpanProvider is the source of the heimdall spans committed by the engine. It's
+// decoupled from IHeimdallClient so that spans can be served by a different source
+// than the milestones and state-sync events.
+type SpanProvider interface {
+	GetSpan(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error)
+	GetCurrentSpan(ctx context.Context) (*span.HeimdallSpan, error)
+}
+
+// latestSpanFetcher is implemented by the heimdall clients able to fetch the latest span.
+type latestSpanFetcher interface {
+	LatestSpan(ctx context.Context) (*span.HeimdallSpan, error)
+}
+
+// heimdallSpanProvider is the default span provider, backed by the heimdall client.
+type heimdallSpanProvider struct {
+	client IHeimdallClient
+}
+
+// NewHeimdallSpanProvider returns a span provider which fetches the spans through
+// the given heimdall client.
+func NewHeimdallSpanProvider(client IHeimdallClient) SpanProvider {
+	return &heimdallSpanProvider{client: client}
+}
+
+func (p *heimdallSpanProvider) GetSpan(ctx context.Context, spanID uint64) (*span.HeimdallSpan, error) {
+	return p.client.Span(ctx, spanID)
+}
+
+func (p *heimdallSpanProvider) GetCurrentSpan(ctx context.Context) (*span.HeimdallSpan, error) {
+	fetcher, ok := p.client.(latestSpanFetcher)
+	if !ok {
+		return nil, errLatestSpanNotSupported
+	}
+
+	return fetcher.LatestSpan(ctx)
+}
+
+// getSpanProvider returns the span provider set through WithSpanProvider or, if
+// none was set, the one derived from the current heimdall client (nil without one).
+func (c *Bor) getSpanProvider() SpanProvider {
+	if c.spanProvider != nil {
+		return c.spanProvider
+	}
+
+	if c.HeimdallClient == nil {
+		return nil
+	}
+
+	return NewHeimdallSpanProvider(c.HeimdallClient)
+}
@@ -0,0 +1,127 @@
+package bor
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// stateSyncProgress is the bookkeeping of the state-sync events applied by the engine.
+type stateSyncProgress struct {
+	lastID    uint64 // ID of the last state-sync event applied
+	lastBlock uint64 // Number of the block the last state-sync event was applied in
+}
+
+// StateSyncStatus describes how far behind heimdall the applied state-sync events are.
+type StateSyncStatus struct {
+	LastAppliedID    uint64
+	LastAppliedBlock uint64
+	HeimdallLatestID uint64
+}
+
+// recordStateSyncProgress updates the state-sync bookkeeping after the events of
+// a block were applied.
+func (c *Bor) recordStateSyncProgress(number uint64, lastStateID uint64) {
+	c.stateSyncProgress.Store(&stateSyncProgress{
+		lastID:    lastStateID,
+		lastBlock: number,
+	})
+}
+
+// SetStateSyncProgress implements consensus.StateSyncEngine, restoring the
+// state-sync bookkeeping from the progress persisted by the previous run.
+func (c *Bor) SetStateSyncProgress(id uint64, number uint64) {
+	c.stateSyncProgress.CompareAndSwap(nil, &stateSyncProgress{
+		lastID:    id,
+		lastBlock: number,
+	})
+}
+
+// StateSyncReplay is the outcome of replaying the state-sync events of a block
+// range against an isolated state.
+type StateSyncReplay struct {
+	FromID    uint64                   `json:"fromID"`
+	LastID    uint64                   `json:"lastID"`
+	Applied   []uint64                 `json:"applied"`
+	Failed    []StateSyncReplayFailure `json:"failed"`
+	Receivers []common.Address         `json:"receivers"` // State receiver contracts the events were committed to
+}
+
+// StateSyncReplayFailure is a state-sync event whose execution failed during a replay.
+type StateSyncReplayFailure struct {
+	ID    uint64 `json:"id"`
+	Error string `json:"error"`
+}
+
+// ReplayStateSync fetches from heimdall the state-sync events due by the end block
+// which weren't applied yet at the start block, and applies them sequentially on
+// top of the given state. The state must be the one of the start block, it's
+// modified in place and never written to the chain. Execution errors don't stop
+// the replay, they're reported along with the failing event instead.
+func (c *Bor) ReplayStateSync(ctx context.Context, chain consensus.ChainHeaderReader, state *state.StateDB, start *types.Header, end *types.Header) (*StateSyncReplay, error) {
+	if c.HeimdallClient == nil {
+		return nil, errHeimdallClientUnavailable
+	}
+
+	lastStateIDBig, err := c.GenesisContractsClient.LastStateId(state.Copy(), start.Number.Uint64(), start.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	number := end.Number.Uint64()
+
+	var to time.Time
+	if c.config.IsIndore(end.Number) {
+		to = time.Unix(int64(end.Time-c.config.CalculateStateSyncDelay(number)), 0)
+	} else {
+		sprintStart := chain.GetHeaderByNumber(number - c.config.CalculateSprint(number))
+		if sprintStart == nil {
+			return nil, errUnknownBlock
+		}
+
+		to = time.Unix(int64(sprintStart.Time), 0)
+	}
+
+	replay := &StateSyncReplay{
+		FromID:    lastStateIDBig.Uint64() + 1,
+		LastID:    lastStateIDBig.Uint64(),
+		Applied:   []uint64{},
+		Failed:    []StateSyncReplayFailure{},
+		Receivers: []common.Address{},
+	}
+
+	touched := make(map[common.Address]bool)
+
+	eventRecords, err := c.HeimdallClient.StateSyncEvents(ctx, replay.FromID, to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	cx := statefull.ChainContext{Chain: chain, Bor: c}
+
+	for _, eventRecord := range eventRecords {
+		if eventRecord.ID <= replay.LastID {
+			continue
+		}
+
+		if _, err := c.GenesisContractsClient.CommitState(eventRecord, state, end, cx); err != nil {
+			replay.Failed = append(replay.Failed, StateSyncReplayFailure{ID: eventRecord.ID, Error: err.Error()})
+		} else {
+			replay.Applied = append(replay.Applied, eventRecord.ID)
+		}
+
+		if receiver := c.GenesisContractsClient.StateReceiverFor(number, eventRecord.Contract); !touched[receiver] {
+			touched[receiver] = true
+			replay.Receivers = append(replay.Receivers, receiver)
+		}
+
+		replay.LastID = eventRecord.ID
+	}
+
+	return replay, nil
+}
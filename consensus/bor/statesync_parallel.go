@@ -0,0 +1,247 @@
+package bor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor/clerk"
+	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/core/blockstm"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// speculativeStateSync is the state-sync events committed to a state receiver
+// contract, executed in order on their own copy of the state.
+type speculativeStateSync struct {
+	events  []int          // Indexes of the events, in order
+	state   *state.StateDB // Copy of the state the events are executed on
+	gasUsed []uint64       // Gas used by each event
+	logs    [][]*types.Log // Logs emitted by each event
+	err     error
+}
+
+// commitStateSyncEvents commits the given state-sync events to the state, in order,
+// and returns the gas they used. With parallel state-sync enabled, the events of
+// the different state receiver contracts are first executed speculatively in
+// parallel, falling back to the sequential commit if they aren't independent of
+// each other.
+func (c *Bor) commitStateSyncEvents(
+	events []*clerk.EventRecordWithTime,
+	statedb *state.StateDB,
+	header *types.Header,
+	chain statefull.ChainContext,
+) (uint64, error) {
+	// The events of a receiver depend on each other through its last state id, only
+	// the receivers are executed in parallel
+	if c.parallelStateSync > 0 && len(events) > 1 {
+		if groups := c.stateSyncEventsByReceiver(header.Number.Uint64(), events); len(groups) > 1 {
+			if gasUsed, ok := c.commitStateSyncEventsInParallel(events, groups, statedb, header, chain); ok {
+				stateSyncParallelCounter.Inc(int64(len(events)))
+				return gasUsed, nil
+			}
+
+			stateSyncConflictCounter.Inc(1)
+			log.Debug("Parallel state-sync conflict, committing the events sequentially", "number", header.Number, "events", len(events))
+		}
+	}
+
+	var totalGas uint64
+
+	for _, event := range events {
+		// we expect that this call MUST emit an event, otherwise we wouldn't make a receipt
+		// if the receiver address is not a contract then we'll skip the most of the execution and emitting an event as well
+		// https://github.com/maticnetwork/genesis-contracts/blob/master/contracts/StateReceiver.sol#L27
+		gasUsed, err := c.GenesisContractsClient.CommitState(event, statedb, header, chain)
+		if err != nil {
+			return 0, err
+		}
+
+		totalGas += gasUsed
+	}
+
+	return totalGas, nil
+}
+
+// stateSyncEventsByReceiver returns the indexes of the events, in order, grouped by
+// the state receiver contract they're committed to at the given block.
+func (c *Bor) stateSyncEventsByReceiver(number uint64, events []*clerk.EventRecordWithTime) [][]int {
+	var (
+		groups [][]int
+		index  = make(map[common.Address]int)
+	)
+
+	for i, event := range events {
+		receiver := c.GenesisContractsClient.StateReceiverFor(number, event.Contract)
+
+		group, ok := index[receiver]
+		if !ok {
+			group = len(groups)
+			index[receiver] = group
+			groups = append(groups, nil)
+		}
+
+		groups[group] = append(groups[group], i)
+	}
+
+	return groups
+}
+
+// commitStateSyncEventsInParallel executes the events of every state receiver on a
+// copy of the state, at most parallelStateSync receivers at a time, recording their
+// reads and writes. If no receiver touches the state written by another one, their
+// writes are applied to the state and the logs added in the order of the events,
+// which leads to the same state as the sequential commit. Otherwise the state is
+// left untouched and false is returned.
+//
+// Every commit is a call with no value from the system address, touching its balance
+// without changing it. These touches aren't a dependency between the receivers, as
+// long as the balance is indeed left untouched.
+//
+// Note that the gas reported for the events may differ from the sequential commit,
+// as the accesses warmed up by the events of the other receivers aren't shared.
+func (c *Bor) commitStateSyncEventsInParallel(
+	events []*clerk.EventRecordWithTime,
+	groups [][]int,
+	statedb *state.StateDB,
+	header *types.Header,
+	chain statefull.ChainContext,
+) (uint64, bool) {
+	results := make([]speculativeStateSync, len(groups))
+
+	var wg sync.WaitGroup
+
+	workers := make(chan struct{}, c.parallelStateSync)
+
+	// The speculative executions start from the logs of the state
+	logCount := uint(len(statedb.Logs()))
+
+	for i, group := range groups {
+		// The state isn't safe for concurrent use, the copies are made upfront
+		results[i].events = group
+		results[i].state = statedb.Copy()
+		results[i].state.SetMVHashmap(blockstm.MakeMVHashMap())
+
+		wg.Add(1)
+
+		workers <- struct{}{}
+
+		go func(result *speculativeStateSync) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			seen := logCount
+
+			for _, i := range result.events {
+				gasUsed, err := c.GenesisContractsClient.CommitState(events[i], result.state, header, chain)
+				if err != nil {
+					result.err = err
+					return
+				}
+
+				var logs []*types.Log
+
+				for _, l := range result.state.Logs() {
+					if l.Index >= seen {
+						logs = append(logs, l)
+					}
+				}
+
+				sort.Slice(logs, func(i, j int) bool { return logs[i].Index < logs[j].Index })
+
+				seen += uint(len(logs))
+
+				result.gasUsed = append(result.gasUsed, gasUsed)
+				result.logs = append(result.logs, logs)
+			}
+		}(&results[i])
+	}
+
+	wg.Wait()
+
+	// Check the independence of the receivers before touching the state
+	written := make(map[blockstm.Key]int)
+
+	for i, result := range results {
+		if result.err != nil {
+			return 0, false
+		}
+
+		if result.state.GetBalance(types.SystemAddress).Cmp(statedb.GetBalance(types.SystemAddress)) != 0 {
+			return 0, false
+		}
+
+		writes := result.state.MVFullWriteList()
+
+		// The reverted writes and the created accounts can't be replayed as is
+		if len(result.state.MVWriteList()) != len(writes) {
+			return 0, false
+		}
+
+		for _, write := range writes {
+			if isSystemCallerTouch(write.Path) {
+				continue
+			}
+
+			if _, ok := written[write.Path]; ok {
+				return 0, false
+			}
+
+			if write.Path.IsAddress() && !statedb.Exist(write.Path.GetAddress()) {
+				return 0, false
+			}
+
+			written[write.Path] = i
+		}
+	}
+
+	// The events of a receiver are interleaved with the others, no read can depend
+	// on the writes of another receiver, whichever comes first
+	for i, result := range results {
+		for _, read := range result.state.MVReadList() {
+			if isSystemCallerTouch(read.Path) {
+				continue
+			}
+
+			if writer, ok := written[read.Path]; ok && writer != i {
+				return 0, false
+			}
+		}
+	}
+
+	// Apply the writes, and the logs in the order of the events
+	var totalGas uint64
+
+	logs := make([][]*types.Log, len(events))
+
+	for _, result := range results {
+		statedb.ApplyMVWriteSet(result.state.MVWriteList())
+
+		for j, i := range result.events {
+			logs[i] = result.logs[j]
+			totalGas += result.gasUsed[j]
+		}
+	}
+
+	for _, eventLogs := range logs {
+		for _, l := range eventLogs {
+			statedb.AddLog(&types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+		}
+	}
+
+	return totalGas, true
+}
+
+// isSystemCallerTouch reports whether the path is the account or the balance of the
+// system address, which every state-sync commit touches as the caller.
+func isSystemCallerTouch(path blockstm.Key) bool {
+	if path.GetAddress() != types.SystemAddress {
+		return false
+	}
+
+	return path.IsAddress() || (!path.IsState() && path.GetSubpath() == state.BalancePath)
+}
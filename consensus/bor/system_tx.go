@@ -0,0 +1,45 @@
+package bor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor/statefull"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SystemTx is a call made by the engine from the system address, like the span
+// and state-sync commits.
+type SystemTx struct {
+	To   common.Address
+	Data []byte
+}
+
+// SystemTxProvider returns the system transactions to apply at the start of the
+// sprint of the given header, after the span and state-sync commits. Providers are
+// invoked both when sealing and when verifying blocks, so the result must only
+// depend on the header and the state.
+type SystemTxProvider func(header *types.Header, state *state.StateDB) ([]SystemTx, error)
+
+// applySystemTxs applies the system transactions of every provider, in the order
+// the providers were registered.
+func (c *Bor) applySystemTxs(ctx context.Context, state *state.StateDB, header *types.Header, chainContext statefull.ChainContext) error {
+	for i, provider := range c.systemTxProviders {
+		txs, err := provider(header, state)
+		if err != nil {
+			return fmt.Errorf("system tx provider %d: %w", i, err)
+		}
+
+		for _, tx := range txs {
+			msg := statefull.GetSystemMessage(tx.To, tx.Data)
+
+			if _, err := statefull.ApplyMessage(ctx, msg, state, header, c.chainConfig, chainContext); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -128,3 +128,23 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// FeeRecipientEngine is a consensus engine which can direct the transaction fees
+// of a block to another address than the block's author.
+type FeeRecipientEngine interface {
+	Engine
+
+	// FeeRecipient returns the address the transaction fees of the block are
+	// credited to, or nil if they go to the block's author.
+	FeeRecipient(header *types.Header) *common.Address
+}
+
+// StateSyncEngine is a consensus engine applying the state-sync events, whose
+// progress can be restored from the one persisted with the blocks.
+type StateSyncEngine interface {
+	Engine
+
+	// SetStateSyncProgress restores the last state-sync event applied, by the
+	// block of the given number, unless the engine applied events since.
+	SetStateSyncProgress(id uint64, number uint64)
+}
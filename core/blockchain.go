@@ -111,6 +111,7 @@ const (
 	blockCacheLimit     = 256
 	receiptsCacheLimit  = 1024
 	txLookupCacheLimit  = 1024
+	parallelStatsLimit  = 256
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 
@@ -249,6 +250,17 @@ type BlockChain struct {
 	stateSyncData    []*types.StateSyncData                  // State sync data
 	stateSyncFeed    event.Feed                              // State sync feed
 	chain2HeadFeed   event.Feed                              // Reorg/NewHead/Fork data feed
+	reorgStats       ReorgStats                              // Cumulative reorg statistics
+	reorgStatsLock   sync.Mutex                              // Protects reorgStats
+
+	persistStateSyncProgress bool                          // Whether the last applied state-sync event is persisted with the blocks
+	pruneMilestonesOnSetHead bool                          // Whether the milestone state above the new head is pruned on SetHead
+	preCommitHook            atomic.Pointer[preCommitHook] // Hook vetoing the blocks about to become the head, nil if none
+	pinnedBlocks             map[common.Hash]common.Hash   // State roots of the blocks pinned from the state GC, by block hash
+	pinnedBlocksLock         sync.Mutex                    // Protects pinnedBlocks
+
+	parallelStats     *lru.Cache[common.Hash, *ParallelEVMStats] // Parallel execution statistics of the recent blocks, nil if parallel EVM is disabled
+	parallelStatsLock sync.Mutex                                 // Serializes the updates of parallelStats
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -500,6 +512,7 @@ func NewParallelBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis
 	}
 
 	bc.parallelProcessor = NewParallelStateProcessor(chainConfig, bc, engine)
+	bc.parallelStats = lru.NewCache[common.Hash, *ParallelEVMStats](parallelStatsLimit)
 
 	return bc, nil
 }
@@ -516,6 +529,7 @@ func (bc *BlockChain) ProcessBlock(block *types.Block, parent *types.Header) (ty
 		err      error
 		statedb  *state.StateDB
 		counter  metrics.Counter
+		parallel bool
 	}
 
 	resultChan := make(chan Result, 2)
@@ -533,7 +547,7 @@ func (bc *BlockChain) ProcessBlock(block *types.Block, parent *types.Header) (ty
 		go func() {
 			parallelStatedb.StartPrefetcher("chain")
 			receipts, logs, usedGas, err := bc.parallelProcessor.Process(block, parallelStatedb, bc.vmConfig, ctx)
-			resultChan <- Result{receipts, logs, usedGas, err, parallelStatedb, blockExecutionParallelCounter}
+			resultChan <- Result{receipts, logs, usedGas, err, parallelStatedb, blockExecutionParallelCounter, true}
 		}()
 	}
 
@@ -548,7 +562,7 @@ func (bc *BlockChain) ProcessBlock(block *types.Block, parent *types.Header) (ty
 		go func() {
 			statedb.StartPrefetcher("chain")
 			receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig, ctx)
-			resultChan <- Result{receipts, logs, usedGas, err, statedb, blockExecutionSerialCounter}
+			resultChan <- Result{receipts, logs, usedGas, err, statedb, blockExecutionSerialCounter, false}
 		}()
 	}
 
@@ -567,6 +581,12 @@ func (bc *BlockChain) ProcessBlock(block *types.Block, parent *types.Header) (ty
 
 	result.counter.Inc(1)
 
+	if bc.parallelProcessor != nil {
+		bc.updateParallelEVMStats(block, func(stats *ParallelEVMStats) {
+			stats.Sequential = !result.parallel
+		})
+	}
+
 	// Make sure we are not leaking any prefetchers
 	if processorCount == 2 {
 		go func() {
@@ -686,6 +706,9 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	if _, err := bc.setHeadBeyondRoot(head, 0, common.Hash{}, false); err != nil {
 		return err
 	}
+
+	bc.pruneMilestonesAboveHead()
+
 	// Send chain head event to update the transaction pool
 	header := bc.CurrentBlock()
 	block := bc.GetBlock(header.Hash(), header.Number.Uint64())
@@ -711,6 +734,9 @@ func (bc *BlockChain) SetHeadWithTimestamp(timestamp uint64) error {
 	if _, err := bc.setHeadBeyondRoot(0, timestamp, common.Hash{}, false); err != nil {
 		return err
 	}
+
+	bc.pruneMilestonesAboveHead()
+
 	// Send chain head event to update the transaction pool
 	header := bc.CurrentBlock()
 	block := bc.GetBlock(header.Hash(), header.Number.Uint64())
@@ -1169,6 +1195,8 @@ func (bc *BlockChain) Stop() {
 			}
 		}
 
+		bc.unpinBlocks()
+
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem())
 		}
@@ -1634,6 +1662,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 
 			// Write bor tx reverse lookup
 			rawdb.WriteBorTxLookupEntry(blockBatch, block.Hash(), block.NumberU64())
+
+			// Persist the state-sync progress atomically with the block
+			bc.writeStateSyncProgress(blockBatch, block, stateSyncLogs)
 		}
 	}
 
@@ -1756,6 +1787,12 @@ func (bc *BlockChain) writeBlockAndSetHead(ctx context.Context, block *types.Blo
 		return NonStatTy, err
 	}
 
+	if reorg {
+		if err = bc.runPreCommitHook(block.Header()); err != nil {
+			return NonStatTy, err
+		}
+	}
+
 	tracing.Exec(writeBlockAndSetHeadCtx, "", "blockchain.reorg", func(_ context.Context, span trace.Span) {
 		if reorg {
 			// Reorganise the chain if the parent is not the head block
@@ -2649,6 +2686,8 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 		blockReorgAddMeter.Mark(int64(len(newChain)))
 		blockReorgDropMeter.Mark(int64(len(oldChain)))
 		blockReorgMeter.Mark(1)
+
+		bc.recordReorg(oldChain)
 	} else if len(newChain) > 0 {
 		// Special case happens in the post merge stage that current head is
 		// the ancestor of new head while these two blocks are not consecutive
@@ -2724,6 +2763,8 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 		rawdb.DeleteCanonicalHash(indexesBatch, i)
 	}
 
+	bc.reorgStateSyncProgress(indexesBatch, commonBlock, newChain)
+
 	if err := indexesBatch.Write(); err != nil {
 		log.Crit("Failed to delete useless indexes", "err", err)
 	}
@@ -3096,6 +3137,19 @@ func (bc *BlockChain) SetBlockValidatorAndProcessorForTesting(v Validator, p Pro
 	bc.processor = p
 }
 
+// SetForkTiebreak sets the policy used by the fork choice to choose between two
+// heads of equal total difficulty and equal height.
+// This method is unsafe and should only be used before block import starts.
+func (bc *BlockChain) SetForkTiebreak(tiebreak ForkTiebreak) {
+	bc.forker.SetTiebreak(tiebreak)
+}
+
+// TrustHeaders lets the chains made of the given headers, fetched from a trusted
+// peer, skip the checkpoint and milestone validation when imported.
+func (bc *BlockChain) TrustHeaders(headers []*types.Header) {
+	bc.forker.TrustHeaders(headers)
+}
+
 // SetTrieFlushInterval configures how often in-memory tries are persisted to disk.
 // The interval is in terms of block processing time, not wall clock.
 // It is thread-safe and can be called repeatedly without side effects.
@@ -1,16 +1,22 @@
 package core
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/downloader/whitelist"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -140,3 +146,676 @@ func TestChain2HeadEvent(t *testing.T) {
 			replacementBlocks[3].Hash(),
 		}})
 }
+
+func TestReorgStats(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	defer blockchain.Stop()
+
+	if stats := blockchain.ReorgStats(); stats != (ReorgStats{}) {
+		t.Fatalf("unexpected stats before any reorg: %+v", stats)
+	}
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// A heavier fork replacing the 3 blocks
+	fork, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x1})
+	})
+	if _, err := blockchain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+
+	stats := blockchain.ReorgStats()
+	if stats.Reorgs != 1 || stats.MilestoneForced != 0 || stats.MaxDepth != 3 || stats.LastDepth != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if stats.LastTime == 0 || stats.LastTime > uint64(time.Now().Unix()) {
+		t.Fatalf("unexpected last reorg time: %d", stats.LastTime)
+	}
+}
+
+// milestoneValidatorFake is a chain validator with a whitelisted milestone.
+type milestoneValidatorFake struct {
+	*chainValidatorFake
+	number uint64
+	hash   common.Hash
+}
+
+func (w *milestoneValidatorFake) GetWhitelistedMilestone() (bool, uint64, common.Hash) {
+	return w.hash != common.Hash{}, w.number, w.hash
+}
+
+func TestCompareForks(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		gspec     = &Genesis{Config: params.TestChainConfig}
+		genesis   = gspec.MustCommit(db)
+		validator = &milestoneValidatorFake{chainValidatorFake: newChainValidatorFake(func(*types.Header, []*types.Header) (bool, error) { return true, nil })}
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, validator)
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// A lighter fork from block 2, kept as a side chain
+	fork, _ := GenerateChain(gspec.Config, chain[1], ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x1})
+	})
+	if _, err := blockchain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+
+	head, side := chain[3].Header(), fork[0].Header()
+
+	res, err := blockchain.CompareForks(side, head)
+	if err != nil {
+		t.Fatalf("failed to compare the forks: %v", err)
+	}
+
+	if res.Preferred != head.Hash() || res.Reason != "total difficulty" || res.CommonAncestor != 2 || res.MilestoneForced {
+		t.Fatalf("unexpected comparison: %+v", res)
+	}
+
+	// A milestone on the side chain wins over the difficulty
+	validator.number, validator.hash = 3, side.Hash()
+
+	res, err = blockchain.CompareForks(head, side)
+	if err != nil {
+		t.Fatalf("failed to compare the forks: %v", err)
+	}
+
+	if res.Preferred != side.Hash() || !res.MilestoneForced || !res.B.Finalized || res.A.Finalized {
+		t.Fatalf("unexpected comparison: %+v", res)
+	}
+
+	// A milestone before the fork decides nothing
+	validator.number, validator.hash = 2, chain[1].Hash()
+
+	res, err = blockchain.CompareForks(side, head)
+	if err != nil {
+		t.Fatalf("failed to compare the forks: %v", err)
+	}
+
+	if res.Preferred != head.Hash() || res.MilestoneForced || !res.A.Finalized || !res.B.Finalized {
+		t.Fatalf("unexpected comparison: %+v", res)
+	}
+}
+
+func TestLastStateSyncID(t *testing.T) {
+	committed := func(id uint64) *types.Log {
+		return &types.Log{Topics: []common.Hash{stateCommittedTopic, common.BigToHash(new(big.Int).SetUint64(id))}}
+	}
+
+	if _, ok := lastStateSyncID([]*types.Log{{Topics: []common.Hash{{0x1}}}}); ok {
+		t.Fatal("unexpected state-sync id without a StateCommitted log")
+	}
+
+	// Logs emitted by the receivers of the events are skipped
+	logs := []*types.Log{committed(7), {Topics: []common.Hash{{0x1}, {0x9}}}, committed(9), committed(8)}
+
+	if id, ok := lastStateSyncID(logs); !ok || id != 9 {
+		t.Fatalf("unexpected state-sync id: %d, %v", id, ok)
+	}
+}
+
+func TestStateSyncProgressRestart(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// Block 3 applied the events up to 5 in the middle of the sprint, then the
+	// node crashes and the head is rewound below it
+	rawdb.WriteLastStateSyncID(db, 5, 3, chain[2].Hash())
+
+	if err := blockchain.SetHead(2); err != nil {
+		t.Fatalf("failed to rewind: %v", err)
+	}
+
+	blockchain.Stop()
+
+	// Restart on the same database
+	engine := &stateSyncEngine{Engine: ethash.NewFaker()}
+	blockchain, _ = NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil, nil)
+
+	blockchain.SetPersistStateSyncProgress(true)
+
+	progress := blockchain.LastStateSync()
+	if progress == nil || progress.ID != 5 || progress.Number != 3 || progress.Hash != chain[2].Hash() {
+		t.Fatalf("unexpected progress after the restart: %+v", progress)
+	}
+
+	if err := blockchain.verifyStateSyncProgress(progress); !errors.Is(err, errStateSyncAboveHead) {
+		t.Fatalf("expected the progress above the head, got %v", err)
+	}
+
+	// The progress of a block lost in the crash isn't restored in the engine
+	if engine.restored != nil {
+		t.Fatalf("unexpected progress restored in the engine: %+v", engine.restored)
+	}
+
+	// Once the block is imported again, the progress is back on the local chain
+	if _, err := blockchain.InsertChain(chain[2:]); err != nil {
+		t.Fatalf("failed to reimport the chain: %v", err)
+	}
+
+	if err := blockchain.verifyStateSyncProgress(progress); err != nil {
+		t.Fatalf("unexpected progress verification failure: %v", err)
+	}
+
+	blockchain.Stop()
+
+	// And restored in the engine on the next start
+	engine = &stateSyncEngine{Engine: ethash.NewFaker()}
+	blockchain, _ = NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil, nil)
+	defer blockchain.Stop()
+
+	blockchain.SetPersistStateSyncProgress(true)
+
+	if engine.restored == nil || *engine.restored != [2]uint64{5, 3} {
+		t.Fatalf("unexpected progress restored in the engine: %+v", engine.restored)
+	}
+
+	// A progress persisted by a block dropped by a reorg isn't canonical
+	rawdb.WriteLastStateSyncID(db, 5, 3, common.Hash{0x1})
+
+	if err := blockchain.verifyStateSyncProgress(blockchain.LastStateSync()); !errors.Is(err, errStateSyncNotCanonical) {
+		t.Fatalf("expected the progress not canonical, got %v", err)
+	}
+}
+
+func TestStateSyncProgressReorg(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+		engine  = ethash.NewFaker()
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil, nil)
+	defer blockchain.Stop()
+
+	blockchain.SetPersistStateSyncProgress(true)
+
+	fork := func(parent *types.Block, n int, coinbase common.Address) []*types.Block {
+		blocks, _ := GenerateChain(gspec.Config, parent, engine, db, n, func(i int, gen *BlockGen) {
+			gen.SetCoinbase(coinbase)
+		})
+
+		return blocks
+	}
+
+	// The state-sync events applied by a block, as stored in its bor receipt
+	applied := func(block *types.Block, id uint64) {
+		rawdb.WriteBorReceipt(db, block.Hash(), block.NumberU64(), &types.ReceiptForStorage{
+			Status: types.ReceiptStatusSuccessful,
+			Logs:   []*types.Log{{Topics: []common.Hash{stateCommittedTopic, common.BigToHash(new(big.Int).SetUint64(id))}}},
+		})
+	}
+
+	expect := func(id uint64, block *types.Block) {
+		t.Helper()
+
+		progress := blockchain.LastStateSync()
+		if progress == nil || progress.ID != id || progress.Number != block.NumberU64() || progress.Hash != block.Hash() {
+			t.Fatalf("unexpected progress: %+v, want %d at block %d", progress, id, block.NumberU64())
+		}
+	}
+
+	prefix := fork(genesis, 1, common.Address{})
+	applied(prefix[0], 3)
+
+	a := fork(prefix[0], 3, common.Address{0xa})
+	if _, err := blockchain.InsertChain(append(prefix, a...)); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	rawdb.WriteLastStateSyncID(db, 5, a[1].NumberU64(), a[1].Hash())
+
+	// The new chain applies no event, the progress is back to the one below the fork
+	b := fork(prefix[0], 4, common.Address{0xb})
+	if _, err := blockchain.InsertChain(b); err != nil {
+		t.Fatalf("failed to insert fork b: %v", err)
+	}
+
+	if blockchain.CurrentBlock().Hash() != b[len(b)-1].Hash() {
+		t.Fatal("fork b not canonical")
+	}
+
+	expect(3, prefix[0])
+
+	// The new chain applies events, the progress is the last one of them
+	c := fork(prefix[0], 5, common.Address{0xc})
+	applied(c[0], 9)
+
+	if _, err := blockchain.InsertChain(c); err != nil {
+		t.Fatalf("failed to insert fork c: %v", err)
+	}
+
+	if blockchain.CurrentBlock().Hash() != c[len(c)-1].Hash() {
+		t.Fatal("fork c not canonical")
+	}
+
+	expect(9, c[0])
+}
+
+// stateSyncEngine records the state-sync progress restored in the engine.
+type stateSyncEngine struct {
+	consensus.Engine
+	restored *[2]uint64 // ID and number of the restored progress
+}
+
+func (e *stateSyncEngine) SetStateSyncProgress(id uint64, number uint64) {
+	e.restored = &[2]uint64{id, number}
+}
+
+func TestPreCommitHook(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {})
+
+	var inspected []uint64
+
+	// The hook vetoes block 3
+	reject := func(ctx context.Context, head *types.Header) error {
+		inspected = append(inspected, head.Number.Uint64())
+
+		if head.Number.Uint64() == 3 {
+			return errors.New("custom finality rule")
+		}
+
+		return nil
+	}
+
+	if err := blockchain.SetPreCommitHook(reject, time.Second); err != nil {
+		t.Fatalf("failed to register the hook: %v", err)
+	}
+
+	if err := blockchain.SetPreCommitHook(reject, time.Second); !errors.Is(err, ErrPreCommitHookRegistered) {
+		t.Fatalf("expected a single hook, got %v", err)
+	}
+
+	if _, err := blockchain.InsertChain(chain); !errors.Is(err, ErrPreCommitRejected) {
+		t.Fatalf("expected the block rejected, got %v", err)
+	}
+
+	if head := blockchain.CurrentBlock().Number.Uint64(); head != 2 || len(inspected) != 3 {
+		t.Fatalf("unexpected head %d after inspecting %v", head, inspected)
+	}
+
+	// A hook not deciding in time doesn't hold the import
+	_ = blockchain.SetPreCommitHook(nil, 0)
+
+	stuck := func(ctx context.Context, head *types.Header) error {
+		<-ctx.Done()
+		return errors.New("too late")
+	}
+
+	if err := blockchain.SetPreCommitHook(stuck, 10*time.Millisecond); err != nil {
+		t.Fatalf("failed to register the hook: %v", err)
+	}
+
+	if _, err := blockchain.InsertChain(chain[2:]); err != nil {
+		t.Fatalf("failed to insert the chain: %v", err)
+	}
+
+	if head := blockchain.CurrentBlock().Number.Uint64(); head != 4 {
+		t.Fatalf("unexpected head %d", head)
+	}
+}
+
+func TestPinLockedSprint(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		checker = whitelist.NewService(db)
+	)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.SnapshotLimit = 0
+	cacheConfig.TriesInMemory = 16
+
+	blockchain, _ := NewBlockChain(db, &cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, checker)
+	defer blockchain.Stop()
+
+	checker.SetBlockPinner(blockchain)
+
+	// Generated apart, not to commit the states of the blocks in the database
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 96, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+	})
+
+	if _, err := blockchain.InsertChain(chain[:8]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// Lock the sprint ending at block 8, then move far enough for the state GC
+	// to prune its state
+	locked := chain[7]
+
+	checker.LockMutex(8)
+	checker.UnlockMutex(true, "milestoneID1", 8, locked.Hash())
+
+	if _, err := blockchain.InsertChain(chain[8:48]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	if !blockchain.HasState(locked.Root()) {
+		t.Fatalf("state of the locked block 8 pruned")
+	}
+
+	if blockchain.HasState(chain[8].Root()) {
+		t.Fatalf("state of the unlocked block 9 not pruned")
+	}
+
+	// Once released, the block is pruned as any other
+	checker.UnlockSprint(8)
+
+	if _, err := blockchain.InsertChain(chain[48:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	if blockchain.HasState(locked.Root()) {
+		t.Fatalf("state of the released block 8 not pruned")
+	}
+}
+
+func TestPruneMilestonesOnSetHead(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		checker = whitelist.NewService(db)
+	)
+
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 32, nil)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, checker)
+	defer blockchain.Stop()
+
+	blockchain.SetPruneMilestonesOnSetHead(true)
+
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// Whitelist the milestone ending at block 16, and lock the sprint ending at block 24
+	checker.ProcessMilestone(16, chain[15].Hash())
+	checker.LockMutex(24)
+	checker.UnlockMutex(true, "milestoneID1", 24, chain[23].Hash())
+
+	// Setting the head above them keeps them
+	if err := blockchain.SetHead(28); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+
+	if ids := checker.GetMilestoneIDsList(); len(ids) != 1 {
+		t.Fatalf("milestone list pruned above the head: %v", ids)
+	}
+
+	// Setting the head below them prunes them
+	if err := blockchain.SetHead(12); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+
+	if ids := checker.GetMilestoneIDsList(); len(ids) != 0 {
+		t.Fatalf("milestone list not pruned: %v", ids)
+	}
+
+	if locked, _, _, _ := checker.GetLockedSprintInfo(); locked {
+		t.Fatalf("sprint still locked")
+	}
+
+	if exists, number, _ := checker.GetWhitelistedMilestone(); exists {
+		t.Fatalf("milestone %d still whitelisted", number)
+	}
+
+	// The chain can be re-imported above the pruned milestone
+	if _, err := blockchain.InsertChain(chain[12:]); err != nil {
+		t.Fatalf("failed to re-insert chain: %v", err)
+	}
+}
+
+func TestParallelEVMStats(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000000)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+
+	blockchain, err := NewParallelBlockChain(db, defaultCacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{ParallelEnable: true, ParallelSpeculativeProcesses: 4}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, gen *BlockGen) {
+		for j := 0; j < 4; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{byte(j + 1)}, big.NewInt(1000), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+			gen.AddTx(tx)
+		}
+	})
+
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	for _, block := range chain {
+		stats := blockchain.ParallelEVMStats(block.Hash())
+		if stats == nil {
+			t.Fatalf("no statistics for block %d", block.NumberU64())
+		}
+
+		if stats.Number != block.NumberU64() || stats.Hash != block.Hash() {
+			t.Fatalf("statistics of block %d (%x) returned for block %d (%x)", stats.Number, stats.Hash, block.NumberU64(), block.Hash())
+		}
+
+		// The sequential processor can be faster than the parallel one, which is
+		// then interrupted before completing
+		if !stats.Executed && !stats.Sequential {
+			t.Fatalf("block %d neither executed in parallel nor sequentially: %+v", block.NumberU64(), stats)
+		}
+
+		if stats.Executed && (stats.Transactions != 4 || stats.Workers != 5) {
+			t.Fatalf("unexpected statistics of block %d: %+v", block.NumberU64(), stats)
+		}
+	}
+
+	if stats := blockchain.ParallelEVMStats(common.Hash{1}); stats != nil {
+		t.Fatalf("statistics returned for an unknown block: %+v", stats)
+	}
+}
+
+func TestParallelSequentialTo(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		hot   = common.HexToAddress("0x1000")
+		keys  = make([]*ecdsa.PrivateKey, 8)
+		alloc = GenesisAlloc{
+			// Increments the counter in slot 0
+			hot: {Code: common.FromHex("0x60005460010160005500"), Balance: common.Big0},
+		}
+	)
+
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: big.NewInt(1000000000000000000)}
+	}
+
+	gspec := &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	blockchain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	// Every transaction calls the hot contract, interleaved with plain transfers
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, gen *BlockGen) {
+		for j, key := range keys {
+			to := hot
+			if j%2 == 1 {
+				to = common.Address{byte(j)}
+			}
+
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(crypto.PubkeyToAddress(key.PublicKey)), to, big.NewInt(1), 100000, gen.header.BaseFee, nil), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+
+	cfg := vm.Config{ParallelEnable: true, ParallelSpeculativeProcesses: 4, ParallelSequentialTo: []common.Address{hot}}
+	processor := NewParallelStateProcessor(gspec.Config, blockchain, blockchain.Engine())
+
+	parent := blockchain.CurrentBlock()
+
+	for _, block := range chain {
+		statedb, err := state.New(parent.Root, blockchain.stateCache, nil)
+		if err != nil {
+			t.Fatalf("failed to open the state of block %d: %v", parent.Number, err)
+		}
+
+		if _, _, _, err := processor.Process(block, statedb, cfg, nil); err != nil {
+			t.Fatalf("failed to process block %d: %v", block.NumberU64(), err)
+		}
+
+		// The root computed by the sequential execution
+		if root := statedb.IntermediateRoot(gspec.Config.IsEIP158(block.Number())); root != block.Root() {
+			t.Fatalf("block %d: state root %x, expected %x", block.NumberU64(), root, block.Root())
+		}
+
+		if _, err := blockchain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", block.NumberU64(), err)
+		}
+
+		parent = block.Header()
+	}
+
+	statedb, _ := blockchain.State()
+	if counter := statedb.GetState(hot, common.Hash{}); counter != common.BigToHash(big.NewInt(8)) {
+		t.Fatalf("unexpected counter of the hot contract %x", counter)
+	}
+}
+
+// feeRecipientEngine directs the transaction fees of the blocks to a fixed address.
+type feeRecipientEngine struct {
+	consensus.Engine
+	recipient common.Address
+}
+
+func (e *feeRecipientEngine) FeeRecipient(_ *types.Header) *common.Address {
+	return &e.recipient
+}
+
+func TestParallelFeeRecipient(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		author    = common.HexToAddress("0x2000")
+		recipient = common.HexToAddress("0x3000")
+		reader    = common.HexToAddress("0x1000")
+		keys      = make([]*ecdsa.PrivateKey, 4)
+		engine    = &feeRecipientEngine{Engine: ethash.NewFaker(), recipient: recipient}
+		alloc     = GenesisAlloc{
+			// Stores COINBASE in slot 0
+			reader: {Code: common.FromHex("0x4160005500"), Balance: common.Big0},
+		}
+	)
+
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: big.NewInt(1000000000000000000)}
+	}
+
+	gspec := &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	blockchain, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	_, chain, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(author)
+
+		for j, key := range keys {
+			to := reader
+			if j%2 == 1 {
+				to = common.Address{byte(j)}
+			}
+
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(crypto.PubkeyToAddress(key.PublicKey)), to, big.NewInt(1), 100000, new(big.Int).Add(gen.header.BaseFee, big.NewInt(params.GWei)), nil), signer, key)
+			gen.AddTxWithChain(blockchain, tx)
+		}
+	})
+
+	cfg := vm.Config{ParallelEnable: true, ParallelSpeculativeProcesses: 4}
+	processor := NewParallelStateProcessor(gspec.Config, blockchain, blockchain.Engine())
+
+	parent := blockchain.CurrentBlock()
+
+	for _, block := range chain {
+		statedb, err := state.New(parent.Root, blockchain.stateCache, nil)
+		if err != nil {
+			t.Fatalf("failed to open the state of block %d: %v", parent.Number, err)
+		}
+
+		if _, _, _, err := processor.Process(block, statedb, cfg, nil); err != nil {
+			t.Fatalf("failed to process block %d: %v", block.NumberU64(), err)
+		}
+
+		// The root computed by the sequential execution
+		if root := statedb.IntermediateRoot(gspec.Config.IsEIP158(block.Number())); root != block.Root() {
+			t.Fatalf("block %d: state root %x, expected %x", block.NumberU64(), root, block.Root())
+		}
+
+		if _, err := blockchain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", block.NumberU64(), err)
+		}
+
+		parent = block.Header()
+	}
+
+	statedb, _ := blockchain.State()
+	if statedb.GetBalance(recipient).Sign() == 0 {
+		t.Fatalf("no fees credited to the recipient")
+	}
+
+	// Contracts still see the block's author as the coinbase
+	if coinbase := statedb.GetState(reader, common.Hash{}); coinbase != common.BytesToHash(author.Bytes()) {
+		t.Fatalf("unexpected coinbase seen by the contract %x", coinbase)
+	}
+}
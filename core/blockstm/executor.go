@@ -28,6 +28,10 @@ type ExecTask interface {
 	Sender() common.Address
 	Settle()
 	Dependencies() []int
+
+	// Sequential reports whether the task is executed after the preceding
+	// sequential task only, e.g. as it's known to conflict with it.
+	Sequential() bool
 }
 
 type ExecVersionView struct {
@@ -164,6 +168,17 @@ type ParallelExecutionResult struct {
 	Stats   *map[int]ExecutionStat
 	Deps    *DAG
 	AllDeps map[int]map[int]bool
+	Summary ExecutionSummary
+}
+
+// ExecutionSummary counts the work done by a parallel execution.
+type ExecutionSummary struct {
+	Tasks              int // Transactions executed
+	Parallel           int // Transactions executed speculatively, without waiting for the preceding ones to be validated
+	Execs              int // Executions, including the re-executions
+	Aborts             int // Executions aborted on a dependency on a transaction not executed yet
+	ValidationFailures int // Executions invalidated by a conflicting write of a preceding transaction
+	Workers            int // Workers executing the transactions
 }
 
 const numGoProcs = 1
@@ -298,6 +313,7 @@ func NewParallelExecutor(tasks []ExecTask, profile bool, metadata bool, numProcs
 // nolint: gocognit
 func (pe *ParallelExecutor) Prepare() error {
 	prevSenderTx := make(map[common.Address]int)
+	prevSequentialTx := -1
 
 	for i, t := range pe.tasks {
 		clearPendingFlag := false
@@ -311,20 +327,30 @@ func (pe *ParallelExecutor) Prepare() error {
 
 				pe.execTasks.addDependencies(val, i)
 			}
-
-			if clearPendingFlag {
-				pe.execTasks.clearPending(i)
-
-				clearPendingFlag = false
-			}
 		} else {
 			if tx, ok := prevSenderTx[t.Sender()]; ok {
+				clearPendingFlag = true
+
 				pe.execTasks.addDependencies(tx, i)
-				pe.execTasks.clearPending(i)
 			}
 
 			prevSenderTx[t.Sender()] = i
 		}
+
+		// Sequential tasks are chained, each one waiting for the preceding one
+		if t.Sequential() {
+			if prevSequentialTx != -1 {
+				clearPendingFlag = true
+
+				pe.execTasks.addDependencies(prevSequentialTx, i)
+			}
+
+			prevSequentialTx = i
+		}
+
+		if clearPendingFlag {
+			pe.execTasks.clearPending(i)
+		}
 	}
 
 	pe.workerWg.Add(pe.numSpeculativeProcs + numGoProcs)
@@ -567,7 +593,7 @@ func (pe *ParallelExecutor) Step(res *ExecResult) (result ParallelExecutionResul
 			deps = BuildDAG(*pe.lastTxIO)
 		}
 
-		return ParallelExecutionResult{pe.lastTxIO, &pe.stats, &deps, allDeps}, err
+		return ParallelExecutionResult{TxIO: pe.lastTxIO, Stats: &pe.stats, Deps: &deps, AllDeps: allDeps, Summary: pe.summary()}, err
 	}
 
 	// Send the next immediate pending transaction to be executed
@@ -599,11 +625,30 @@ func (pe *ParallelExecutor) Step(res *ExecResult) (result ParallelExecutionResul
 	return
 }
 
+// summary counts the work done by the execution.
+func (pe *ParallelExecutor) summary() ExecutionSummary {
+	summary := ExecutionSummary{
+		Tasks:              len(pe.tasks),
+		Execs:              pe.cntExec,
+		Aborts:             pe.cntAbort,
+		ValidationFailures: pe.cntValidationFail,
+		Workers:            pe.numSpeculativeProcs + numGoProcs,
+	}
+
+	for _, waited := range pe.skipCheck {
+		if !waited {
+			summary.Parallel++
+		}
+	}
+
+	return summary
+}
+
 type PropertyCheck func(*ParallelExecutor) error
 
 func executeParallelWithCheck(tasks []ExecTask, profile bool, check PropertyCheck, metadata bool, numProcs int, interruptCtx context.Context) (result ParallelExecutionResult, err error) {
 	if len(tasks) == 0 {
-		return ParallelExecutionResult{MakeTxnInputOutput(len(tasks)), nil, nil, nil}, nil
+		return ParallelExecutionResult{TxIO: MakeTxnInputOutput(len(tasks))}, nil
 	}
 
 	pe := NewParallelExecutor(tasks, profile, metadata, numProcs)
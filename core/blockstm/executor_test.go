@@ -42,6 +42,7 @@ type testExecTask struct {
 	sender       common.Address
 	nonce        int
 	dependencies []int
+	sequential   bool
 }
 
 type PathGenerator func(addr common.Address, i int, j int, total int) Key
@@ -172,6 +173,10 @@ func (t *testExecTask) Dependencies() []int {
 	return t.dependencies
 }
 
+func (t *testExecTask) Sequential() bool {
+	return t.sequential
+}
+
 func randTimeGenerator(min time.Duration, max time.Duration) func(txIdx int, opIdx int) time.Duration {
 	return func(txIdx int, opIdx int) time.Duration {
 		return time.Duration(rand.Int63n(int64(max-min))) + min
@@ -986,3 +991,54 @@ func TestBreakFromPartialCircularDependency(t *testing.T) {
 		t.Error("Expected cancel error")
 	}
 }
+
+func TestExecutionSummary(t *testing.T) {
+	t.Parallel()
+	rand.New(rand.NewSource(0))
+
+	sender := func(i int) common.Address { return common.BigToAddress(big.NewInt(int64(i))) }
+	tasks, _ := taskFactory(50, sender, 10, 10, 10, randomPathGenerator, readTime, writeTime, nonIOTime)
+
+	result, err := executeParallelWithCheck(tasks, false, nil, false, numProcs, nil)
+	assert.NoError(t, err, "error occur during parallel execution")
+
+	summary := result.Summary
+
+	assert.Equal(t, 50, summary.Tasks)
+	assert.Equal(t, numProcs+numGoProcs, summary.Workers)
+	assert.GreaterOrEqual(t, summary.Execs, summary.Tasks)
+	assert.LessOrEqual(t, summary.Parallel, summary.Tasks)
+	assert.Positive(t, summary.Parallel)
+
+	// Every re-execution follows an abort or a failed validation
+	assert.Equal(t, summary.Execs-summary.Tasks, summary.Aborts+summary.ValidationFailures)
+}
+
+func TestSequentialTasks(t *testing.T) {
+	t.Parallel()
+	rand.New(rand.NewSource(0))
+
+	// Every transaction reads and writes the storage of the same hot contract
+	hotContract := common.BigToAddress(big.NewInt(1000))
+	hotPath := func(addr common.Address, i int, j int, total int) Key { return NewSubpathKey(hotContract, 0) }
+	sender := func(i int) common.Address { return common.BigToAddress(big.NewInt(int64(i))) }
+
+	reExecutions := func(sequential bool) int {
+		tasks, _ := taskFactory(100, sender, 5, 5, 10, hotPath, readTime, writeTime, nonIOTime)
+
+		for _, task := range tasks {
+			task.(*testExecTask).sequential = sequential
+		}
+
+		result, err := executeParallelWithCheck(tasks, false, checkNoDroppedTx, false, numProcs, nil)
+		assert.NoError(t, err, "error occur during parallel execution")
+
+		return result.Summary.Execs - result.Summary.Tasks
+	}
+
+	speculative, sequential := reExecutions(false), reExecutions(true)
+
+	t.Logf("re-executions: speculative %d, sequential %d", speculative, sequential)
+
+	assert.Less(t, sequential, speculative)
+}
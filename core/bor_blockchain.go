@@ -1,11 +1,189 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
+// ReorgStats are cumulative statistics of the chain reorgs since the start.
+type ReorgStats struct {
+	Reorgs          uint64 `json:"reorgs"`
+	MilestoneForced uint64 `json:"milestoneForced"` // Reorgs dropping a block conflicting with the whitelisted milestone
+	MaxDepth        uint64 `json:"maxDepth"`        // Most blocks dropped by a reorg
+	LastTime        uint64 `json:"lastTime"`        // Unix time of the last reorg, 0 if none
+	LastDepth       uint64 `json:"lastDepth"`       // Blocks dropped by the last reorg
+}
+
+// ReorgStats returns the cumulative reorg statistics since the start.
+func (bc *BlockChain) ReorgStats() ReorgStats {
+	bc.reorgStatsLock.Lock()
+	defer bc.reorgStatsLock.Unlock()
+
+	return bc.reorgStats
+}
+
+// recordReorg accounts a reorg dropping the given blocks (from the old head down)
+// in the reorg statistics. The reorg is milestone forced if one of the dropped
+// blocks conflicts with the whitelisted milestone.
+func (bc *BlockChain) recordReorg(oldChain types.Blocks) {
+	var forced bool
+
+	if bc.forker != nil && bc.forker.validator != nil {
+		if exists, number, hash := bc.forker.validator.GetWhitelistedMilestone(); exists {
+			for _, block := range oldChain {
+				if block.NumberU64() == number {
+					forced = block.Hash() != hash
+					break
+				}
+			}
+		}
+	}
+
+	depth := uint64(len(oldChain))
+
+	bc.reorgStatsLock.Lock()
+	defer bc.reorgStatsLock.Unlock()
+
+	bc.reorgStats.Reorgs++
+	if forced {
+		bc.reorgStats.MilestoneForced++
+	}
+
+	if depth > bc.reorgStats.MaxDepth {
+		bc.reorgStats.MaxDepth = depth
+	}
+
+	bc.reorgStats.LastTime = uint64(time.Now().Unix())
+	bc.reorgStats.LastDepth = depth
+}
+
+// ForkHead is one of the heads compared by CompareForks.
+type ForkHead struct {
+	Hash      common.Hash `json:"hash"`
+	Number    uint64      `json:"number"`
+	TD        *big.Int    `json:"td"`
+	Finalized bool        `json:"finalized"` // Whether the chain of the head holds the whitelisted milestone
+}
+
+// ForkComparison is the fork choice between two heads, as made by the chain.
+type ForkComparison struct {
+	A               ForkHead     `json:"a"`
+	B               ForkHead     `json:"b"`
+	CommonAncestor  uint64       `json:"commonAncestor"`
+	AncestorHash    common.Hash  `json:"ancestorHash"`
+	Milestone       *uint64      `json:"milestone,omitempty"`     // End block of the whitelisted milestone, if any
+	MilestoneHash   *common.Hash `json:"milestoneHash,omitempty"` // Hash of the end block of the whitelisted milestone, if any
+	MilestoneForced bool         `json:"milestoneForced"`         // Whether the whitelisted milestone decides, regardless of the difficulties
+	Preferred       common.Hash  `json:"preferred"`
+	Reason          string       `json:"reason"`
+}
+
+// CompareForks returns which of the two heads the fork choice prefers and why. A is
+// considered the current head, which matters for the tiebreaks keeping the local
+// or the first seen head. Below the whitelisted milestone, the head whose chain
+// holds the milestone always wins.
+func (bc *BlockChain) CompareForks(a *types.Header, b *types.Header) (*ForkComparison, error) {
+	res := &ForkComparison{
+		A: ForkHead{Hash: a.Hash(), Number: a.Number.Uint64(), TD: bc.GetTd(a.Hash(), a.Number.Uint64())},
+		B: ForkHead{Hash: b.Hash(), Number: b.Number.Uint64(), TD: bc.GetTd(b.Hash(), b.Number.Uint64())},
+	}
+
+	if res.A.TD == nil || res.B.TD == nil {
+		return nil, errors.New("missing td")
+	}
+
+	ancestor := rawdb.FindCommonAncestor(bc.db, a, b)
+	if ancestor == nil {
+		return nil, errors.New("no common ancestor")
+	}
+
+	res.CommonAncestor, res.AncestorHash = ancestor.Number.Uint64(), ancestor.Hash()
+
+	if res.A.Hash == res.B.Hash {
+		res.Preferred, res.Reason = res.A.Hash, "same head"
+		return res, nil
+	}
+
+	if bc.forker.validator != nil {
+		if exists, number, hash := bc.forker.validator.GetWhitelistedMilestone(); exists {
+			res.Milestone, res.MilestoneHash = &number, &hash
+
+			res.A.Finalized = bc.holdsBlock(a, number, hash)
+			res.B.Finalized = bc.holdsBlock(b, number, hash)
+
+			// Both forks share the milestone if they diverge after it
+			if res.A.Finalized != res.B.Finalized {
+				res.MilestoneForced = true
+				res.Reason = "milestone"
+
+				if res.A.Finalized {
+					res.Preferred = res.A.Hash
+				} else {
+					res.Preferred = res.B.Hash
+				}
+
+				return res, nil
+			}
+		}
+	}
+
+	reorg, err := bc.forker.ReorgNeeded(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Preferred = res.A.Hash
+	if reorg {
+		res.Preferred = res.B.Hash
+	}
+
+	switch {
+	case res.A.TD.Cmp(res.B.TD) != 0:
+		res.Reason = "total difficulty"
+	case res.A.Number != res.B.Number:
+		res.Reason = "height, the shorter chain wins at equal total difficulty"
+	default:
+		res.Reason = fmt.Sprintf("tiebreak (%s)", bc.forker.tiebreak)
+	}
+
+	return res, nil
+}
+
+// holdsBlock reports whether the chain of the given head holds the block of the
+// given number and hash.
+func (bc *BlockChain) holdsBlock(head *types.Header, number uint64, hash common.Hash) bool {
+	if head.Number.Uint64() < number {
+		return false
+	}
+
+	if rawdb.ReadCanonicalHash(bc.db, head.Number.Uint64()) == head.Hash() {
+		return rawdb.ReadCanonicalHash(bc.db, number) == hash
+	}
+
+	for header := head; header != nil; header = bc.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		if header.Number.Uint64() == number {
+			return header.Hash() == hash
+		}
+
+		if header.Number.Uint64() < number || header.Number.Uint64() == 0 {
+			return false
+		}
+	}
+
+	return false
+}
+
 // GetBorReceiptByHash retrieves the bor block receipt in a given block.
 func (bc *BlockChain) GetBorReceiptByHash(hash common.Hash) *types.Receipt {
 	if receipt, ok := bc.borReceiptsCache.Get(hash); ok {
@@ -29,3 +207,379 @@ func (bc *BlockChain) GetBorReceiptByHash(hash common.Hash) *types.Receipt {
 
 	return receipt
 }
+
+var (
+	errStateSyncAboveHead    = errors.New("state-sync progress above the head")
+	errStateSyncNotCanonical = errors.New("state-sync progress not canonical")
+)
+
+// stateCommittedTopic is the topic of the StateCommitted(uint256 indexed stateId, bool success)
+// event emitted by the state receiver for every applied state-sync event.
+var stateCommittedTopic = common.HexToHash("0x5a22725590b0a51c923940223f7458512164b1113359a735e86e7f27f44791ee")
+
+// lastStateSyncID returns the highest state-sync event id committed by the given
+// state-sync logs, false if none.
+func lastStateSyncID(logs []*types.Log) (uint64, bool) {
+	var (
+		last  uint64
+		found bool
+	)
+
+	for _, l := range logs {
+		if len(l.Topics) < 2 || l.Topics[0] != stateCommittedTopic {
+			continue
+		}
+
+		if id := l.Topics[1].Big().Uint64(); !found || id > last {
+			last, found = id, true
+		}
+	}
+
+	return last, found
+}
+
+// SetPruneMilestonesOnSetHead sets whether SetHead prunes the tracked milestone
+// ids, the milestone lock and the whitelisted checkpoint and milestone above the
+// new head, which would otherwise keep rejecting the chain re-imported below them.
+// This method is unsafe and should only be used before block import starts.
+func (bc *BlockChain) SetPruneMilestonesOnSetHead(prune bool) {
+	bc.pruneMilestonesOnSetHead = prune
+}
+
+// pruneMilestonesAboveHead prunes the milestone state above the current head, if enabled.
+func (bc *BlockChain) pruneMilestonesAboveHead() {
+	if !bc.pruneMilestonesOnSetHead || bc.forker == nil || bc.forker.validator == nil {
+		return
+	}
+
+	bc.forker.validator.PruneAbove(bc.CurrentBlock().Number.Uint64())
+}
+
+// SetPersistStateSyncProgress sets whether the last applied state-sync event is
+// persisted atomically with the canonical block which applied it. On enabling, the
+// progress persisted by the previous run is loaded, checked against the local
+// chain and restored in the engine.
+// This method is unsafe and should only be used before block import starts.
+func (bc *BlockChain) SetPersistStateSyncProgress(persist bool) {
+	bc.persistStateSyncProgress = persist
+
+	if !persist {
+		return
+	}
+
+	progress := bc.LastStateSync()
+	if progress == nil {
+		log.Info("No persisted state-sync progress")
+		return
+	}
+
+	if err := bc.verifyStateSyncProgress(progress); err != nil {
+		// The events applied by the blocks lost in the crash get applied again by their reimport
+		log.Warn("Persisted state-sync progress not on the local chain", "id", progress.ID, "number", progress.Number, "hash", progress.Hash, "err", err)
+		return
+	}
+
+	if engine, ok := bc.engine.(consensus.StateSyncEngine); ok {
+		engine.SetStateSyncProgress(progress.ID, progress.Number)
+	}
+
+	log.Info("Loaded the persisted state-sync progress", "id", progress.ID, "number", progress.Number, "hash", progress.Hash)
+}
+
+// writeStateSyncProgress persists the last state-sync event applied by the block
+// into its batch, if it extends the head. The progress of a block becoming the
+// head by a reorg is recomputed by reorgStateSyncProgress, the sidechain blocks
+// don't write it.
+func (bc *BlockChain) writeStateSyncProgress(batch ethdb.KeyValueWriter, block *types.Block, stateSyncLogs []*types.Log) {
+	if !bc.persistStateSyncProgress || block.ParentHash() != bc.CurrentBlock().Hash() {
+		return
+	}
+
+	if id, ok := lastStateSyncID(stateSyncLogs); ok {
+		rawdb.WriteLastStateSyncID(batch, id, block.NumberU64(), block.Hash())
+	}
+}
+
+// reorgStateSyncProgress recomputes the persisted state-sync progress for the new
+// chain of a reorg, newest block first, on top of the common ancestor. If the new
+// blocks don't apply any event while the progress was on the dropped ones, it's
+// taken from the canonical blocks below the ancestor.
+func (bc *BlockChain) reorgStateSyncProgress(batch ethdb.KeyValueWriter, ancestor *types.Block, newChain types.Blocks) {
+	if !bc.persistStateSyncProgress {
+		return
+	}
+
+	for _, block := range newChain {
+		if id, ok := bc.blockStateSyncID(block.Hash(), block.NumberU64()); ok {
+			rawdb.WriteLastStateSyncID(batch, id, block.NumberU64(), block.Hash())
+			return
+		}
+	}
+
+	if progress := bc.LastStateSync(); progress == nil || progress.Number <= ancestor.NumberU64() {
+		return
+	}
+
+	for number := ancestor.NumberU64(); number > 0; number-- {
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if id, ok := bc.blockStateSyncID(hash, number); ok {
+			rawdb.WriteLastStateSyncID(batch, id, number, hash)
+			return
+		}
+	}
+
+	rawdb.DeleteLastStateSyncID(batch)
+}
+
+// blockStateSyncID returns the last state-sync event applied by the given block,
+// read from its bor receipt.
+func (bc *BlockChain) blockStateSyncID(hash common.Hash, number uint64) (uint64, bool) {
+	receipt := rawdb.ReadRawBorReceipt(bc.db, hash, number)
+	if receipt == nil {
+		return 0, false
+	}
+
+	return lastStateSyncID(receipt.Logs)
+}
+
+// verifyStateSyncProgress checks that the block which applied the persisted
+// state-sync progress is part of the local canonical chain.
+func (bc *BlockChain) verifyStateSyncProgress(progress *rawdb.StateSyncProgress) error {
+	if head := bc.CurrentBlock().Number.Uint64(); progress.Number > head {
+		return fmt.Errorf("%w: block %d, head %d", errStateSyncAboveHead, progress.Number, head)
+	}
+
+	if rawdb.ReadCanonicalHash(bc.db, progress.Number) != progress.Hash {
+		return fmt.Errorf("%w: block %d", errStateSyncNotCanonical, progress.Number)
+	}
+
+	return nil
+}
+
+// LastStateSync returns the last applied state-sync event persisted with the
+// blocks, nil if none.
+func (bc *BlockChain) LastStateSync() *rawdb.StateSyncProgress {
+	return rawdb.ReadLastStateSyncID(bc.db)
+}
+
+var (
+	// ErrPreCommitHookRegistered is returned when registering a pre-commit hook
+	// while another one is registered.
+	ErrPreCommitHookRegistered = errors.New("pre-commit hook already registered")
+
+	// ErrPreCommitRejected is returned when the pre-commit hook rejected a block
+	// about to become the head.
+	ErrPreCommitRejected = errors.New("block rejected by the pre-commit hook")
+
+	// Metrics for counting the blocks rejected by the pre-commit hook, and the ones
+	// accepted as the hook didn't decide in time
+	preCommitRejectedCounter = metrics.NewRegisteredCounter("chain/precommit/rejected", nil)
+	preCommitTimeoutCounter  = metrics.NewRegisteredCounter("chain/precommit/timeout", nil)
+)
+
+// PreCommitHook inspects a block about to become the head of the chain, before
+// it's set as the head. Returning an error rejects the block, which stays stored
+// as a side block, and aborts its import. The context is done once the timeout
+// of the hook elapsed.
+type PreCommitHook func(ctx context.Context, head *types.Header) error
+
+// preCommitHook is the registered pre-commit hook along with its timeout.
+type preCommitHook struct {
+	fn      PreCommitHook
+	timeout time.Duration
+}
+
+// SetPreCommitHook registers the hook invoked with every block about to become
+// the head, which can veto it, e.g. to layer a custom finality rule on top of the
+// chain validator. A single hook can be registered, a nil hook unregisters it.
+//
+// The hook runs on the import path, under the chain lock: no block is imported
+// nor mined while it runs. To bound the stall, the block is accepted if the hook
+// doesn't return within the timeout, which must be positive. A hook rejecting
+// blocks wrongly stalls the chain, as the node can't move past them.
+func (bc *BlockChain) SetPreCommitHook(hook PreCommitHook, timeout time.Duration) error {
+	if hook == nil {
+		bc.preCommitHook.Store(nil)
+		return nil
+	}
+
+	if timeout <= 0 {
+		return fmt.Errorf("invalid pre-commit hook timeout %v", timeout)
+	}
+
+	if !bc.preCommitHook.CompareAndSwap(nil, &preCommitHook{fn: hook, timeout: timeout}) {
+		return ErrPreCommitHookRegistered
+	}
+
+	return nil
+}
+
+// runPreCommitHook invokes the pre-commit hook, if any, with the block about to
+// become the head. The block is accepted if the hook doesn't return in time.
+func (bc *BlockChain) runPreCommitHook(head *types.Header) error {
+	hook := bc.preCommitHook.Load()
+	if hook == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.timeout)
+	defer cancel()
+
+	// Buffered so that a late hook doesn't leak its goroutine
+	result := make(chan error, 1)
+
+	go func() {
+		result <- hook.fn(ctx, types.CopyHeader(head))
+	}()
+
+	var err error
+
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+	}
+
+	// A decision taken after the timeout is ignored
+	if ctx.Err() != nil {
+		preCommitTimeoutCounter.Inc(1)
+		log.Warn("Pre-commit hook timed out, accepting the block", "number", head.Number, "hash", head.Hash(), "timeout", hook.timeout)
+
+		return nil
+	}
+
+	if err != nil {
+		preCommitRejectedCounter.Inc(1)
+		return fmt.Errorf("%w: block %d (%s): %v", ErrPreCommitRejected, head.Number, head.Hash(), err)
+	}
+
+	return nil
+}
+
+var (
+	errPinUnknownBlock = errors.New("unknown block")
+	errPinMissingState = errors.New("missing state")
+)
+
+// PinBlock keeps the state of the given block from being garbage collected, until
+// UnpinBlock is called. It's used to keep the locked sprint checkable against the
+// chain for as long as the lock is held, however long that is. Pinning a block
+// already pinned is a no-op. It's a no-op on archive nodes, which don't prune.
+func (bc *BlockChain) PinBlock(hash common.Hash) error {
+	if bc.cacheConfig.TrieDirtyDisabled {
+		return nil
+	}
+
+	header := bc.GetHeaderByHash(hash)
+	if header == nil {
+		return fmt.Errorf("%w %s", errPinUnknownBlock, hash)
+	}
+
+	bc.pinnedBlocksLock.Lock()
+	defer bc.pinnedBlocksLock.Unlock()
+
+	if _, ok := bc.pinnedBlocks[hash]; ok {
+		return nil
+	}
+
+	if !bc.HasState(header.Root) {
+		return fmt.Errorf("%w of block %d (%s)", errPinMissingState, header.Number, hash)
+	}
+
+	if err := bc.triedb.Reference(header.Root, common.Hash{}); err != nil {
+		return fmt.Errorf("failed to pin the state of block %d (%s): %w", header.Number, hash, err)
+	}
+
+	if bc.pinnedBlocks == nil {
+		bc.pinnedBlocks = make(map[common.Hash]common.Hash)
+	}
+
+	bc.pinnedBlocks[hash] = header.Root
+
+	log.Debug("Pinned block state", "number", header.Number, "hash", hash, "root", header.Root)
+
+	return nil
+}
+
+// UnpinBlock releases the state of a block pinned by PinBlock, which is garbage
+// collected from then on as any other. It's a no-op if the block isn't pinned.
+func (bc *BlockChain) UnpinBlock(hash common.Hash) {
+	bc.pinnedBlocksLock.Lock()
+	defer bc.pinnedBlocksLock.Unlock()
+
+	root, ok := bc.pinnedBlocks[hash]
+	if !ok {
+		return
+	}
+
+	delete(bc.pinnedBlocks, hash)
+
+	_ = bc.triedb.Dereference(root)
+
+	log.Debug("Unpinned block state", "hash", hash, "root", root)
+}
+
+// unpinBlocks releases the states of all the pinned blocks.
+func (bc *BlockChain) unpinBlocks() {
+	bc.pinnedBlocksLock.Lock()
+	defer bc.pinnedBlocksLock.Unlock()
+
+	for hash, root := range bc.pinnedBlocks {
+		_ = bc.triedb.Dereference(root)
+
+		delete(bc.pinnedBlocks, hash)
+	}
+}
+
+// ParallelEVMStats are the statistics of the parallel execution of a block, to
+// tune the parallel EVM. Both the parallel and the sequential processors execute
+// each block, the result of the first one done being taken.
+type ParallelEVMStats struct {
+	Number        uint64      `json:"number"`
+	Hash          common.Hash `json:"hash"`
+	Executed      bool        `json:"executed"`      // Whether the parallel execution completed, the statistics below being zero otherwise
+	Transactions  int         `json:"transactions"`  // Transactions executed
+	Parallel      int         `json:"parallel"`      // Transactions executed speculatively, without waiting for the preceding ones to be validated
+	ReExecutions  int         `json:"reExecutions"`  // Executions beyond the first one of each transaction
+	Conflicts     int         `json:"conflicts"`     // Executions aborted or invalidated by a conflict with another transaction
+	Workers       int         `json:"workers"`       // Workers executing the transactions
+	FeeDelayRerun bool        `json:"feeDelayRerun"` // Whether the block was executed again without delaying the fee transfers
+	Sequential    bool        `json:"sequential"`    // Whether the result of the sequential processor was taken, the parallel execution failing or being slower
+}
+
+// updateParallelEVMStats updates the parallel execution statistics of the block.
+func (bc *BlockChain) updateParallelEVMStats(block *types.Block, update func(stats *ParallelEVMStats)) {
+	if bc.parallelStats == nil {
+		return
+	}
+
+	bc.parallelStatsLock.Lock()
+	defer bc.parallelStatsLock.Unlock()
+
+	stats, ok := bc.parallelStats.Get(block.Hash())
+	if !ok {
+		stats = &ParallelEVMStats{Number: block.NumberU64(), Hash: block.Hash()}
+		bc.parallelStats.Add(block.Hash(), stats)
+	}
+
+	update(stats)
+}
+
+// ParallelEVMStats returns the parallel execution statistics of the given block,
+// nil if it wasn't executed by the parallel EVM or isn't one of the recent blocks.
+func (bc *BlockChain) ParallelEVMStats(hash common.Hash) *ParallelEVMStats {
+	if bc.parallelStats == nil {
+		return nil
+	}
+
+	bc.parallelStatsLock.Lock()
+	defer bc.parallelStatsLock.Unlock()
+
+	stats, ok := bc.parallelStats.Get(hash)
+	if !ok {
+		return nil
+	}
+
+	result := *stats
+
+	return &result
+}
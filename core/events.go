@@ -41,3 +41,11 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// MilestoneEvent is posted when a new milestone has been whitelisted.
+type MilestoneEvent struct {
+	Number      uint64      `json:"number"` // End block of the milestone, i.e. the new finalized block
+	Hash        common.Hash `json:"hash"`
+	MilestoneID string      `json:"milestoneID"`
+	Reorg       bool        `json:"reorg"` // Whether the chain was rewound to match the milestone before it was whitelisted
+}
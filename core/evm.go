@@ -71,9 +71,26 @@ func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common
 		GasLimit:      header.GasLimit,
 		Random:        random,
 		ExcessBlobGas: header.ExcessBlobGas,
+		FeeRecipient:  feeRecipient(header, chain),
 	}
 }
 
+// feeRecipient returns the address the engine directs the transaction fees of the
+// block to, the same way when mining and when verifying so that the state roots
+// match, or nil if they go to the block's author.
+func feeRecipient(header *types.Header, chain ChainContext) *common.Address {
+	// A nil blockchain may be handed over as a context, e.g. when generating chains
+	if bc, ok := chain.(*BlockChain); chain == nil || (ok && bc == nil) {
+		return nil
+	}
+
+	if engine, ok := chain.Engine().(consensus.FeeRecipientEngine); ok {
+		return engine.FeeRecipient(header)
+	}
+
+	return nil
+}
+
 // NewEVMTxContext creates a new transaction context for a single transaction.
 func NewEVMTxContext(msg *Message) vm.TxContext {
 	return vm.TxContext{
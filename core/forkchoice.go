@@ -19,16 +19,54 @@ package core
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/maticnetwork/crand"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 )
 
+// ForkTiebreak is the policy used to choose between two heads of equal total
+// difficulty and equal height. It's not consulted if the total difficulties
+// differ, nor if only the heights differ (the shorter chain wins then).
+type ForkTiebreak string
+
+const (
+	// ForkTiebreakHighestHash keeps the locally preserved head if any, otherwise
+	// the head with the lexicographically larger hash wins (default).
+	ForkTiebreakHighestHash ForkTiebreak = "highesthash"
+
+	// ForkTiebreakLowestHash picks the head with the lexicographically smaller
+	// hash, regardless of which one is preserved locally, so that every node
+	// makes the same decision.
+	ForkTiebreakLowestHash ForkTiebreak = "lowesthash"
+
+	// ForkTiebreakFirstSeen keeps the current head, i.e. the head seen first wins.
+	ForkTiebreakFirstSeen ForkTiebreak = "firstseen"
+)
+
+// ParseForkTiebreak parses a fork tiebreak policy, the empty string selects the default one.
+func ParseForkTiebreak(s string) (ForkTiebreak, error) {
+	switch ForkTiebreak(s) {
+	case "", ForkTiebreakHighestHash:
+		return ForkTiebreakHighestHash, nil
+	case ForkTiebreakLowestHash, ForkTiebreakFirstSeen:
+		return ForkTiebreak(s), nil
+	}
+
+	return "", fmt.Errorf("unknown fork tiebreak policy %q", s)
+}
+
+// forkTiebreakCounter counts the fork choices decided by the tiebreak policy
+var forkTiebreakCounter = metrics.NewRegisteredCounter("chain/forkchoice/tiebreak", nil)
+
 // ChainReader defines a small collection of methods needed to access the local
 // blockchain during header verification. It's implemented by both blockchain
 // and lightchain.
@@ -56,8 +94,19 @@ type ForkChoice struct {
 	preserve func(header *types.Header) bool
 
 	validator ethereum.ChainValidator
+
+	// tiebreak is the policy used when both heads have the same td and height
+	tiebreak ForkTiebreak
+
+	// trusted are the headers fetched from a trusted peer, whose chains skip the
+	// validator. It's meant for recovering a chain stuck on a bad milestone only.
+	trusted *lru.Cache[common.Hash, struct{}]
 }
 
+// trustedHeadersLimit is the maximum number of trusted headers kept, above the
+// number of blocks the downloader caches.
+const trustedHeadersLimit = 16384
+
 type Floater interface {
 	Float64() float64
 }
@@ -71,7 +120,37 @@ func NewForkChoice(chainReader ChainReader, preserve func(header *types.Header)
 		rand:      r,
 		preserve:  preserve,
 		validator: validator,
+		tiebreak:  ForkTiebreakHighestHash,
+		trusted:   lru.NewCache[common.Hash, struct{}](trustedHeadersLimit),
+	}
+}
+
+// TrustHeaders lets the chains made only of the given headers skip the validator.
+func (f *ForkChoice) TrustHeaders(headers []*types.Header) {
+	for _, header := range headers {
+		f.trusted.Add(header.Hash(), struct{}{})
+	}
+}
+
+// isTrusted returns whether all the headers of the chain are trusted.
+func (f *ForkChoice) isTrusted(chain []*types.Header) bool {
+	if len(chain) == 0 {
+		return false
+	}
+
+	for _, header := range chain {
+		if !f.trusted.Contains(header.Hash()) {
+			return false
+		}
 	}
+
+	return true
+}
+
+// SetTiebreak sets the policy used to choose between two heads of equal total
+// difficulty and equal height.
+func (f *ForkChoice) SetTiebreak(tiebreak ForkTiebreak) {
+	f.tiebreak = tiebreak
 }
 
 // ReorgNeeded returns whether the reorg should be applied
@@ -110,13 +189,23 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, extern *types.Header) (b
 	if externNum < localNum {
 		reorg = true
 	} else if externNum == localNum {
-		var currentPreserve, externPreserve bool
-		if f.preserve != nil {
-			currentPreserve, externPreserve = f.preserve(current), f.preserve(extern)
+		// Both heads have the same td and height, the tiebreak policy decides.
+		forkTiebreakCounter.Inc(1)
+
+		switch f.tiebreak {
+		case ForkTiebreakFirstSeen:
+			reorg = false
+		case ForkTiebreakLowestHash:
+			reorg = bytes.Compare(extern.Hash().Bytes(), current.Hash().Bytes()) < 0
+		default:
+			var currentPreserve, externPreserve bool
+			if f.preserve != nil {
+				currentPreserve, externPreserve = f.preserve(current), f.preserve(extern)
+			}
+
+			// Compare hashes of block in case of tie breaker. Lexicographically larger hash wins.
+			reorg = !currentPreserve && (externPreserve || bytes.Compare(current.Hash().Bytes(), extern.Hash().Bytes()) < 0)
 		}
-
-		// Compare hashes of block in case of tie breaker. Lexicographically larger hash wins.
-		reorg = !currentPreserve && (externPreserve || bytes.Compare(current.Hash().Bytes(), extern.Hash().Bytes()) < 0)
 	}
 
 	return reorg, nil
@@ -126,6 +215,11 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, extern *types.Header) (b
 func (f *ForkChoice) ValidateReorg(current *types.Header, chain []*types.Header) (bool, error) {
 	// Call the bor chain validator service
 	if f.validator != nil {
+		if f.isTrusted(chain) {
+			log.Warn("Bypassing checkpoint and milestone validation of trusted chain", "first", chain[0].Number, "last", chain[len(chain)-1].Number)
+			return true, nil
+		}
+
 		return f.validator.IsValidChain(current, chain)
 	}
 
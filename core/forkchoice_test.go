@@ -3,7 +3,9 @@ package core
 import (
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -86,6 +88,73 @@ func TestForkChoice(t *testing.T) {
 	}
 }
 
+func TestForkChoiceTiebreak(t *testing.T) {
+	t.Parallel()
+
+	getTd := func(hash common.Hash, number uint64) *big.Int {
+		return big.NewInt(1)
+	}
+	mockChainReader := newChainReaderFake(getTd)
+
+	headerD := &types.Header{Number: big.NewInt(4), Extra: []byte("D")} // 0x96b0f70c...
+	headerE := &types.Header{Number: big.NewInt(4), Extra: []byte("E")} // 0xdc0acf54...
+
+	testCases := []struct {
+		tiebreak ForkTiebreak
+		current  *types.Header
+		incoming *types.Header
+		want     bool
+	}{
+		{ForkTiebreakHighestHash, headerD, headerE, true},
+		{ForkTiebreakHighestHash, headerE, headerD, false},
+		{ForkTiebreakLowestHash, headerD, headerE, false},
+		{ForkTiebreakLowestHash, headerE, headerD, true},
+		{ForkTiebreakFirstSeen, headerD, headerE, false},
+		{ForkTiebreakFirstSeen, headerE, headerD, false},
+	}
+
+	for _, tc := range testCases {
+		forker := NewForkChoice(mockChainReader, nil, nil)
+		forker.SetTiebreak(tc.tiebreak)
+
+		res, err := forker.ReorgNeeded(tc.current, tc.incoming)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, res, "tiebreak %s, current %x, incoming %x", tc.tiebreak, tc.current.Extra, tc.incoming.Extra)
+	}
+
+	_, err := ParseForkTiebreak("unknown")
+	require.Error(t, err)
+}
+
+func TestForkChoiceTrustedHeaders(t *testing.T) {
+	t.Parallel()
+
+	mockChainReader := newChainReaderFake(func(hash common.Hash, number uint64) *big.Int { return big.NewInt(1) })
+	mockChainValidator := newChainValidatorFake(func(currentHeader *types.Header, chain []*types.Header) (bool, error) {
+		return false, nil
+	})
+
+	forker := NewForkChoice(mockChainReader, nil, mockChainValidator)
+
+	current := &types.Header{Number: big.NewInt(1)}
+	chain := []*types.Header{{Number: big.NewInt(2)}, {Number: big.NewInt(3)}}
+
+	res, err := forker.ValidateReorg(current, chain)
+	require.NoError(t, err)
+	require.False(t, res)
+
+	// Chains made only of trusted headers skip the validator
+	forker.TrustHeaders(chain)
+
+	res, err = forker.ValidateReorg(current, chain)
+	require.NoError(t, err)
+	require.True(t, res)
+
+	res, err = forker.ValidateReorg(current, append(chain, &types.Header{Number: big.NewInt(4)}))
+	require.NoError(t, err)
+	require.False(t, res)
+}
+
 func TestPastChainInsert(t *testing.T) {
 	t.Parallel()
 
@@ -306,6 +375,7 @@ func (w *chainValidatorFake) GetWhitelistedMilestone() (bool, uint64, common.Has
 }
 func (w *chainValidatorFake) PurgeWhitelistedCheckpoint() {}
 func (w *chainValidatorFake) PurgeWhitelistedMilestone()  {}
+func (w *chainValidatorFake) PruneAbove(number uint64)    {}
 func (w *chainValidatorFake) GetCheckpoints(current, sidechainHeader *types.Header, sidechainCheckpoints []*types.Header) (map[uint64]*types.Header, error) {
 	return map[uint64]*types.Header{}, nil
 }
@@ -314,10 +384,34 @@ func (w *chainValidatorFake) LockMutex(endBlockNum uint64) bool {
 }
 func (w *chainValidatorFake) UnlockMutex(doLock bool, milestoneId string, endBlockNum uint64, endBlockHash common.Hash) {
 }
-func (w *chainValidatorFake) UnlockSprint(endBlockNum uint64) {
+func (w *chainValidatorFake) UnlockSprint(endBlockNum uint64) bool {
+	return false
+}
+func (w *chainValidatorFake) UnlockSprintUpTo(endBlockNum uint64) bool {
+	return false
 }
 func (w *chainValidatorFake) RemoveMilestoneID(milestoneId string) {
 }
 func (w *chainValidatorFake) GetMilestoneIDsList() []string {
 	return nil
 }
+func (w *chainValidatorFake) RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64) {
+}
+func (w *chainValidatorFake) GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64) {
+	return false, "", 0, 0
+}
+func (w *chainValidatorFake) SubscribeMilestoneIDListChange(ch chan<- int) ethereum.Subscription {
+	return nil
+}
+func (w *chainValidatorFake) GetFutureMilestones() ([]uint64, []common.Hash) {
+	return nil, nil
+}
+func (w *chainValidatorFake) GetLockedSprintInfo() (bool, uint64, common.Hash, []string) {
+	return false, 0, common.Hash{}, nil
+}
+func (w *chainValidatorFake) ExpireMilestoneIDs(before time.Time) []string {
+	return nil
+}
+func (w *chainValidatorFake) CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error {
+	return nil
+}
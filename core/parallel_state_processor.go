@@ -38,6 +38,11 @@ import (
 type ParallelEVMConfig struct {
 	Enable               bool
 	SpeculativeProcesses int
+
+	// SequentialToAddresses are hot contracts, whose transactions almost always
+	// conflict: they're executed one after the other instead of speculatively, to
+	// avoid their re-executions.
+	SequentialToAddresses []common.Address
 }
 
 // StateProcessor is a basic Processor, which takes care of transitioning
@@ -87,6 +92,7 @@ type ExecutionTask struct {
 	//                                       (0 -> delay is not allowed, 1 -> delay is allowed)
 	// next k elements in dependencies -> transaction indexes on which transaction i is dependent on
 	dependencies []int
+	sequential   bool // Whether the transaction is to a hot contract, executed after the preceding one
 	coinbase     common.Address
 	blockContext vm.BlockContext
 }
@@ -124,8 +130,8 @@ func (task *ExecutionTask) Execute(mvh *blockstm.MVHashMap, incarnation int) (er
 
 		reads := task.statedb.MVReadMap()
 
-		if _, ok := reads[blockstm.NewSubpathKey(task.blockContext.Coinbase, state.BalancePath)]; ok {
-			log.Info("Coinbase is in MVReadMap", "address", task.blockContext.Coinbase)
+		if _, ok := reads[blockstm.NewSubpathKey(task.coinbase, state.BalancePath)]; ok {
+			log.Info("Coinbase is in MVReadMap", "address", task.coinbase)
 
 			task.shouldRerunWithoutFeeDelay = true
 		}
@@ -173,6 +179,10 @@ func (task *ExecutionTask) Dependencies() []int {
 	return task.dependencies
 }
 
+func (task *ExecutionTask) Sequential() bool {
+	return task.sequential
+}
+
 func (task *ExecutionTask) Settle() {
 	task.finalStateDB.SetTxContext(task.tx.Hash(), task.index)
 
@@ -282,8 +292,6 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 
 	shouldDelayFeeCal := true
 
-	coinbase, _ := p.bc.Engine().Author(header)
-
 	blockTxDependency := block.GetTxDependency()
 
 	deps := GetDeps(blockTxDependency)
@@ -294,6 +302,14 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
 
+	// The fees are settled after the execution to the address credited with them
+	coinbase := blockContext.FeeBeneficiary()
+
+	sequentialTo := make(map[common.Address]struct{}, len(cfg.ParallelSequentialTo))
+	for _, addr := range cfg.ParallelSequentialTo {
+		sequentialTo[addr] = struct{}{}
+	}
+
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		msg, err := TransactionToMessage(tx, types.MakeSigner(p.config, header.Number, header.Time), header.BaseFee)
@@ -308,6 +324,11 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 			shouldDelayFeeCal = false
 		}
 
+		var sequential bool
+		if msg.To != nil {
+			_, sequential = sequentialTo[*msg.To]
+		}
+
 		if len(blockTxDependency) != len(block.Transactions()) {
 			task := &ExecutionTask{
 				msg:               *msg,
@@ -328,6 +349,7 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 				receipts:          &receipts,
 				allLogs:           &allLogs,
 				dependencies:      deps[i],
+				sequential:        sequential,
 				coinbase:          coinbase,
 				blockContext:      blockContext,
 			}
@@ -353,6 +375,7 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 				receipts:          &receipts,
 				allLogs:           &allLogs,
 				dependencies:      nil,
+				sequential:        sequential,
 				coinbase:          coinbase,
 				blockContext:      blockContext,
 			}
@@ -378,6 +401,8 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 		parallelizabilityTimer.Update(time.Duration(serialWeight * 100 / weight))
 	}
 
+	var feeDelayRerun bool
+
 	for _, task := range tasks {
 		task := task.(*ExecutionTask)
 		if task.shouldRerunWithoutFeeDelay {
@@ -398,7 +423,8 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 				t.totalUsedGas = usedGas
 			}
 
-			_, err = blockstm.ExecuteParallel(tasks, false, metadata, cfg.ParallelSpeculativeProcesses, interruptCtx)
+			result, err = blockstm.ExecuteParallel(tasks, false, metadata, cfg.ParallelSpeculativeProcesses, interruptCtx)
+			feeDelayRerun = true
 
 			break
 		}
@@ -408,6 +434,16 @@ func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.Stat
 		return nil, nil, 0, err
 	}
 
+	p.bc.updateParallelEVMStats(block, func(stats *ParallelEVMStats) {
+		stats.Transactions = result.Summary.Tasks
+		stats.Parallel = result.Summary.Parallel
+		stats.ReExecutions = result.Summary.Execs - result.Summary.Tasks
+		stats.Conflicts = result.Summary.Aborts + result.Summary.ValidationFailures
+		stats.Workers = result.Summary.Workers
+		stats.FeeDelayRerun = feeDelayRerun
+		stats.Executed = true
+	})
+
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), nil)
 
@@ -0,0 +1,56 @@
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// lastStateSyncKey tracks the last state-sync event applied by a written block.
+var lastStateSyncKey = []byte("LastStateSyncID")
+
+// StateSyncProgress is the last state-sync event applied, and the block which
+// applied it.
+type StateSyncProgress struct {
+	ID     uint64
+	Number uint64
+	Hash   common.Hash
+}
+
+// ReadLastStateSyncID retrieves the last state-sync event applied by a written
+// block, nil if none was recorded.
+func ReadLastStateSyncID(db ethdb.KeyValueReader) *StateSyncProgress {
+	data, _ := db.Get(lastStateSyncKey)
+	if len(data) == 0 {
+		return nil
+	}
+
+	progress := new(StateSyncProgress)
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		log.Error("Invalid last state-sync id", "err", err)
+		return nil
+	}
+
+	return progress
+}
+
+// WriteLastStateSyncID stores the last state-sync event applied, by the block of
+// the given number and hash.
+func WriteLastStateSyncID(db ethdb.KeyValueWriter, id uint64, number uint64, hash common.Hash) {
+	data, err := rlp.EncodeToBytes(&StateSyncProgress{ID: id, Number: number, Hash: hash})
+	if err != nil {
+		log.Crit("Failed to encode the last state-sync id", "err", err)
+	}
+
+	if err := db.Put(lastStateSyncKey, data); err != nil {
+		log.Crit("Failed to store the last state-sync id", "err", err)
+	}
+}
+
+// DeleteLastStateSyncID removes the last state-sync event applied.
+func DeleteLastStateSyncID(db ethdb.KeyValueWriter) {
+	if err := db.Delete(lastStateSyncKey); err != nil {
+		log.Crit("Failed to delete the last state-sync id", "err", err)
+	}
+}
@@ -0,0 +1,25 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLastStateSyncID(t *testing.T) {
+	t.Parallel()
+
+	db := NewMemoryDatabase()
+
+	if progress := ReadLastStateSyncID(db); progress != nil {
+		t.Fatalf("unexpected progress on an empty database: %+v", progress)
+	}
+
+	WriteLastStateSyncID(db, 10, 64, common.Hash{0x1})
+	WriteLastStateSyncID(db, 12, 80, common.Hash{0x2})
+
+	progress := ReadLastStateSyncID(db)
+	if progress == nil || progress.ID != 12 || progress.Number != 80 || progress.Hash != (common.Hash{0x2}) {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+}
@@ -2,6 +2,7 @@
 package rawdb
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	json "github.com/json-iterator/go"
@@ -16,6 +17,11 @@ var (
 	lastMilestone      = []byte("LastMilestone")
 	lockFieldKey       = []byte("LockField")
 	futureMilestoneKey = []byte("FutureMilestoneField")
+
+	milestoneHistoryPrefix = []byte("MilestoneHistory-") // milestoneHistoryPrefix + end block (uint64 big endian) -> milestone history entry
+
+	milestoneVotePrefix  = []byte("MilestoneVote-")    // milestoneVotePrefix + sequence number (uint64 big endian) -> milestone vote entry
+	milestoneVoteHeadKey = []byte("MilestoneVoteHead") // Sequence number of the next milestone vote
 )
 
 type Finality struct {
@@ -97,6 +103,19 @@ func WriteLastFinality[T BlockFinality[T]](db ethdb.KeyValueWriter, block uint64
 	return nil
 }
 
+// DeleteLastFinality removes the stored whitelisted checkpoint or milestone.
+func DeleteLastFinality[T BlockFinality[T]](db ethdb.KeyValueWriter) error {
+	_, key := getKey[T]()
+
+	if err := db.Delete(key); err != nil {
+		log.Error(fmt.Sprintf("Failed to delete the %s struct", string(key)), "err", err)
+
+		return fmt.Errorf("%w: %v for %s struct", ErrDBNotResponding, err, string(key))
+	}
+
+	return nil
+}
+
 type BlockFinality[T any] interface {
 	set(block uint64, hash common.Hash)
 	clone() T
@@ -219,3 +238,128 @@ func ReadFutureMilestoneList(db ethdb.KeyValueReader) ([]uint64, map[uint64]comm
 
 	return order, list, nil
 }
+
+// MilestoneHistoryEntry is a whitelisted milestone stored in the milestone history.
+type MilestoneHistoryEntry struct {
+	MilestoneID string      `json:"milestoneID"`
+	StartBlock  uint64      `json:"startBlock"`
+	EndBlock    uint64      `json:"endBlock"`
+	Hash        common.Hash `json:"hash"`
+	Reorg       bool        `json:"reorg"` // Whether the chain was rewound to match the milestone
+}
+
+func milestoneHistoryKey(endBlock uint64) []byte {
+	return append(append([]byte{}, milestoneHistoryPrefix...), encodeBlockNumber(endBlock)...)
+}
+
+func WriteMilestoneHistory(db ethdb.KeyValueWriter, entry *MilestoneHistoryEntry) error {
+	enc, err := json.Marshal(entry)
+	if err != nil {
+		log.Error("Failed to marshal the milestone history entry", "err", err)
+
+		return fmt.Errorf("%w: %v for milestone history entry", ErrIncorrectFinalityToStore, err)
+	}
+
+	if err := db.Put(milestoneHistoryKey(entry.EndBlock), enc); err != nil {
+		log.Error("Failed to store the milestone history entry", "err", err)
+
+		return fmt.Errorf("%w: %v for milestone history entry", ErrDBNotResponding, err)
+	}
+
+	return nil
+}
+
+// ReadMilestoneHistory returns up to limit milestones of the history, ordered by
+// end block, starting with the first one ending at or after the from block.
+func ReadMilestoneHistory(db ethdb.Iteratee, from uint64, limit int) ([]*MilestoneHistoryEntry, error) {
+	it := db.NewIterator(milestoneHistoryPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var entries []*MilestoneHistoryEntry
+
+	for len(entries) < limit && it.Next() {
+		entry := new(MilestoneHistoryEntry)
+		if err := json.Unmarshal(it.Value(), entry); err != nil {
+			return nil, fmt.Errorf("%w(%v) for milestone history entry %x", ErrIncorrectFinality, err, it.Key())
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, it.Error()
+}
+
+// MilestoneVoteEntry is a vote cast by the node on a proposed milestone.
+type MilestoneVoteEntry struct {
+	MilestoneID string `json:"milestoneID"`
+	StartBlock  uint64 `json:"startBlock"`
+	EndBlock    uint64 `json:"endBlock"`
+	RootHash    string `json:"rootHash"`  // Proposed root hash (or end block hash) voted on
+	VoteYes     bool   `json:"voteYes"`   // Whether the node agreed with the milestone
+	Timestamp   uint64 `json:"timestamp"` // Unix time of the vote
+}
+
+func milestoneVoteKey(seq uint64) []byte {
+	return append(append([]byte{}, milestoneVotePrefix...), encodeBlockNumber(seq)...)
+}
+
+func readMilestoneVoteHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(milestoneVoteHeadKey)
+	if len(data) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteMilestoneVote appends a vote to the milestone vote history, dropping the
+// oldest vote once the history holds more than limit votes. Concurrent writers
+// must be serialized by the caller.
+func WriteMilestoneVote(db ethdb.KeyValueStore, entry *MilestoneVoteEntry, limit uint64) error {
+	enc, err := json.Marshal(entry)
+	if err != nil {
+		log.Error("Failed to marshal the milestone vote entry", "err", err)
+
+		return fmt.Errorf("%w: %v for milestone vote entry", ErrIncorrectFinalityToStore, err)
+	}
+
+	seq := readMilestoneVoteHead(db)
+
+	batch := db.NewBatch()
+	_ = batch.Put(milestoneVoteKey(seq), enc)
+	_ = batch.Put(milestoneVoteHeadKey, encodeBlockNumber(seq+1))
+
+	if limit > 0 && seq >= limit {
+		_ = batch.Delete(milestoneVoteKey(seq - limit))
+	}
+
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to store the milestone vote entry", "err", err)
+
+		return fmt.Errorf("%w: %v for milestone vote entry", ErrDBNotResponding, err)
+	}
+
+	return nil
+}
+
+// ReadMilestoneVoteHistory returns up to limit votes of the milestone vote history,
+// newest first.
+func ReadMilestoneVoteHistory(db ethdb.KeyValueReader, limit int) ([]*MilestoneVoteEntry, error) {
+	var entries []*MilestoneVoteEntry
+
+	for seq := readMilestoneVoteHead(db); seq > 0 && len(entries) < limit; seq-- {
+		data, err := db.Get(milestoneVoteKey(seq - 1))
+		if err != nil || len(data) == 0 {
+			break
+		}
+
+		entry := new(MilestoneVoteEntry)
+		if err := json.Unmarshal(data, entry); err != nil {
+			return nil, fmt.Errorf("%w(%v) for milestone vote entry %d", ErrIncorrectFinality, err, seq-1)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
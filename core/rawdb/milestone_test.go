@@ -0,0 +1,114 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMilestoneHistory(t *testing.T) {
+	t.Parallel()
+
+	db := NewMemoryDatabase()
+
+	// Written out of order, read by end block
+	for _, end := range []uint64{300, 100, 200, 400} {
+		entry := &MilestoneHistoryEntry{
+			MilestoneID: common.Hash{byte(end / 100)}.Hex(),
+			StartBlock:  end - 99,
+			EndBlock:    end,
+			Hash:        common.Hash{byte(end / 100)},
+			Reorg:       end == 200,
+		}
+		if err := WriteMilestoneHistory(db, entry); err != nil {
+			t.Fatalf("failed to write milestone %d: %v", end, err)
+		}
+	}
+
+	tests := []struct {
+		from  uint64
+		limit int
+		ends  []uint64
+	}{
+		{0, 10, []uint64{100, 200, 300, 400}},
+		{150, 2, []uint64{200, 300}},
+		{300, 10, []uint64{300, 400}},
+		{401, 10, nil},
+	}
+
+	for _, tt := range tests {
+		entries, err := ReadMilestoneHistory(db, tt.from, tt.limit)
+		if err != nil {
+			t.Fatalf("from %d: failed to read the history: %v", tt.from, err)
+		}
+
+		if len(entries) != len(tt.ends) {
+			t.Fatalf("from %d: got %d milestones, want %d", tt.from, len(entries), len(tt.ends))
+		}
+
+		for i, entry := range entries {
+			if entry.EndBlock != tt.ends[i] || entry.StartBlock != tt.ends[i]-99 || entry.Hash != (common.Hash{byte(tt.ends[i] / 100)}) {
+				t.Fatalf("from %d: unexpected milestone %d: %+v", tt.from, i, entry)
+			}
+
+			if entry.Reorg != (entry.EndBlock == 200) {
+				t.Fatalf("from %d: unexpected reorg flag of milestone %d", tt.from, entry.EndBlock)
+			}
+		}
+	}
+}
+
+func TestMilestoneVoteHistory(t *testing.T) {
+	t.Parallel()
+
+	db := NewMemoryDatabase()
+
+	if entries, err := ReadMilestoneVoteHistory(db, 10); err != nil || len(entries) != 0 {
+		t.Fatalf("unexpected votes in an empty history: %v, %v", entries, err)
+	}
+
+	// Bounded to the 3 newest votes
+	for i := uint64(1); i <= 5; i++ {
+		entry := &MilestoneVoteEntry{
+			MilestoneID: common.Hash{byte(i)}.Hex(),
+			StartBlock:  i*100 - 99,
+			EndBlock:    i * 100,
+			RootHash:    common.Hash{byte(i)}.Hex(),
+			VoteYes:     i%2 == 0,
+			Timestamp:   i,
+		}
+		if err := WriteMilestoneVote(db, entry, 3); err != nil {
+			t.Fatalf("failed to write vote %d: %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		limit int
+		times []uint64
+	}{
+		{10, []uint64{5, 4, 3}},
+		{2, []uint64{5, 4}},
+		{0, nil},
+	}
+
+	for _, tt := range tests {
+		entries, err := ReadMilestoneVoteHistory(db, tt.limit)
+		if err != nil {
+			t.Fatalf("limit %d: failed to read the votes: %v", tt.limit, err)
+		}
+
+		if len(entries) != len(tt.times) {
+			t.Fatalf("limit %d: got %d votes, want %d", tt.limit, len(entries), len(tt.times))
+		}
+
+		for i, entry := range entries {
+			if entry.Timestamp != tt.times[i] || entry.EndBlock != tt.times[i]*100 || entry.VoteYes != (tt.times[i]%2 == 0) {
+				t.Fatalf("limit %d: unexpected vote %d: %+v", tt.limit, i, entry)
+			}
+		}
+	}
+
+	if has, _ := db.Has(milestoneVoteKey(1)); has {
+		t.Fatal("vote beyond the history bound not dropped")
+	}
+}
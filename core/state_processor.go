@@ -130,7 +130,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	// pause recording read and write
 	statedb.SetMVHashmap(nil)
 
-	coinbaseBalance := statedb.GetBalance(evm.Context.Coinbase)
+	coinbaseBalance := statedb.GetBalance(evm.Context.FeeBeneficiary())
 
 	// resume recording read and write
 	statedb.SetMVHashmap(backupMVHashMap)
@@ -148,7 +148,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	}
 
 	// TODO(raneet10) Double check
-	statedb.AddBalance(evm.Context.Coinbase, result.FeeTipped)
+	statedb.AddBalance(evm.Context.FeeBeneficiary(), result.FeeTipped)
 	output1 := new(big.Int).SetBytes(result.SenderInitBalance.Bytes())
 	output2 := new(big.Int).SetBytes(coinbaseBalance.Bytes())
 
@@ -158,7 +158,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 		statedb,
 
 		msg.From,
-		evm.Context.Coinbase,
+		evm.Context.FeeBeneficiary(),
 
 		result.FeeTipped,
 		result.SenderInitBalance,
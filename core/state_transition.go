@@ -391,7 +391,7 @@ func (st *StateTransition) TransitionDb(interruptCtx context.Context) (*Executio
 	var input2 *big.Int
 
 	if !st.noFeeBurnAndTip {
-		input2 = st.state.GetBalance(st.evm.Context.Coinbase)
+		input2 = st.state.GetBalance(st.evm.Context.FeeBeneficiary())
 	}
 	// First check this message satisfies all consensus rules before
 	// applying the message. The rules include these clauses
@@ -505,7 +505,7 @@ func (st *StateTransition) TransitionDb(interruptCtx context.Context) (*Executio
 	}
 
 	if !st.noFeeBurnAndTip {
-		st.state.AddBalance(st.evm.Context.Coinbase, amount)
+		st.state.AddBalance(st.evm.Context.FeeBeneficiary(), amount)
 
 		output1 := new(big.Int).SetBytes(input1.Bytes())
 		output2 := new(big.Int).SetBytes(input2.Bytes())
@@ -516,7 +516,7 @@ func (st *StateTransition) TransitionDb(interruptCtx context.Context) (*Executio
 			st.state,
 
 			msg.From,
-			st.evm.Context.Coinbase,
+			st.evm.Context.FeeBeneficiary(),
 
 			amount,
 			input1,
@@ -79,6 +79,18 @@ type BlockContext struct {
 	BaseFee       *big.Int       // Provides information for BASEFEE
 	Random        *common.Hash   // Provides information for PREVRANDAO
 	ExcessBlobGas *uint64        // ExcessBlobGas field in the header, needed to compute the data
+
+	FeeRecipient *common.Address // Credited with the transaction fees instead of Coinbase if set
+}
+
+// FeeBeneficiary returns the address credited with the transaction fees of the
+// block.
+func (ctx *BlockContext) FeeBeneficiary() common.Address {
+	if ctx.FeeRecipient != nil {
+		return *ctx.FeeRecipient
+	}
+
+	return ctx.Coinbase
 }
 
 // TxContext provides the EVM with information about a transaction.
@@ -56,6 +56,7 @@ type Config struct {
 	// parallel EVM configs
 	ParallelEnable               bool
 	ParallelSpeculativeProcesses int
+	ParallelSequentialTo         []common.Address // Transactions to these addresses are executed one after the other, as they're known to conflict
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -18,17 +18,27 @@ package eth
 
 import (
 	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/consensus/bor"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// heimdallClientPingTimeout is the time given to a new heimdall client to reach
+// heimdall before it's swapped in.
+const heimdallClientPingTimeout = 10 * time.Second
+
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
@@ -137,3 +147,128 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// borTrustPeerTTL is the time after which a peer trusted through BorTrustPeer
+// loses its trust, even if the chain didn't reach the requested block.
+const borTrustPeerTTL = time.Hour
+
+// BorTrustPeer lets the chain served by the given peer bypass the checkpoint and
+// milestone whitelist up to untilBlock, so that a chain stuck on a bad milestone
+// can be recovered without restarting the node. The trust lapses once the head
+// reaches untilBlock or after an hour, whichever comes first.
+//
+// This is dangerous: the blocks synced from the peer skip the whitelist
+// validation, so only use it for a surgical recovery.
+func (api *AdminAPI) BorTrustPeer(url string, untilBlock uint64) (bool, error) {
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+
+	if head := api.eth.BlockChain().CurrentBlock().Number.Uint64(); untilBlock <= head {
+		return false, fmt.Errorf("until block %d is not above the current head %d", untilBlock, head)
+	}
+
+	api.eth.Downloader().TrustPeer(node.ID().String(), untilBlock, time.Now().Add(borTrustPeerTTL))
+
+	return true, nil
+}
+
+// BorPauseStateSync pauses the state-sync for a bridge maintenance window. The
+// node stops sealing while it's paused, as a block sealed without the expected
+// state-sync events would be invalid.
+func (api *AdminAPI) BorPauseStateSync() (bool, error) {
+	bor, ok := api.eth.Engine().(*bor.Bor)
+	if !ok {
+		return false, errBorEngineNotAvailable
+	}
+
+	bor.PauseStateSync()
+
+	return true, nil
+}
+
+// BorResumeStateSync resumes the state-sync and the sealing paused by
+// BorPauseStateSync.
+func (api *AdminAPI) BorResumeStateSync() (bool, error) {
+	bor, ok := api.eth.Engine().(*bor.Bor)
+	if !ok {
+		return false, errBorEngineNotAvailable
+	}
+
+	bor.ResumeStateSync()
+
+	return true, nil
+}
+
+// BorWarmSignerCache recovers the signers of the blocks in [start, end] into the
+// signer cache of the engine, so that the author lookups over the range are fast,
+// e.g. before scanning the range for a validator activity report. The number of
+// concurrent recoveries and of consecutive blocks handed to each of them at once
+// are optional.
+func (api *AdminAPI) BorWarmSignerCache(start uint64, end uint64, workers *int, batch *int) (*bor.SignerCacheWarmup, error) {
+	engine, ok := api.eth.Engine().(*bor.Bor)
+	if !ok {
+		return nil, errBorEngineNotAvailable
+	}
+
+	var w, b int
+
+	if workers != nil {
+		w = *workers
+	}
+
+	if batch != nil {
+		b = *batch
+	}
+
+	return engine.WarmSignerCache(api.eth.BlockChain(), start, end, w, b)
+}
+
+// BorHeimdallClientSwap is the outcome of BorSetHeimdallClient.
+type BorHeimdallClientSwap struct {
+	Old *bor.HeimdallClientInfo `json:"old"`
+	New *bor.HeimdallClientInfo `json:"new"`
+}
+
+// BorSetHeimdallClient swaps the heimdall client of the engine at runtime for a
+// new client of the given type ("http" or "grpc") targeting the given endpoint,
+// e.g. to migrate to another heimdall or backend without restarting the node. The
+// new client is checked to reach heimdall before the swap, and is wrapped by the
+// same proxy, recording and concurrency limiting clients as the old one. The calls
+// in flight on the old client are allowed to finish, for a while, before it's
+// closed.
+func (api *AdminAPI) BorSetHeimdallClient(kind string, endpoint string) (*BorHeimdallClientSwap, error) {
+	engine, ok := api.eth.Engine().(*bor.Bor)
+	if !ok {
+		return nil, errBorEngineNotAvailable
+	}
+
+	client, err := ethconfig.NewHeimdallClient(kind, endpoint, api.eth.config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), heimdallClientPingTimeout)
+	defer cancel()
+
+	if _, err := client.FetchMilestoneCount(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("new heimdall client unreachable: %w", err)
+	}
+
+	old, err := engine.SwapHeimdallClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	swap := &BorHeimdallClientSwap{
+		Old: bor.DescribeHeimdallClient(old),
+		New: bor.DescribeHeimdallClient(client),
+	}
+
+	log.Warn("Swapped the heimdall client", "old", swap.Old.Type, "oldEndpoint", swap.Old.Endpoint, "new", swap.New.Type, "newEndpoint", swap.New.Endpoint)
+
+	return swap, nil
+}
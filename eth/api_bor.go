@@ -0,0 +1,447 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var errNoWhitelistedMilestone = errors.New("no milestone whitelisted")
+
+// maxMilestoneHistoryLimit is the maximum number of milestones returned by a
+// single GetMilestoneHistory call.
+const maxMilestoneHistoryLimit = 1000
+
+// BorAPI provides bor specific APIs which rely on the node's milestone and
+// checkpoint whitelist rather than on the consensus engine.
+type BorAPI struct {
+	eth *Ethereum
+}
+
+// NewBorAPI creates a new instance of BorAPI.
+func NewBorAPI(eth *Ethereum) *BorAPI {
+	return &BorAPI{eth: eth}
+}
+
+// MilestoneForBlock describes the whitelisted milestone covering a block.
+type MilestoneForBlock struct {
+	Number      uint64  `json:"number"`
+	Finalized   bool    `json:"finalized"`             // Whether the block is covered by a whitelisted milestone
+	MilestoneID string  `json:"milestoneID,omitempty"` // Id of the milestone covering the block, if still tracked
+	StartBlock  *uint64 `json:"startBlock,omitempty"`
+	EndBlock    *uint64 `json:"endBlock,omitempty"`
+}
+
+// GetMilestoneForBlock returns the whitelisted milestone whose range covers the
+// given block. Blocks above the latest whitelisted milestone aren't finalized.
+// Finalized blocks whose milestone is older than the tracked history are
+// reported without the milestone details.
+func (api *BorAPI) GetMilestoneForBlock(number uint64) *MilestoneForBlock {
+	validator := api.eth.Downloader().ChainValidator
+	res := &MilestoneForBlock{Number: number}
+
+	exists, latest, _ := validator.GetWhitelistedMilestone()
+	if !exists || number > latest {
+		return res
+	}
+
+	res.Finalized = true
+
+	if found, id, start, end := validator.GetMilestoneForBlock(number); found {
+		res.MilestoneID = id
+		res.StartBlock = &start
+		res.EndBlock = &end
+	}
+
+	return res
+}
+
+// PendingMilestone is a milestone received above the current head, waiting for
+// the chain to catch up to be whitelisted.
+type PendingMilestone struct {
+	EndBlock uint64      `json:"endBlock"`
+	Hash     common.Hash `json:"hash"`
+}
+
+// GetPendingMilestones returns the buffered future milestones, in the order they
+// were received.
+func (api *BorAPI) GetPendingMilestones() []PendingMilestone {
+	numbers, hashes := api.eth.Downloader().ChainValidator.GetFutureMilestones()
+
+	res := make([]PendingMilestone, len(numbers))
+	for i := range numbers {
+		res[i] = PendingMilestone{EndBlock: numbers[i], Hash: hashes[i]}
+	}
+
+	return res
+}
+
+// ActiveLock describes the sprint locked by the milestone voting, if any.
+type ActiveLock struct {
+	Locked       bool        `json:"locked"`
+	Number       uint64      `json:"number"`       // End block of the locked sprint
+	Hash         common.Hash `json:"hash"`         // Hash of the end block of the locked sprint
+	MilestoneID  string      `json:"milestoneID"`  // Id of the milestone holding the lock, the first one if several do
+	MilestoneIDs []string    `json:"milestoneIDs"` // Ids of all the milestones holding the lock
+}
+
+// GetActiveLock returns the sprint locked by the milestone voting and the ids of
+// the milestones holding the lock, letting a coordinator check whether its proposed
+// milestone took the lock. Locked is false, with the other fields zero, if no sprint
+// is locked.
+func (api *BorAPI) GetActiveLock() *ActiveLock {
+	locked, number, hash, ids := api.eth.Downloader().ChainValidator.GetLockedSprintInfo()
+	if !locked {
+		return &ActiveLock{MilestoneIDs: []string{}}
+	}
+
+	res := &ActiveLock{
+		Locked:       true,
+		Number:       number,
+		Hash:         hash,
+		MilestoneIDs: ids,
+	}
+
+	if len(ids) > 0 {
+		res.MilestoneID = ids[0]
+	}
+
+	return res
+}
+
+// ForkedPeer is a connected peer whose chain was refused for conflicting with the
+// whitelisted checkpoint or milestone.
+type ForkedPeer struct {
+	ID      string      `json:"id"`
+	Enode   string      `json:"enode"`
+	Head    common.Hash `json:"head"`    // Head advertised by the peer
+	Strikes uint64      `json:"strikes"` // Number of chains of the peer refused since it connected
+}
+
+// GetForkedPeers returns the connected peers whose chain was refused by the chain
+// validator for conflicting with the whitelisted checkpoint or milestone, the most
+// struck first.
+func (api *BorAPI) GetForkedPeers() []ForkedPeer {
+	res := []ForkedPeer{}
+
+	for id, strikes := range api.eth.Downloader().ForkStrikes() {
+		peer := api.eth.handler.peers.peer(id)
+		if peer == nil {
+			continue
+		}
+
+		head, _ := peer.Head()
+
+		res = append(res, ForkedPeer{
+			ID:      id,
+			Enode:   peer.Node().URLv4(),
+			Head:    head,
+			Strikes: strikes,
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Strikes != res[j].Strikes {
+			return res[i].Strikes > res[j].Strikes
+		}
+
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
+
+// SyncProgress is the sync progress of the node along with its finality progress.
+type SyncProgress struct {
+	Syncing       bool   `json:"syncing"`       // Whether the node is behind the highest block announced by its peers
+	StartingBlock uint64 `json:"startingBlock"` // Block the sync started from, only set while syncing
+	CurrentBlock  uint64 `json:"currentBlock"`  // Head of the chain
+	HighestBlock  uint64 `json:"highestBlock"`  // Highest block announced by the peers, the head if not syncing
+
+	Finalized       bool         `json:"finalized"`               // Whether a milestone was whitelisted yet
+	FinalizedBlock  uint64       `json:"finalizedBlock"`          // End block of the latest whitelisted milestone
+	FinalizedHash   *common.Hash `json:"finalizedHash,omitempty"` // Hash of the end block of the latest whitelisted milestone
+	LatestMilestone uint64       `json:"latestMilestone"`         // End block of the latest milestone received, whitelisted or still pending
+	FinalityGap     uint64       `json:"finalityGap"`             // Number of blocks of the chain above the finalized block
+	PendingGap      uint64       `json:"pendingGap,omitempty"`    // Number of blocks the head is behind the latest milestone received
+}
+
+// GetSyncProgress returns how far the node is from the tip of the chain, like
+// eth_syncing, and how far its head is from the finality of the milestones.
+func (api *BorAPI) GetSyncProgress() *SyncProgress {
+	var (
+		progress  = api.eth.Downloader().Progress()
+		validator = api.eth.Downloader().ChainValidator
+		head      = api.eth.BlockChain().CurrentBlock().Number.Uint64()
+	)
+
+	res := &SyncProgress{
+		CurrentBlock: head,
+		HighestBlock: head,
+	}
+
+	if progress.CurrentBlock < progress.HighestBlock {
+		res.Syncing = true
+		res.StartingBlock = progress.StartingBlock
+		res.HighestBlock = progress.HighestBlock
+	}
+
+	if exists, number, hash := validator.GetWhitelistedMilestone(); exists {
+		res.Finalized = true
+		res.FinalizedBlock = number
+		res.FinalizedHash = &hash
+		res.LatestMilestone = number
+
+		if head > number {
+			res.FinalityGap = head - number
+		}
+	}
+
+	// The milestones received above the head are pending until it catches up
+	numbers, _ := validator.GetFutureMilestones()
+	for _, number := range numbers {
+		if number > res.LatestMilestone {
+			res.LatestMilestone = number
+		}
+	}
+
+	if res.LatestMilestone > head {
+		res.PendingGap = res.LatestMilestone - head
+	}
+
+	return res
+}
+
+// BorStatus describes the state of the node's interactions with heimdall.
+type BorStatus struct {
+	LastMilestonePoll     uint64 `json:"lastMilestonePoll"`     // Unix time of the last milestone fetched from heimdall, 0 if none yet
+	MilestonePollInterval string `json:"milestonePollInterval"` // Interval between the milestone fetches
+	HeimdallClockSkew     string `json:"heimdallClockSkew"`     // Last measured skew of the local clock from heimdall's (positive if ahead), empty if not measured
+}
+
+// Status returns the state of the node's interactions with heimdall.
+func (api *BorAPI) Status() *BorStatus {
+	status := &BorStatus{
+		LastMilestonePoll:     uint64(api.eth.lastMilestonePoll.Load()),
+		MilestonePollInterval: api.eth.milestonePollInterval().String(),
+	}
+
+	if skew := api.eth.heimdallClockSkew.Load(); skew != nil {
+		status.HeimdallClockSkew = skew.String()
+	}
+
+	return status
+}
+
+// MilestoneRewind describes a rewind of the chain to the latest whitelisted milestone.
+type MilestoneRewind struct {
+	From uint64      `json:"from"` // Head before the rewind
+	To   uint64      `json:"to"`   // End block of the milestone, the head after the rewind
+	Hash common.Hash `json:"hash"`
+}
+
+// RewindToMilestone sets the head of the chain to the end block of the latest
+// whitelisted milestone, a known safe point to recover a node which forked above
+// the finality. The milestone locks taken above it are released, so that the node
+// doesn't refuse the canonical chain when syncing again. The chain is left as is
+// if the head isn't past the milestone.
+func (api *BorAPI) RewindToMilestone() (*MilestoneRewind, error) {
+	validator := api.eth.Downloader().ChainValidator
+
+	exists, number, hash := validator.GetWhitelistedMilestone()
+	if !exists {
+		return nil, errNoWhitelistedMilestone
+	}
+
+	chain := api.eth.BlockChain()
+
+	if local := chain.GetHeaderByNumber(number); local == nil || local.Hash() != hash {
+		return nil, fmt.Errorf("block %d of the milestone %s isn't in the canonical chain", number, hash)
+	}
+
+	res := &MilestoneRewind{
+		From: chain.CurrentBlock().Number.Uint64(),
+		To:   number,
+		Hash: hash,
+	}
+
+	if res.From <= number {
+		res.To = res.From
+		return res, nil
+	}
+
+	for _, id := range validator.GetMilestoneIDsList() {
+		validator.RemoveMilestoneID(id)
+	}
+
+	rewindBack(api.eth, res.From, number)
+
+	if head := chain.CurrentBlock(); head.Number.Uint64() != number {
+		return nil, fmt.Errorf("failed to rewind the chain to block %d, head is at %d", number, head.Number.Uint64())
+	}
+
+	return res, nil
+}
+
+// GetReorgStats returns the cumulative statistics of the chain reorgs since the
+// node started.
+func (api *BorAPI) GetReorgStats() core.ReorgStats {
+	return api.eth.BlockChain().ReorgStats()
+}
+
+// CompareForks returns which of the two heads the fork choice of the node prefers
+// and why: their total difficulties, their common ancestor and whether the
+// whitelisted milestone forces the choice. Head A is considered the current head.
+func (api *BorAPI) CompareForks(hashA common.Hash, hashB common.Hash) (*core.ForkComparison, error) {
+	chain := api.eth.BlockChain()
+
+	a := chain.GetHeaderByHash(hashA)
+	if a == nil {
+		return nil, fmt.Errorf("unknown block %s", hashA)
+	}
+
+	b := chain.GetHeaderByHash(hashB)
+	if b == nil {
+		return nil, fmt.Errorf("unknown block %s", hashB)
+	}
+
+	return chain.CompareForks(a, b)
+}
+
+// AlternativeHead is the best head advertised by the connected peers which isn't
+// part of the local canonical chain.
+type AlternativeHead struct {
+	Hash  common.Hash `json:"hash"`
+	TD    *big.Int    `json:"td"`
+	Peer  string      `json:"peer"` // ID of the peer advertising the head
+	Enode string      `json:"enode"`
+}
+
+// HeadScore is the total difficulty of the current head, and its margin over the
+// best alternative head known from the peers. A small or negative margin warns of
+// a contested head.
+type HeadScore struct {
+	Number      uint64           `json:"number"`
+	Hash        common.Hash      `json:"hash"`
+	TD          *big.Int         `json:"td"`
+	Alternative *AlternativeHead `json:"alternative"` // Nil if no peer advertises a competing head
+	Margin      *big.Int         `json:"margin"`      // TD of the head minus the one of the alternative, nil without alternative
+}
+
+// peerHead is the head advertised by a connected peer.
+type peerHead struct {
+	id    string
+	enode string
+	hash  common.Hash
+	td    *big.Int
+}
+
+// headScore scores the given head against the heads of the peers, the ones in the
+// local canonical chain (e.g. peers on the same chain, behind) not competing with it.
+func headScore(number uint64, hash common.Hash, td *big.Int, peers []peerHead, canonical func(hash common.Hash) bool) *HeadScore {
+	score := &HeadScore{Number: number, Hash: hash, TD: td}
+
+	for _, peer := range peers {
+		if peer.td == nil || peer.hash == hash || canonical(peer.hash) {
+			continue
+		}
+
+		if alt := score.Alternative; alt != nil && (peer.td.Cmp(alt.TD) < 0 || (peer.td.Cmp(alt.TD) == 0 && peer.id > alt.Peer)) {
+			continue
+		}
+
+		score.Alternative = &AlternativeHead{Hash: peer.hash, TD: peer.td, Peer: peer.id, Enode: peer.enode}
+	}
+
+	if score.Alternative != nil {
+		score.Margin = new(big.Int).Sub(td, score.Alternative.TD)
+	}
+
+	return score
+}
+
+// GetHeadScore returns the total difficulty of the current head and its margin
+// over the best competing head advertised by the connected peers, if any.
+func (api *BorAPI) GetHeadScore() (*HeadScore, error) {
+	chain := api.eth.BlockChain()
+
+	head := chain.CurrentBlock()
+
+	td := chain.GetTd(head.Hash(), head.Number.Uint64())
+	if td == nil {
+		return nil, fmt.Errorf("missing total difficulty of the head %s", head.Hash())
+	}
+
+	var peers []peerHead
+
+	for _, peer := range api.eth.handler.peers.allPeers() {
+		hash, td := peer.Head()
+		peers = append(peers, peerHead{id: peer.ID(), enode: peer.Node().URLv4(), hash: hash, td: td})
+	}
+
+	canonical := func(hash common.Hash) bool {
+		number := rawdb.ReadHeaderNumber(api.eth.ChainDb(), hash)
+		return number != nil && *number <= head.Number.Uint64() && chain.GetCanonicalHash(*number) == hash
+	}
+
+	return headScore(head.Number.Uint64(), head.Hash(), td, peers, canonical), nil
+}
+
+// GetMilestoneHistory returns up to limit whitelisted milestones from the stored
+// history, ordered by end block and starting with the first one ending at or after
+// the given block. Along with the Milestones subscription, it lets an indexer
+// backfill the milestones and then follow the new ones.
+func (api *BorAPI) GetMilestoneHistory(fromNumber uint64, limit int) ([]*rawdb.MilestoneHistoryEntry, error) {
+	if limit <= 0 || limit > maxMilestoneHistoryLimit {
+		limit = maxMilestoneHistoryLimit
+	}
+
+	entries, err := rawdb.ReadMilestoneHistory(api.eth.ChainDb(), fromNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []*rawdb.MilestoneHistoryEntry{}
+	}
+
+	return entries, nil
+}
+
+// Milestones creates a subscription notified of every newly whitelisted milestone,
+// advancing the finalized block.
+func (api *BorAPI) Milestones(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		milestones := make(chan core.MilestoneEvent, 16)
+		milestonesSub := api.eth.SubscribeMilestoneEvent(milestones)
+
+		defer milestonesSub.Unsubscribe()
+
+		for {
+			select {
+			case m := <-milestones:
+				_ = notifier.Notify(rpcSub.ID, m)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/bor"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -432,3 +434,202 @@ func (api *DebugAPI) SetTrieFlushInterval(interval string) error {
 func (api *DebugAPI) GetTrieFlushInterval() string {
 	return api.eth.blockchain.GetTrieFlushInterval().String()
 }
+
+// borReplayStorageLimit is the maximum number of storage slots of each state
+// receiver contract returned by BorReplayStateSync.
+const borReplayStorageLimit = 1024
+
+// BorStateSyncReplay is the outcome of BorReplayStateSync, along with the
+// resulting storage of the state receiver contracts the events were committed to.
+type BorStateSyncReplay struct {
+	*bor.StateSyncReplay
+	Storage map[common.Address]StorageRangeResult `json:"storage"`
+}
+
+// BorReplayStateSync replays the state-sync events due by the end block which
+// weren't applied yet at the start block on top of an isolated copy of the start
+// state, and returns the applied events, the failed ones and the resulting storage
+// of the state receiver contracts they were committed to. The chain isn't modified.
+func (api *DebugAPI) BorReplayStateSync(ctx context.Context, start uint64, end uint64) (*BorStateSyncReplay, error) {
+	engine, ok := api.eth.Engine().(*bor.Bor)
+	if !ok {
+		return nil, errBorEngineNotAvailable
+	}
+
+	if end <= start {
+		return nil, fmt.Errorf("end block %d must be after start block %d", end, start)
+	}
+
+	chain := api.eth.BlockChain()
+
+	startHeader := chain.GetHeaderByNumber(start)
+	if startHeader == nil {
+		return nil, fmt.Errorf("block %d not found", start)
+	}
+
+	endHeader := chain.GetHeaderByNumber(end)
+	if endHeader == nil {
+		return nil, fmt.Errorf("block %d not found", end)
+	}
+
+	statedb, err := chain.StateAt(startHeader.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := engine.ReplayStateSync(ctx, chain, statedb, startHeader, endHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BorStateSyncReplay{StateSyncReplay: replay, Storage: make(map[common.Address]StorageRangeResult, len(replay.Receivers))}
+
+	for _, receiver := range replay.Receivers {
+		st, err := statedb.StorageTrie(receiver)
+		if err != nil {
+			return nil, err
+		}
+
+		storage := StorageRangeResult{Storage: storageMap{}}
+
+		if st != nil {
+			if storage, err = storageRangeAt(st, nil, borReplayStorageLimit); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Storage[receiver] = storage
+	}
+
+	return result, nil
+}
+
+// BorProcessedMilestone is the outcome of BorProcessMilestone.
+type BorProcessedMilestone struct {
+	Number        uint64      `json:"number"`
+	Hash          common.Hash `json:"hash"`
+	MilestoneID   string      `json:"milestoneID,omitempty"`
+	Reorg         bool        `json:"reorg"`               // Whether the local chain conflicted with the milestone and was rewound
+	RewoundTo     *uint64     `json:"rewoundTo,omitempty"` // Block the chain was rewound to, if rewound
+	Finalized     uint64      `json:"finalized"`           // Finalized block once the milestone is processed
+	FinalizedHash common.Hash `json:"finalizedHash"`
+}
+
+// BorProcessMilestone whitelists the milestone ending at the given block with the
+// given hash, as if it was fetched from heimdall, and returns the new finalized
+// block. If the local block at that height has a different hash, the chain is
+// rewound below it for the downloader to fetch the chain of the milestone.
+//
+// This is for testing and recovery only: the milestone isn't checked against
+// heimdall, the source of truth of the finality.
+func (api *DebugAPI) BorProcessMilestone(number uint64, hash common.Hash, milestoneID string) (*BorProcessedMilestone, error) {
+	if _, ok := api.eth.Engine().(*bor.Bor); !ok {
+		return nil, errBorEngineNotAvailable
+	}
+
+	if hash == (common.Hash{}) {
+		return nil, errors.New("empty milestone hash")
+	}
+
+	var (
+		chain     = api.eth.BlockChain()
+		validator = api.eth.Downloader().ChainValidator
+		result    = &BorProcessedMilestone{Number: number, Hash: hash, MilestoneID: milestoneID}
+	)
+
+	exists, prevNumber, _ := validator.GetWhitelistedMilestone()
+
+	if err := checkProcessedMilestone(exists, prevNumber, number); err != nil {
+		return nil, err
+	}
+
+	log.Warn("Processing a milestone bypassing heimdall, for testing and recovery only", "number", number, "hash", hash, "milestoneID", milestoneID)
+
+	if header := chain.GetHeaderByNumber(number); header != nil && header.Hash() != hash {
+		head := chain.CurrentBlock().Number.Uint64()
+		rewindTo := processedMilestoneRewind(head, number)
+
+		log.Warn("Rewinding the chain conflicting with the processed milestone", "number", rewindTo, "local", header.Hash())
+
+		rewindBack(api.eth, head, rewindTo)
+
+		result.Reorg = true
+		result.RewoundTo = &rewindTo
+	}
+
+	validator.ProcessMilestone(number, hash)
+
+	if milestoneID != "" {
+		start := number
+		if exists && prevNumber < number {
+			start = prevNumber + 1
+		}
+
+		validator.RecordMilestone(milestoneID, start, number)
+	}
+
+	_, result.Finalized, result.FinalizedHash = validator.GetWhitelistedMilestone()
+
+	log.Warn("Processed a milestone bypassing heimdall", "number", number, "hash", hash, "reorg", result.Reorg, "finalized", result.Finalized)
+
+	return result, nil
+}
+
+// checkProcessedMilestone checks that the milestone ending at number can be
+// processed, the finality of the whitelisted milestone not being revertible.
+func checkProcessedMilestone(exists bool, whitelisted uint64, number uint64) error {
+	if exists && number < whitelisted {
+		return fmt.Errorf("milestone %d is below the whitelisted milestone %d", number, whitelisted)
+	}
+
+	return nil
+}
+
+// processedMilestoneRewind returns the block the chain at head is rewound to when
+// it conflicts with the milestone ending at number: the block below the milestone,
+// but at most maxRewindDepth blocks below the head, like borVerify.
+func processedMilestoneRewind(head uint64, number uint64) uint64 {
+	var rewindTo uint64
+	if number > 0 {
+		rewindTo = number - 1
+	}
+
+	if head > rewindTo && head-rewindTo > maxRewindDepth {
+		rewindTo = head - maxRewindDepth
+	}
+
+	return rewindTo
+}
+
+// BorGetParallelEVMStats returns the statistics of the parallel execution of the
+// given block, retained for the recent blocks imported with the parallel EVM
+// enabled. Blocks mined locally aren't executed by the parallel EVM.
+func (api *DebugAPI) BorGetParallelEVMStats(blockNr rpc.BlockNumber) (*core.ParallelEVMStats, error) {
+	if !api.eth.config.ParallelEVM.Enable {
+		return nil, errors.New("parallel EVM is disabled")
+	}
+
+	var header *types.Header
+
+	switch blockNr {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		header = api.eth.blockchain.CurrentBlock()
+	case rpc.FinalizedBlockNumber:
+		header = api.eth.blockchain.CurrentFinalBlock()
+	case rpc.SafeBlockNumber:
+		header = api.eth.blockchain.CurrentSafeBlock()
+	default:
+		header = api.eth.blockchain.GetHeaderByNumber(uint64(blockNr))
+	}
+
+	if header == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+
+	stats := api.eth.blockchain.ParallelEVMStats(header.Hash())
+	if stats == nil {
+		return nil, fmt.Errorf("no parallel execution statistics for block #%d", header.Number)
+	}
+
+	return stats, nil
+}
@@ -227,3 +227,37 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessedMilestone(t *testing.T) {
+	t.Parallel()
+
+	// Milestones below the whitelisted one are refused
+	if err := checkProcessedMilestone(true, 100, 99); err == nil {
+		t.Fatalf("milestone below the whitelisted one accepted")
+	}
+
+	for _, number := range []uint64{100, 101} {
+		if err := checkProcessedMilestone(true, 100, number); err != nil {
+			t.Fatalf("milestone %d refused: %v", number, err)
+		}
+	}
+
+	if err := checkProcessedMilestone(false, 0, 1); err != nil {
+		t.Fatalf("milestone refused without a whitelisted one: %v", err)
+	}
+
+	// The chain is rewound below the milestone, by at most maxRewindDepth blocks
+	tests := []struct {
+		head, number, want uint64
+	}{
+		{head: 100, number: 90, want: 89},
+		{head: 100, number: 0, want: 0},
+		{head: 1000, number: 500, want: 1000 - maxRewindDepth},
+		{head: 50, number: 80, want: 79},
+	}
+	for _, test := range tests {
+		if rewindTo := processedMilestoneRewind(test.head, test.number); rewindTo != test.want {
+			t.Fatalf("head %d, milestone %d: rewound to %d, expected %d", test.head, test.number, rewindTo, test.want)
+		}
+	}
+}
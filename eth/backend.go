@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/bor"
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -110,6 +112,21 @@ type Ethereum struct {
 	closeCh chan struct{} // Channel to signal the background processes to exit
 
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
+
+	milestoneFeed    event.Feed // Feed of the whitelisted milestones
+	milestoneRewound bool       // Whether the chain was rewound on a milestone mismatch since the last whitelisted milestone
+
+	pendingMilestones []*milestone.Milestone        // Verified milestones waiting for enough confirmations to be whitelisted, oldest first
+	lastMilestonePoll atomic.Int64                  // Unix time of the last milestone fetched from heimdall
+	heimdallClockSkew atomic.Pointer[time.Duration] // Last measured skew of the local clock from heimdall's, nil if none yet
+
+	milestoneMissingDataPolicy milestoneMissingDataPolicy // Behaviour of the milestone verification when the end block isn't available
+	milestoneConflict          *milestoneConflict         // Milestone conflicting with the local chain, nil if none
+	milestoneOverlapPolicy     milestoneOverlapPolicy     // Behaviour of the milestone verification when a milestone disagrees with the whitelisted one over their overlap
+	milestoneSnapSyncPolicy    milestoneSnapSyncPolicy    // Behaviour of the milestone processing while the snap sync is in progress
+
+	milestoneStartupPolicy  milestoneStartupPolicy // Reconciliation of the persisted milestone with heimdall's latest one on startup
+	persistedMilestoneFloor uint64                 // Persisted milestone kept on startup, heimdall's milestones below it are ignored (0 = none)
 }
 
 // New creates a new Ethereum object (including the
@@ -220,6 +237,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			ParallelSequentialTo:    config.ParallelEVM.SequentialToAddresses,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -247,6 +265,38 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
+	// Keep the block of the locked sprint from being pruned while the lock is held
+	checker.SetBlockPinner(eth.blockchain)
+
+	forkTiebreak, err := core.ParseForkTiebreak(config.BorForkTiebreak)
+	if err != nil {
+		return nil, err
+	}
+
+	eth.milestoneMissingDataPolicy, err = parseMilestoneMissingDataPolicy(config.BorMilestoneVerifyMissingDataPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	eth.milestoneOverlapPolicy, err = parseMilestoneOverlapPolicy(config.BorMilestoneOverlapPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	eth.milestoneSnapSyncPolicy, err = parseMilestoneSnapSyncPolicy(config.BorMilestoneDuringSnapSync)
+	if err != nil {
+		return nil, err
+	}
+
+	eth.milestoneStartupPolicy, err = parseMilestoneStartupPolicy(config.BorMilestoneStartupPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	eth.blockchain.SetForkTiebreak(forkTiebreak)
+	eth.blockchain.SetPersistStateSyncProgress(config.BorPersistStateSyncProgress)
+	eth.blockchain.SetPruneMilestonesOnSetHead(config.BorPruneMilestonesOnSetHead)
+
 	_ = eth.engine.VerifyHeader(eth.blockchain, eth.blockchain.CurrentHeader()) // TODO think on it
 
 	// BOR changes
@@ -381,6 +431,9 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
+		}, {
+			Namespace: "bor",
+			Service:   NewBorAPI(s),
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
@@ -623,6 +676,21 @@ func (s *Ethereum) Start() error {
 	go s.startNoAckMilestoneService()
 	go s.startNoAckMilestoneByIDService()
 
+	if engine, ok := s.engine.(*bor.Bor); ok {
+		go s.startMilestoneGapService()
+		go s.startSealingMonitor(engine)
+	}
+
+	if s.config.BorMilestoneIDTTL > 0 {
+		go s.startMilestoneIDReaper()
+	}
+
+	// The clock skew is measured against the heimdall REST server only
+	if _, ok := s.engine.(*bor.Bor); ok && s.config.BorMaxHeimdallClockSkew > 0 && !s.config.WithoutHeimdall &&
+		s.config.HeimdallgRPCAddress == "" && !(s.config.RunHeimdall && s.config.UseHeimdallApp) {
+		go s.startHeimdallClockSkewCheck()
+	}
+
 	return nil
 }
 
@@ -634,6 +702,9 @@ var (
 const (
 	whitelistTimeout      = 30 * time.Second
 	noAckMilestoneTimeout = 4 * time.Second
+
+	defaultMilestonePollInterval = 12 * time.Second
+	minMilestonePollInterval     = time.Second
 )
 
 // StartCheckpointWhitelistService starts the goroutine to fetch checkpoints and update the
@@ -650,12 +721,118 @@ func (s *Ethereum) startCheckpointWhitelistService() {
 // startMilestoneWhitelistService starts the goroutine to fetch milestiones and update the
 // milestone whitelist map.
 func (s *Ethereum) startMilestoneWhitelistService() {
-	const (
-		tickerDuration = 12 * time.Second
-		fnName         = "whitelist milestone"
-	)
+	const fnName = "whitelist milestone"
+
+	tickerDuration := s.milestonePollInterval()
+	if tickerDuration != s.config.BorMilestonePollInterval && s.config.BorMilestonePollInterval != 0 {
+		log.Warn("Milestone poll interval too low, using the minimum", "interval", s.config.BorMilestonePollInterval, "minimum", tickerDuration)
+	}
+
+	s.retryHeimdallHandler(s.withStartupMilestoneReconciliation(s.handleMilestone), tickerDuration, whitelistTimeout, fnName)
+}
+
+// milestonePollInterval returns the interval between the milestone fetches, the
+// default one if not configured and not lower than the minimum.
+func (s *Ethereum) milestonePollInterval() time.Duration {
+	switch interval := s.config.BorMilestonePollInterval; {
+	case interval == 0:
+		return defaultMilestonePollInterval
+	case interval < minMilestonePollInterval:
+		return minMilestonePollInterval
+	default:
+		return interval
+	}
+}
+
+// startMilestoneIDReaper periodically drops the milestone ids which locked a sprint
+// more than BorMilestoneIDTTL ago, without being confirmed or rejected by heimdall,
+// unlocking the sprint once none is left.
+func (s *Ethereum) startMilestoneIDReaper() {
+	ttl := s.config.BorMilestoneIDTTL
+
+	// Check twice per ttl, within sane bounds
+	interval := ttl / 2
+	if interval < time.Second {
+		interval = time.Second
+	} else if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			expired := s.handler.downloader.ExpireMilestoneIDs(time.Now().Add(-ttl))
+			if len(expired) == 0 {
+				continue
+			}
+
+			milestoneIDExpiredMeter.Mark(int64(len(expired)))
+			log.Warn("Dropped milestone ids left unconfirmed by heimdall", "ids", expired, "ttl", ttl)
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+// milestoneGapWarnPeriod is the time the gap between the head and the latest
+// milestone has to stay over the threshold before being warned about, and the
+// interval between the following warnings.
+const milestoneGapWarnPeriod = time.Minute
+
+// startMilestoneGapService tracks the gap between the head and the end block of the
+// latest whitelisted milestone at every new head, and warns if it stays over
+// BorMilestoneGapWarnThreshold blocks, as a sign of the finality stalling.
+func (s *Ethereum) startMilestoneGapService() {
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+
+	defer sub.Unsubscribe()
+
+	var over, warned time.Time
 
-	s.retryHeimdallHandler(s.handleMilestone, tickerDuration, whitelistTimeout, fnName)
+	for {
+		select {
+		case ev := <-headCh:
+			exists, end, _ := s.handler.downloader.GetWhitelistedMilestone()
+			if !exists {
+				continue
+			}
+
+			var (
+				head = ev.Block.NumberU64()
+				gap  uint64
+			)
+
+			if head > end {
+				gap = head - end
+			}
+
+			milestoneHeadGapGauge.Update(int64(gap))
+
+			threshold := s.config.BorMilestoneGapWarnThreshold
+			if threshold == 0 || gap <= threshold {
+				over = time.Time{}
+				continue
+			}
+
+			now := time.Now()
+			if over.IsZero() {
+				over = now
+			}
+
+			if now.Sub(over) >= milestoneGapWarnPeriod && now.Sub(warned) >= milestoneGapWarnPeriod {
+				log.Warn("Head too far ahead of the latest milestone, finality may be stalling", "head", head, "milestone", end, "gap", gap, "threshold", threshold, "since", common.PrettyAge(over))
+				warned = now
+			}
+		case <-sub.Err():
+			return
+		case <-s.closeCh:
+			return
+		}
+	}
 }
 
 func (s *Ethereum) startNoAckMilestoneService() {
@@ -748,28 +925,153 @@ type heimdallHandler func(ctx context.Context, ethHandler *ethHandler, bor *bor.
 func (s *Ethereum) handleMilestone(ctx context.Context, ethHandler *ethHandler, bor *bor.Bor) error {
 	// Create a new bor verifier, which will be used to verify checkpoints and milestones
 	verifier := newBorVerifier()
-	num, hash, err := ethHandler.fetchWhitelistMilestone(ctx, bor, s, verifier)
+	if s.config.BorMilestoneFetchTimeout > 0 {
+		verifier.verify = s.withMilestoneFetchTimeout(verifier.verify, s.config.BorMilestoneFetchTimeout)
+	}
+
+	verifier.verify = s.withMilestoneOverlapCheck(verifier.verify)
+	verifier.verify = s.withPersistedMilestoneFloor(verifier.verify)
+	verifier.verify = s.withMilestoneSnapSyncPolicy(verifier.verify)
+
+	tracer := s.newMilestoneTracer()
+	ctx = withMilestoneTracer(ctx, tracer)
+
+	fetched, err := ethHandler.fetchWhitelistMilestone(ctx, bor, s, verifier)
 
 	// If the current chain head is behind the received milestone, add it to the future milestone
 	// list. Also, the hash mismatch (end block hash) error will lead to rewind so also
 	// add that milestone to the future milestone list.
 	if errors.Is(err, errMissingBlocks) || errors.Is(err, errHashMismatch) {
-		ethHandler.downloader.ProcessFutureMilestone(num, hash)
+		ethHandler.downloader.ProcessFutureMilestone(fetched.EndBlock.Uint64(), fetched.Hash)
+		tracer.trace("queued as a future milestone")
 	}
 
-	if errors.Is(err, heimdall.ErrServiceUnavailable) {
+	if errors.Is(err, errHashMismatch) {
+		s.milestoneRewound = true
+
+		if s.config.BorEagerMilestoneResync {
+			(*handler)(ethHandler).eagerMilestoneResync(fetched.EndBlock.Uint64(), fetched.Hash)
+			tracer.trace("eager resync requested")
+		}
+	}
+
+	if fetched != nil {
+		s.lastMilestonePoll.Store(time.Now().Unix())
+	}
+
+	if errors.Is(err, heimdall.ErrServiceUnavailable) || errors.Is(err, errMilestoneBelowPersisted) ||
+		errors.Is(err, errMilestoneDeferredSnapSync) || errors.Is(err, errMilestoneSkippedSnapSync) {
 		return nil
 	}
 
 	if err != nil {
+		tracer.trace("not whitelisted", "err", err)
 		return err
 	}
 
-	ethHandler.downloader.ProcessMilestone(num, hash)
+	confirmed := s.confirmMilestone(fetched)
+	tracer.trace("confirmation", "required", s.config.BorMilestoneConfirmations, "pending", len(s.pendingMilestones), "confirmed", len(confirmed))
+
+	for _, milestone := range confirmed {
+		locked, lockedNumber, lockedHash, _ := ethHandler.downloader.GetLockedSprintInfo()
+		tracer.trace("lock before whitelisting", "whitelisting", milestone.EndBlock, "locked", locked, "lockedNumber", lockedNumber, "lockedHash", lockedHash)
+
+		s.whitelistMilestone(ethHandler, milestone)
+
+		locked, lockedNumber, lockedHash, _ = ethHandler.downloader.GetLockedSprintInfo()
+		tracer.trace("whitelisted", "whitelisted", milestone.EndBlock, "hash", milestone.Hash, "locked", locked, "lockedNumber", lockedNumber, "lockedHash", lockedHash)
+	}
 
 	return nil
 }
 
+// confirmMilestone queues a verified milestone until enough newer milestones were
+// verified after it, and returns the milestones which got confirmed, oldest first.
+// The pending milestones are dropped if one of them no longer matches the local
+// chain, as it means heimdall revised them.
+func (s *Ethereum) confirmMilestone(fetched *milestone.Milestone) []*milestone.Milestone {
+	confirmations := int(s.config.BorMilestoneConfirmations)
+	if confirmations <= 1 {
+		return []*milestone.Milestone{fetched}
+	}
+
+	if n := len(s.pendingMilestones); n > 0 {
+		last := s.pendingMilestones[n-1]
+
+		// The same milestone fetched again doesn't confirm anything
+		if fetched.EndBlock.Cmp(last.EndBlock) == 0 && fetched.Hash == last.Hash {
+			return nil
+		}
+
+		if fetched.EndBlock.Cmp(last.EndBlock) <= 0 || !s.isCanonicalMilestones(s.pendingMilestones) {
+			log.Warn("Dropping the pending milestones, not confirmed by the latest one", "pending", n, "end", fetched.EndBlock, "hash", fetched.Hash)
+
+			s.pendingMilestones = nil
+		}
+	}
+
+	s.pendingMilestones = append(s.pendingMilestones, fetched)
+
+	if len(s.pendingMilestones) < confirmations {
+		return nil
+	}
+
+	n := len(s.pendingMilestones) - confirmations + 1
+	confirmed := s.pendingMilestones[:n:n]
+	s.pendingMilestones = append([]*milestone.Milestone(nil), s.pendingMilestones[n:]...)
+
+	return confirmed
+}
+
+// isCanonicalMilestones reports whether the end blocks of all the given milestones
+// are still part of the canonical chain.
+func (s *Ethereum) isCanonicalMilestones(milestones []*milestone.Milestone) bool {
+	for _, m := range milestones {
+		header := s.blockchain.GetHeaderByNumber(m.EndBlock.Uint64())
+		if header == nil || header.Hash() != m.Hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+// whitelistMilestone whitelists a verified (and confirmed) milestone.
+func (s *Ethereum) whitelistMilestone(ethHandler *ethHandler, milestone *milestone.Milestone) {
+	exists, prevNumber, _ := ethHandler.downloader.GetWhitelistedMilestone()
+
+	ethHandler.downloader.ProcessMilestone(milestone.EndBlock.Uint64(), milestone.Hash)
+	ethHandler.downloader.RecordMilestone(milestone.MilestoneID, milestone.StartBlock.Uint64(), milestone.EndBlock.Uint64())
+
+	// Announce the milestone only if it advances the finalized block. The rewind
+	// (if any) has already completed, as it's done while verifying the milestone.
+	if !exists || milestone.EndBlock.Uint64() > prevNumber {
+		// Keep the milestone in the history served to the indexers
+		_ = rawdb.WriteMilestoneHistory(s.chainDb, &rawdb.MilestoneHistoryEntry{
+			MilestoneID: milestone.MilestoneID,
+			StartBlock:  milestone.StartBlock.Uint64(),
+			EndBlock:    milestone.EndBlock.Uint64(),
+			Hash:        milestone.Hash,
+			Reorg:       s.milestoneRewound,
+		})
+
+		s.milestoneFeed.Send(core.MilestoneEvent{
+			Number:      milestone.EndBlock.Uint64(),
+			Hash:        milestone.Hash,
+			MilestoneID: milestone.MilestoneID,
+			Reorg:       s.milestoneRewound,
+		})
+
+		s.milestoneRewound = false
+	}
+}
+
+// SubscribeMilestoneEvent registers a subscription of MilestoneEvent, fired
+// whenever a new milestone is whitelisted.
+func (s *Ethereum) SubscribeMilestoneEvent(ch chan<- core.MilestoneEvent) event.Subscription {
+	return s.milestoneFeed.Subscribe(ch)
+}
+
 func (s *Ethereum) handleNoAckMilestone(ctx context.Context, ethHandler *ethHandler, bor *bor.Bor) error {
 	milestoneID, err := ethHandler.fetchNoAckMilestone(ctx, bor)
 
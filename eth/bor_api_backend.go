@@ -41,6 +41,17 @@ func (b *EthAPIBackend) GetRootHash(ctx context.Context, starBlockNr uint64, end
 
 // GetRootHash returns root hash for given start and end block
 func (b *EthAPIBackend) GetVoteOnHash(ctx context.Context, starBlockNr uint64, endBlockNr uint64, hash string, milestoneId string) (bool, error) {
+	vote, err := b.getVoteOnHash(ctx, starBlockNr, endBlockNr, hash, milestoneId)
+
+	// Keep the vote in the history audited through bor_getMilestoneVoteHistory
+	if engine, ok := b.eth.Engine().(*bor.Bor); ok {
+		engine.RecordMilestoneVote(milestoneId, starBlockNr, endBlockNr, hash, vote)
+	}
+
+	return vote, err
+}
+
+func (b *EthAPIBackend) getVoteOnHash(ctx context.Context, starBlockNr uint64, endBlockNr uint64, hash string, milestoneId string) (bool, error) {
 	var api *bor.API
 
 	for _, _api := range b.eth.Engine().APIs(b.eth.BlockChain()) {
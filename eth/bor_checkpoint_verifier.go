@@ -5,7 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth/downloader/whitelist"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -33,12 +36,252 @@ var (
 	// errBlockNumberConversion is returned when we get err in parsing hexautil block number
 	errBlockNumberConversion = errors.New("failed to parse the block number")
 
+	// errMilestoneFetchFailed is returned when the node gave up fetching the chain
+	// of a milestone conflicting with the local chain.
+	errMilestoneFetchFailed = errors.New("finality fetch failed")
+
+	// errMilestoneDeferredSnapSync is returned when a milestone is buffered until the
+	// snap sync completes, instead of being verified.
+	errMilestoneDeferredSnapSync = errors.New("milestone deferred until the snap sync completes")
+
+	// errMilestoneSkippedSnapSync is returned when a milestone is ignored as the snap
+	// sync is in progress.
+	errMilestoneSkippedSnapSync = errors.New("milestone skipped during the snap sync")
+
 	//Metrics for collecting the rewindLength
 	rewindLengthMeter = metrics.NewRegisteredMeter("chain/autorewind/length", nil)
+
+	// Metric for the milestones whose chain the node gave up fetching
+	milestoneFetchFailedMeter = metrics.NewRegisteredMeter("chain/milestone/fetchfailed", nil)
+
+	// Metric for the eager resyncs triggered by a milestone conflicting with the local chain
+	milestoneEagerResyncMeter = metrics.NewRegisteredMeter("chain/milestone/eagerresync", nil)
+
+	// Metric for the gap between the head and the end block of the latest whitelisted milestone
+	milestoneHeadGapGauge = metrics.NewRegisteredGauge("bor/milestone/headGap", nil)
+
+	// Metric for the milestone ids dropped after BorMilestoneIDTTL without being confirmed
+	milestoneIDExpiredMeter = metrics.NewRegisteredMeter("chain/milestone/idexpired", nil)
+
+	// Metric for the last measured skew of the local clock from heimdall's, in milliseconds
+	heimdallClockSkewGauge = metrics.NewRegisteredGauge("bor/heimdall/clockskew", nil)
+
+	// Metric for the measures of the clock skew from heimdall exceeding BorMaxHeimdallClockSkew
+	heimdallClockSkewExceededMeter = metrics.NewRegisteredMeter("bor/heimdall/clockskewexceeded", nil)
+
+	// Metric for the blocks the node was the in-turn proposer of but didn't seal
+	sealingMissedSlotMeter = metrics.NewRegisteredMeter("bor/sealing/missedslot", nil)
+)
+
+// maxRewindDepth is the maximum number of blocks the chain is rewound by when it
+// conflicts with a checkpoint or a milestone.
+const maxRewindDepth = 255
+
+// milestoneMissingDataPolicy is the behaviour of the milestone verification when
+// the end block of the milestone isn't available locally while the head is past it.
+type milestoneMissingDataPolicy string
+
+const (
+	// milestoneMissingDataDefer keeps the milestone pending until the block is
+	// available (default).
+	milestoneMissingDataDefer milestoneMissingDataPolicy = "defer"
+
+	// milestoneMissingDataTrust whitelists the milestone without verifying it.
+	milestoneMissingDataTrust milestoneMissingDataPolicy = "trust"
+)
+
+// parseMilestoneMissingDataPolicy parses a milestone missing data policy, the
+// empty string selects the default one.
+func parseMilestoneMissingDataPolicy(s string) (milestoneMissingDataPolicy, error) {
+	switch milestoneMissingDataPolicy(s) {
+	case "", milestoneMissingDataDefer:
+		return milestoneMissingDataDefer, nil
+	case milestoneMissingDataTrust:
+		return milestoneMissingDataTrust, nil
+	}
+
+	return "", fmt.Errorf("unknown milestone missing data policy %q", s)
+}
+
+// milestoneOverlapPolicy is the behaviour of the milestone verification when a
+// milestone covering the whitelisted milestone disagrees with it over the overlap.
+type milestoneOverlapPolicy string
+
+const (
+	// milestoneOverlapReject rejects the milestone, as heimdall revised the
+	// finalized history (default).
+	milestoneOverlapReject milestoneOverlapPolicy = "reject"
+
+	// milestoneOverlapAccept whitelists the milestone anyway, logging the conflict.
+	milestoneOverlapAccept milestoneOverlapPolicy = "accept"
+)
+
+// parseMilestoneOverlapPolicy parses a milestone overlap policy, the empty string
+// selects the default one.
+func parseMilestoneOverlapPolicy(s string) (milestoneOverlapPolicy, error) {
+	switch milestoneOverlapPolicy(s) {
+	case "", milestoneOverlapReject:
+		return milestoneOverlapReject, nil
+	case milestoneOverlapAccept:
+		return milestoneOverlapAccept, nil
+	}
+
+	return "", fmt.Errorf("unknown milestone overlap policy %q", s)
+}
+
+// milestoneSnapSyncPolicy is the behaviour of the milestone processing while the
+// snap sync is in progress, the blocks of the milestones not being available to
+// verify them.
+type milestoneSnapSyncPolicy string
+
+const (
+	// milestoneSnapSyncDefer buffers the milestone as a future milestone, verified
+	// once the snap sync completes (default).
+	milestoneSnapSyncDefer milestoneSnapSyncPolicy = "defer"
+
+	// milestoneSnapSyncSkip ignores the milestone.
+	milestoneSnapSyncSkip milestoneSnapSyncPolicy = "skip"
 )
 
+// parseMilestoneSnapSyncPolicy parses a milestone snap sync policy, the empty
+// string selects the default one.
+func parseMilestoneSnapSyncPolicy(s string) (milestoneSnapSyncPolicy, error) {
+	switch milestoneSnapSyncPolicy(s) {
+	case "", milestoneSnapSyncDefer:
+		return milestoneSnapSyncDefer, nil
+	case milestoneSnapSyncSkip:
+		return milestoneSnapSyncSkip, nil
+	}
+
+	return "", fmt.Errorf("unknown milestone snap sync policy %q", s)
+}
+
+// withMilestoneSnapSyncPolicy wraps the verification of the milestones to handle
+// them as per the snap sync policy while the snap sync is in progress, rather than
+// failing to verify them or whitelisting them unverified.
+func (s *Ethereum) withMilestoneSnapSyncPolicy(verify verifyFn) verifyFn {
+	return func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		if isCheckpoint || handler == nil || !handler.snapSync.Load() {
+			return verify(ctx, eth, handler, start, end, hash, isCheckpoint)
+		}
+
+		if s.milestoneSnapSyncPolicy == milestoneSnapSyncSkip {
+			log.Debug("Skipping milestone, snap sync in progress", "start", start, "end", end, "hash", hash)
+			milestoneTracerFrom(ctx).trace("skipped, snap sync in progress")
+
+			return hash, errMilestoneSkippedSnapSync
+		}
+
+		log.Info("Deferring milestone until the snap sync completes", "start", start, "end", end, "hash", hash)
+		milestoneTracerFrom(ctx).trace("deferred, snap sync in progress")
+
+		return hash, fmt.Errorf("%w: %w", errMilestoneDeferredSnapSync, errMissingBlocks)
+	}
+}
+
+// withMilestoneOverlapCheck wraps the verification of the milestones to check a
+// verified milestone covering the whitelisted milestone against it, through the
+// local chain of the new milestone. If they agree the milestone extends the
+// whitelist as usual, otherwise it's handled as per the overlap policy.
+func (s *Ethereum) withMilestoneOverlapCheck(verify verifyFn) verifyFn {
+	return func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		localHash, err := verify(ctx, eth, handler, start, end, hash, isCheckpoint)
+		if err != nil || isCheckpoint {
+			return localHash, err
+		}
+
+		// The milestone may have been trusted without its end block
+		header := s.blockchain.GetHeader(common.HexToHash(localHash), end)
+		if header == nil {
+			return localHash, nil
+		}
+
+		hashAt := func(number uint64) common.Hash {
+			ancestor := header
+			for ancestor != nil && ancestor.Number.Uint64() > number {
+				ancestor = s.blockchain.GetHeader(ancestor.ParentHash, ancestor.Number.Uint64()-1)
+			}
+
+			if ancestor == nil {
+				return common.Hash{}
+			}
+
+			return ancestor.Hash()
+		}
+
+		err = handler.downloader.CheckMilestoneOverlap(start, end, hashAt)
+		milestoneTracerFrom(ctx).trace("overlap check", "policy", s.milestoneOverlapPolicy, "err", err)
+		if errors.Is(err, whitelist.ErrMilestoneOverlapConflict) && s.milestoneOverlapPolicy == milestoneOverlapAccept {
+			log.Warn("Accepting the milestone conflicting with the whitelisted milestone", "start", start, "end", end, "hash", localHash, "err", err)
+			return localHash, nil
+		}
+
+		if err != nil {
+			log.Error("Rejecting the milestone conflicting with the whitelisted milestone", "start", start, "end", end, "hash", localHash, "err", err)
+			return hash, err
+		}
+
+		return localHash, nil
+	}
+}
+
+// milestoneConflict is a milestone whose end block hash didn't match the local
+// chain, tracked until the local chain adopts it or heimdall moves on.
+type milestoneConflict struct {
+	end     uint64
+	hash    string
+	since   time.Time
+	givenUp bool
+}
+
+// withMilestoneFetchTimeout wraps the verification of the milestones to give up on
+// a milestone conflicting with the local chain, if the chain of the milestone
+// couldn't be fetched within the timeout. Each hash mismatch rewinds the chain for
+// the downloader to fetch the chain of the milestone, which goes on forever if no
+// peer has it. Once given up, the milestone isn't verified any more (so the chain
+// isn't rewound), until a different milestone is fetched.
+func (s *Ethereum) withMilestoneFetchTimeout(verify verifyFn, timeout time.Duration) verifyFn {
+	return func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		if isCheckpoint {
+			return verify(ctx, eth, handler, start, end, hash, isCheckpoint)
+		}
+
+		conflict := s.milestoneConflict
+		if conflict != nil && (conflict.end != end || conflict.hash != hash) {
+			conflict, s.milestoneConflict = nil, nil
+		}
+
+		if conflict != nil && conflict.givenUp {
+			milestoneTracerFrom(ctx).trace("conflict already given up, skipped")
+			return hash, errMilestoneFetchFailed
+		}
+
+		localHash, err := verify(ctx, eth, handler, start, end, hash, isCheckpoint)
+
+		switch {
+		case err == nil:
+			s.milestoneConflict = nil
+		case errors.Is(err, errHashMismatch) && conflict == nil:
+			s.milestoneConflict = &milestoneConflict{end: end, hash: hash, since: time.Now()}
+		case conflict != nil && time.Since(conflict.since) >= timeout:
+			conflict.givenUp = true
+			milestoneFetchFailedMeter.Mark(1)
+
+			log.Error("Finality fetch failed, giving up on the milestone conflicting with the local chain", "end", end, "hash", hash, "conflicting", time.Since(conflict.since).Round(time.Second), "timeout", timeout)
+			milestoneTracerFrom(ctx).trace("conflict given up", "conflicting", time.Since(conflict.since).Round(time.Second), "timeout", timeout)
+
+			return hash, errMilestoneFetchFailed
+		}
+
+		return localHash, err
+	}
+}
+
+// verifyFn verifies a checkpoint or milestone against the local chain.
+type verifyFn func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error)
+
 type borVerifier struct {
-	verify func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error)
+	verify verifyFn
 }
 
 func newBorVerifier() *borVerifier {
@@ -51,6 +294,8 @@ func borVerify(ctx context.Context, eth *Ethereum, handler *ethHandler, start ui
 		str = "checkpoint"
 	}
 
+	tracer := milestoneTracerFrom(ctx)
+
 	// check if we have the given blocks
 	currentBlock := eth.BlockChain().CurrentBlock()
 	if currentBlock == nil {
@@ -62,9 +307,13 @@ func borVerify(ctx context.Context, eth *Ethereum, handler *ethHandler, start ui
 
 	if head < end {
 		log.Debug(fmt.Sprintf("Current head block behind incoming %s block", str), "head", head, "end block", end)
+		tracer.trace("head behind the end block, deferred", "head", head)
+
 		return hash, errMissingBlocks
 	}
 
+	tracer.trace("head past the end block", "head", head)
+
 	var localHash string
 
 	// verify the hash
@@ -76,19 +325,36 @@ func borVerify(ctx context.Context, eth *Ethereum, handler *ethHandler, start ui
 
 		if err != nil {
 			log.Debug("Failed to get root hash of given block range while whitelisting checkpoint", "start", start, "end", end, "err", err)
+			tracer.trace("root hash unavailable", "err", err)
+
 			return hash, errRootHash
 		}
+
+		tracer.trace("root hash computed", "root", localHash)
 	} else {
 		// in case of milestone(isCheckpoint==false) get the hash of endBlock
 		block, err := handler.ethAPI.GetBlockByNumber(ctx, rpc.BlockNumber(end), false)
-		if err != nil {
+		if err != nil || block == nil {
 			log.Debug("Failed to get end block hash while whitelisting milestone", "number", end, "err", err)
-			return hash, errEndBlock
+
+			tracer.trace("end block unavailable", "policy", eth.milestoneMissingDataPolicy, "err", err)
+
+			switch eth.milestoneMissingDataPolicy {
+			case milestoneMissingDataTrust:
+				log.Warn("Whitelisting milestone without verification, its end block isn't available", "number", end, "hash", hash)
+				return "0x" + hash, nil
+			default:
+				return hash, fmt.Errorf("%w: end block %d isn't available", errMissingBlocks, end)
+			}
 		}
 
 		localHash = fmt.Sprintf("%v", block["hash"])[2:]
+
+		tracer.trace("end block found", "local", "0x"+localHash)
 	}
 
+	tracer.trace("hash comparison", "local", localHash, "remote", hash, "match", localHash == hash)
+
 	//nolint
 	if localHash != hash {
 
@@ -117,10 +383,18 @@ func borVerify(ctx context.Context, eth *Ethereum, handler *ethHandler, start ui
 			}
 		}
 
-		if head-rewindTo > 255 {
-			rewindTo = head - 255
+		basis := "start"
+		if doExist {
+			basis = "whitelisted finality"
+		}
+
+		if head-rewindTo > maxRewindDepth {
+			rewindTo = head - maxRewindDepth
+			basis = "maximum rewind depth"
 		}
 
+		tracer.trace("reorg computed", "head", head, "rewindTo", rewindTo, "basis", basis)
+
 		if isCheckpoint {
 			log.Warn("Rewinding chain due to checkpoint root hash mismatch", "number", rewindTo)
 		} else {
@@ -136,11 +410,15 @@ func borVerify(ctx context.Context, eth *Ethereum, handler *ethHandler, start ui
 	block, err := handler.ethAPI.GetBlockByNumber(ctx, rpc.BlockNumber(end), false)
 	if err != nil {
 		log.Debug("Failed to get end block hash while whitelisting", "err", err)
+		tracer.trace("end block lookup failed", "err", err)
+
 		return hash, errEndBlock
 	}
 
 	hash = fmt.Sprintf("%v", block["hash"])
 
+	tracer.trace("verified", "hash", hash)
+
 	return hash, nil
 }
 
@@ -0,0 +1,69 @@
+package eth
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// heimdallClockSkewInterval is the interval between the measures of the skew of
+// the local clock from heimdall's.
+const heimdallClockSkewInterval = time.Minute
+
+// clockSkewMeasurer measures the skew of the local clock from heimdall's.
+type clockSkewMeasurer interface {
+	ClockSkew(ctx context.Context) (time.Duration, error)
+}
+
+// checkHeimdallClockSkew measures the skew of the local clock from heimdall's,
+// warning if it exceeds BorMaxHeimdallClockSkew. It's best-effort, a failed
+// measure being ignored.
+func (s *Ethereum) checkHeimdallClockSkew(ctx context.Context, client clockSkewMeasurer) {
+	skew, err := client.ClockSkew(ctx)
+	if err != nil {
+		log.Debug("Failed to measure the clock skew from heimdall", "err", err)
+		return
+	}
+
+	s.heimdallClockSkew.Store(&skew)
+	heimdallClockSkewGauge.Update(skew.Milliseconds())
+
+	if limit := s.config.BorMaxHeimdallClockSkew; skew > limit || skew < -limit {
+		heimdallClockSkewExceededMeter.Mark(1)
+		log.Warn("Local clock skewed from heimdall's, check the time synchronization", "skew", skew, "max", limit)
+	}
+}
+
+// startHeimdallClockSkewCheck periodically measures the skew of the local clock
+// from the heimdall REST server until shutdown, independently of the chain
+// processing.
+func (s *Ethereum) startHeimdallClockSkewCheck() {
+	client := heimdall.NewHeimdallClient(s.config.HeimdallURL)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.closeCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(heimdallClockSkewInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkHeimdallClockSkew(ctx, client)
+
+		select {
+		case <-ticker.C:
+		case <-s.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errMilestoneBelowPersisted is returned when a milestone fetched from heimdall is
+// ignored, as it's below the persisted milestone kept on startup.
+var errMilestoneBelowPersisted = errors.New("milestone below the persisted one")
+
+// milestoneStartupPolicy is the reconciliation of the milestone persisted by the
+// previous run with the latest milestone of heimdall, done on startup.
+type milestoneStartupPolicy string
+
+const (
+	// milestoneStartupHeimdall adopts heimdall's milestone, rewinding the chain if
+	// it conflicts with it.
+	milestoneStartupHeimdall milestoneStartupPolicy = "heimdall"
+
+	// milestoneStartupPersisted keeps the persisted milestone, ignoring heimdall's
+	// older milestones until heimdall catches up.
+	milestoneStartupPersisted milestoneStartupPolicy = "persisted"
+
+	// milestoneStartupNewest keeps the newest of the two milestones, the persisted
+	// one only if it still matches the local chain (default).
+	milestoneStartupNewest milestoneStartupPolicy = "newest"
+)
+
+// parseMilestoneStartupPolicy parses a milestone startup policy, the empty string
+// selects the default one.
+func parseMilestoneStartupPolicy(s string) (milestoneStartupPolicy, error) {
+	switch milestoneStartupPolicy(s) {
+	case "", milestoneStartupNewest:
+		return milestoneStartupNewest, nil
+	case milestoneStartupHeimdall, milestoneStartupPersisted:
+		return milestoneStartupPolicy(s), nil
+	}
+
+	return "", fmt.Errorf("unknown milestone startup policy %q", s)
+}
+
+// persistedMilestone is the milestone whitelisted by the previous run.
+type persistedMilestone struct {
+	number   uint64
+	hash     common.Hash
+	verified bool // Whether its end block is part of the local chain
+}
+
+// adoptHeimdallMilestone reports whether the latest milestone of heimdall is to be
+// adopted over the persisted one under the given policy, and why.
+func adoptHeimdallMilestone(policy milestoneStartupPolicy, persisted *persistedMilestone, fetched *milestone.Milestone) (bool, string) {
+	end := fetched.EndBlock.Uint64()
+
+	switch {
+	case persisted == nil:
+		return true, "no persisted milestone"
+	case persisted.number == end && persisted.hash == fetched.Hash:
+		return false, "same milestone"
+	case policy == milestoneStartupHeimdall:
+		return true, "heimdall trusted"
+	case policy == milestoneStartupPersisted:
+		return false, "persisted milestone trusted"
+	case !persisted.verified:
+		return true, "persisted milestone not on the local chain"
+	case end >= persisted.number:
+		return true, "heimdall's milestone is newer"
+	}
+
+	return false, "persisted milestone is newer"
+}
+
+// withStartupMilestoneReconciliation wraps the milestone handler to reconcile the
+// persisted milestone with heimdall's latest one before the first milestone is
+// handled, retrying while heimdall is unavailable.
+func (s *Ethereum) withStartupMilestoneReconciliation(handle heimdallHandler) heimdallHandler {
+	reconciled := false
+
+	return func(ctx context.Context, ethHandler *ethHandler, bor *bor.Bor) error {
+		if reconciled {
+			return handle(ctx, ethHandler, bor)
+		}
+
+		err := s.reconcileStartupMilestone(ctx, ethHandler, bor)
+		if errors.Is(err, heimdall.ErrServiceUnavailable) {
+			return nil
+		}
+
+		reconciled = true
+
+		if err != nil {
+			log.Warn("Failed to reconcile the persisted milestone with heimdall", "err", err)
+		}
+
+		err = handle(ctx, ethHandler, bor)
+
+		if exists, number, hash := ethHandler.downloader.GetWhitelistedMilestone(); exists {
+			log.Info("Finalized block after the startup milestone reconciliation", "number", number, "hash", hash)
+		}
+
+		return err
+	}
+}
+
+// reconcileStartupMilestone compares the persisted milestone with heimdall's latest
+// one and applies the configured policy. A newer milestone of heimdall is left to
+// the regular milestone handling, which verifies it and rewinds the chain if needed.
+func (s *Ethereum) reconcileStartupMilestone(ctx context.Context, ethHandler *ethHandler, bor *bor.Bor) error {
+	fetched, err := bor.HeimdallClient.FetchMilestone(ctx)
+	if err != nil {
+		return err
+	}
+
+	var persisted *persistedMilestone
+
+	if exists, number, hash := ethHandler.downloader.GetWhitelistedMilestone(); exists {
+		header := s.blockchain.GetHeaderByNumber(number)
+
+		persisted = &persistedMilestone{
+			number:   number,
+			hash:     hash,
+			verified: header != nil && header.Hash() == hash,
+		}
+	}
+
+	adopt, reason := adoptHeimdallMilestone(s.milestoneStartupPolicy, persisted, fetched)
+
+	end := fetched.EndBlock.Uint64()
+
+	if persisted == nil {
+		log.Info("No persisted milestone to reconcile with heimdall", "heimdall", end, "hash", fetched.Hash)
+		return nil
+	}
+
+	decision := "persisted"
+	if adopt {
+		decision = "heimdall"
+	}
+
+	log.Info("Reconciling the persisted milestone with heimdall", "policy", s.milestoneStartupPolicy, "decision", decision, "reason", reason,
+		"persisted", persisted.number, "persistedHash", persisted.hash, "verified", persisted.verified, "heimdall", end, "heimdallHash", fetched.Hash)
+
+	if !adopt {
+		if end < persisted.number {
+			s.persistedMilestoneFloor = persisted.number
+		}
+
+		return nil
+	}
+
+	// The regular milestone handling only moves the finalized block forward safely
+	if end >= persisted.number {
+		return nil
+	}
+
+	// Heimdall's milestone is older than the persisted one, so the chain is rewound
+	// below it if it conflicts with it, for the downloader to fetch heimdall's chain
+	if header := s.blockchain.GetHeaderByNumber(end); header != nil && header.Hash() != fetched.Hash {
+		rewindTo := uint64(0)
+		if start := fetched.StartBlock.Uint64(); start > 0 {
+			rewindTo = start - 1
+		}
+
+		log.Warn("Rewinding the chain conflicting with heimdall's milestone", "number", rewindTo)
+
+		s.milestoneRewound = true
+		rewindBack(s, s.blockchain.CurrentBlock().Number.Uint64(), rewindTo)
+	}
+
+	s.whitelistMilestone(ethHandler, fetched)
+
+	return nil
+}
+
+// withPersistedMilestoneFloor wraps the verification of the milestones to ignore the
+// milestones of heimdall below the persisted milestone kept on startup, until
+// heimdall catches up with it.
+func (s *Ethereum) withPersistedMilestoneFloor(verify verifyFn) verifyFn {
+	return func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		if !isCheckpoint && s.persistedMilestoneFloor > 0 {
+			if end < s.persistedMilestoneFloor {
+				milestoneTracerFrom(ctx).trace("below the persisted milestone, ignored", "persisted", s.persistedMilestoneFloor)
+				return hash, errMilestoneBelowPersisted
+			}
+
+			log.Info("Heimdall caught up with the persisted milestone", "persisted", s.persistedMilestoneFloor, "heimdall", end)
+
+			s.persistedMilestoneFloor = 0
+		}
+
+		return verify(ctx, eth, handler, start, end, hash, isCheckpoint)
+	}
+}
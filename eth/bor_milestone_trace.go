@@ -0,0 +1,73 @@
+package eth
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// milestoneTracer logs every decision taken while processing a single milestone,
+// as a correlated set of logs tagged with the milestone id and numbered in order.
+// A nil tracer logs nothing, so that the processing doesn't need to check whether
+// tracing is enabled.
+type milestoneTracer struct {
+	id         string
+	start, end uint64
+	step       int
+	began      time.Time
+}
+
+// milestoneTracerKey is the context key of the milestone tracer.
+type milestoneTracerKey struct{}
+
+// newMilestoneTracer returns the tracer of the milestone about to be processed,
+// nil if BorTraceMilestoneProcessing isn't set.
+func (s *Ethereum) newMilestoneTracer() *milestoneTracer {
+	if !s.config.BorTraceMilestoneProcessing {
+		return nil
+	}
+
+	return &milestoneTracer{began: time.Now()}
+}
+
+// withMilestoneTracer returns a copy of the context carrying the tracer.
+func withMilestoneTracer(ctx context.Context, t *milestoneTracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, milestoneTracerKey{}, t)
+}
+
+// milestoneTracerFrom returns the tracer carried by the context, nil if none.
+func milestoneTracerFrom(ctx context.Context) *milestoneTracer {
+	t, _ := ctx.Value(milestoneTracerKey{}).(*milestoneTracer)
+	return t
+}
+
+// fetched tags the following logs with the fetched milestone.
+func (t *milestoneTracer) fetched(m *milestone.Milestone) {
+	if t == nil {
+		return
+	}
+
+	t.id, t.start, t.end = m.MilestoneID, m.StartBlock.Uint64(), m.EndBlock.Uint64()
+
+	t.trace("fetched", "hash", m.Hash)
+}
+
+// trace logs a decision of the processing along with its context.
+func (t *milestoneTracer) trace(decision string, ctx ...interface{}) {
+	if t == nil {
+		return
+	}
+
+	t.step++
+
+	ctx = append([]interface{}{"milestoneID", t.id, "step", t.step, "decision", decision, "start", t.start, "end", t.end}, ctx...)
+	ctx = append(ctx, "elapsed", time.Since(t.began).Round(time.Microsecond))
+
+	log.Info("Milestone trace", ctx...)
+}
@@ -0,0 +1,155 @@
+package eth
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// missedSlotGrace is the time past the due time of a block the node is the in-turn
+// proposer of after which the slot is considered missed, if no block was imported.
+const missedSlotGrace = 2 * time.Second
+
+// sealingMonitor detects the blocks the node was the in-turn proposer of but
+// didn't seal, either because no block was imported in time or because the block
+// imported was sealed by a backup. It's driven by the new heads and a timer armed
+// for the due time of the next in-turn slot.
+type sealingMonitor struct {
+	inTurnSlot     func(parent *types.Header) (*bor.SealingSlot, error)
+	author         func(header *types.Header) (common.Address, error)
+	headerByNumber func(number uint64) *types.Header
+	mining         func() bool
+	restart        func() // Restarts the miner, nil if the automatic recovery is disabled
+
+	slot      *bor.SealingSlot // Next in-turn slot, nil if none
+	reported  bool             // Whether the missed slot was already reported
+	recovered bool             // Whether the miner was restarted since the last block sealed
+}
+
+// newHead checks the pending slot against the new head, and returns the time the
+// next in-turn slot is due at, zero if the node isn't its proposer.
+func (m *sealingMonitor) newHead(head *types.Header, now time.Time) time.Time {
+	number := head.Number.Uint64()
+
+	if m.slot != nil && number >= m.slot.Number {
+		if header := m.headerByNumber(m.slot.Number); header != nil {
+			author, err := m.author(header)
+			if err == nil && author == m.slot.Signer {
+				m.recovered = false
+			} else if err == nil && !m.reported {
+				m.missed(m.slot, "sealed by a backup")
+			}
+		}
+
+		m.slot = nil
+	}
+
+	if !m.mining() {
+		return time.Time{}
+	}
+
+	slot, err := m.inTurnSlot(head)
+	if err != nil {
+		log.Debug("Failed to find the next in-turn slot", "number", number+1, "err", err)
+		return time.Time{}
+	}
+
+	if slot == nil {
+		return time.Time{}
+	}
+
+	// A stale head (e.g. while syncing) isn't worth monitoring
+	due := time.Unix(int64(slot.Due), 0)
+	if now.After(due.Add(missedSlotGrace)) {
+		return time.Time{}
+	}
+
+	m.slot, m.reported = slot, false
+
+	return due.Add(missedSlotGrace)
+}
+
+// timeout reports the pending slot as missed if no block was imported for it.
+func (m *sealingMonitor) timeout(head uint64) {
+	if m.slot == nil || m.reported || head >= m.slot.Number {
+		return
+	}
+
+	m.missed(m.slot, "no block imported")
+}
+
+// missed raises the alert for a missed slot, restarting the miner once until a
+// block is sealed again if the automatic recovery is enabled.
+func (m *sealingMonitor) missed(slot *bor.SealingSlot, reason string) {
+	m.reported = true
+
+	sealingMissedSlotMeter.Mark(1)
+	log.Error("Missed an in-turn sealing slot", "number", slot.Number, "signer", slot.Signer, "due", time.Unix(int64(slot.Due), 0), "reason", reason)
+
+	if m.restart == nil || m.recovered {
+		return
+	}
+
+	m.recovered = true
+
+	log.Warn("Restarting the miner to recover the sealing", "number", slot.Number)
+	m.restart()
+}
+
+// startSealingMonitor runs the sealing monitor at every new head until shutdown.
+func (s *Ethereum) startSealingMonitor(engine *bor.Bor) {
+	monitor := &sealingMonitor{
+		inTurnSlot: func(parent *types.Header) (*bor.SealingSlot, error) {
+			return engine.InTurnSlot(s.blockchain, parent)
+		},
+		author:         engine.Author,
+		headerByNumber: s.blockchain.GetHeaderByNumber,
+		mining:         s.IsMining,
+	}
+
+	if s.config.BorAutoRecoverSealing {
+		monitor.restart = func() {
+			s.StopMining()
+
+			if err := s.StartMining(); err != nil {
+				log.Error("Failed to restart the miner", "err", err)
+			}
+		}
+	}
+
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+
+	defer sub.Unsubscribe()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	<-timer.C
+
+	for {
+		select {
+		case ev := <-headCh:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+
+			if deadline := monitor.newHead(ev.Block.Header(), time.Now()); !deadline.IsZero() {
+				timer.Reset(time.Until(deadline))
+			}
+		case <-timer.C:
+			monitor.timeout(s.blockchain.CurrentBlock().Number.Uint64())
+		case <-sub.Err():
+			return
+		case <-s.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/bor"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSealingMonitor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		local    = common.Address{0x1}
+		backup   = common.Address{0x2}
+		now      = time.Unix(1000, 0)
+		headers  = make(map[uint64]*types.Header)
+		authors  = make(map[uint64]common.Address)
+		restarts int
+	)
+
+	// The node is the in-turn proposer of the even blocks, due 2s after their parent
+	monitor := &sealingMonitor{
+		inTurnSlot: func(parent *types.Header) (*bor.SealingSlot, error) {
+			number := parent.Number.Uint64() + 1
+			if number%2 != 0 {
+				return nil, nil
+			}
+
+			return &bor.SealingSlot{Number: number, Signer: local, Due: parent.Time + 2}, nil
+		},
+		author: func(header *types.Header) (common.Address, error) {
+			return authors[header.Number.Uint64()], nil
+		},
+		headerByNumber: func(number uint64) *types.Header { return headers[number] },
+		mining:         func() bool { return true },
+		restart:        func() { restarts++ },
+	}
+
+	head := func(number uint64, author common.Address) *types.Header {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Time: uint64(now.Unix())}
+		headers[number], authors[number] = header, author
+
+		return header
+	}
+
+	// Not in-turn for block 1
+	require.True(t, monitor.newHead(head(0, common.Address{}), now).IsZero())
+
+	// In-turn for block 2, sealed locally
+	deadline := monitor.newHead(head(1, backup), now)
+	require.Equal(t, now.Add(2*time.Second+missedSlotGrace), deadline)
+
+	monitor.newHead(head(2, local), now)
+	require.Zero(t, restarts)
+
+	// Block 4 sealed by a backup, the miner is restarted once
+	monitor.newHead(head(3, backup), now)
+	monitor.newHead(head(4, backup), now)
+	require.Equal(t, 1, restarts)
+
+	// No block imported for block 6, not restarted again until a block is sealed
+	monitor.newHead(head(5, backup), now)
+	monitor.timeout(5)
+	require.Equal(t, 1, restarts)
+
+	// Once reported, the slot isn't reported again when the backup's block comes
+	monitor.newHead(head(6, backup), now)
+	require.Equal(t, 1, restarts)
+
+	// Sealing recovered, a later missed slot restarts the miner again
+	monitor.newHead(head(7, backup), now)
+	monitor.newHead(head(8, local), now)
+	monitor.newHead(head(9, backup), now)
+	monitor.timeout(9)
+	require.Equal(t, 2, restarts)
+
+	// A timeout after the block was imported isn't a missed slot
+	monitor.newHead(head(10, local), now)
+	monitor.newHead(head(11, backup), now)
+	monitor.timeout(12)
+	require.Equal(t, 2, restarts)
+
+	// Stale heads aren't monitored
+	require.True(t, monitor.newHead(head(13, backup), now.Add(time.Minute)).IsZero())
+}
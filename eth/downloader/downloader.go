@@ -149,6 +149,13 @@ type Downloader struct {
 
 	ethereum.ChainValidator
 
+	trustedPeers     map[string]trustedPeer // Peers whose chains bypass the chain validator, for recovery only
+	trustedPeersLock sync.Mutex             // Lock protecting the trusted peers
+	trustedSyncUntil atomic.Uint64          // Block up to which the headers of the current sync are trusted (0 = untrusted sync)
+
+	forkStrikes     map[string]uint64 // Number of chains of each connected peer refused by the chain validator
+	forkStrikesLock sync.Mutex        // Lock protecting the fork strikes
+
 	// Testing hooks
 	syncInitHook     func(uint64, uint64)  // Method to call upon initiating a new sync run
 	bodyFetchHook    func([]*types.Header) // Method to call upon starting a block body fetch
@@ -218,6 +225,10 @@ type BlockChain interface {
 	// TrieDB retrieves the low level trie database used for interacting
 	// with trie nodes.
 	TrieDB() *trie.Database
+
+	// TrustHeaders lets the chains made of the given headers skip the chain
+	// validator when imported.
+	TrustHeaders([]*types.Header)
 }
 
 // New creates a new downloader to fetch hashes and blocks from remote peers.
@@ -350,6 +361,10 @@ func (d *Downloader) UnregisterPeer(id string) error {
 
 	d.queue.Revoke(id)
 
+	d.forkStrikesLock.Lock()
+	delete(d.forkStrikes, id)
+	d.forkStrikesLock.Unlock()
+
 	return nil
 }
 
@@ -477,9 +492,105 @@ func (d *Downloader) synchronise(id string, hash common.Hash, td, ttd *big.Int,
 		close(beaconPing)
 	}
 
+	if untilBlock, trusted := d.trustedUntil(id); trusted {
+		log.Warn("Synchronising from trusted peer, its headers bypass checkpoint and milestone validation", "peer", id, "until", untilBlock)
+
+		d.trustedSyncUntil.Store(untilBlock)
+		defer d.trustedSyncUntil.Store(0)
+	}
+
 	return d.syncWithPeer(p, hash, td, ttd, beaconMode)
 }
 
+// trustedPeer is a peer whose chain bypasses the chain validator up to a block.
+type trustedPeer struct {
+	untilBlock uint64
+	expiry     time.Time
+}
+
+// TrustPeer lets the chain served by the given peer bypass the chain validator
+// (i.e. the checkpoint and milestone whitelist) up to untilBlock, until expiry.
+// It's meant for recovering a chain stuck on a bad milestone only: the headers
+// fetched while syncing from the peer skip the validation when imported, blocks
+// from any other source are still validated.
+func (d *Downloader) TrustPeer(id string, untilBlock uint64, expiry time.Time) {
+	d.trustedPeersLock.Lock()
+	defer d.trustedPeersLock.Unlock()
+
+	if d.trustedPeers == nil {
+		d.trustedPeers = make(map[string]trustedPeer)
+	}
+
+	d.trustedPeers[id] = trustedPeer{untilBlock: untilBlock, expiry: expiry}
+
+	log.Warn("Trusting peer, its chain bypasses checkpoint and milestone validation", "peer", id, "until", untilBlock, "expiry", expiry)
+}
+
+// trustHeaders lets the headers up to untilBlock bypass the chain validator when
+// imported.
+func (d *Downloader) trustHeaders(headers []*types.Header, untilBlock uint64) {
+	trusted := make([]*types.Header, 0, len(headers))
+
+	for _, header := range headers {
+		if header.Number.Uint64() <= untilBlock {
+			trusted = append(trusted, header)
+		}
+	}
+
+	if len(trusted) > 0 {
+		d.blockchain.TrustHeaders(trusted)
+	}
+}
+
+// trustedUntil returns the block up to which the chain of the given peer bypasses
+// the chain validator, if the peer is trusted. Expired trusts are dropped.
+func (d *Downloader) trustedUntil(id string) (uint64, bool) {
+	d.trustedPeersLock.Lock()
+	defer d.trustedPeersLock.Unlock()
+
+	peer, ok := d.trustedPeers[id]
+	if !ok {
+		return 0, false
+	}
+
+	if time.Now().After(peer.expiry) || d.blockchain.CurrentBlock().Number.Uint64() >= peer.untilBlock {
+		delete(d.trustedPeers, id)
+		log.Warn("Peer trust expired, restoring checkpoint and milestone validation", "peer", id, "until", peer.untilBlock)
+
+		return 0, false
+	}
+
+	return peer.untilBlock, true
+}
+
+// recordForkStrike records that the chain of the given peer conflicted with the
+// whitelisted checkpoint or milestone.
+func (d *Downloader) recordForkStrike(id string) {
+	d.forkStrikesLock.Lock()
+	defer d.forkStrikesLock.Unlock()
+
+	if d.forkStrikes == nil {
+		d.forkStrikes = make(map[string]uint64)
+	}
+
+	d.forkStrikes[id]++
+}
+
+// ForkStrikes returns the number of times the chain of each connected peer was
+// refused for conflicting with the whitelisted checkpoint or milestone, for the
+// peers with at least one strike.
+func (d *Downloader) ForkStrikes() map[string]uint64 {
+	d.forkStrikesLock.Lock()
+	defer d.forkStrikesLock.Unlock()
+
+	strikes := make(map[string]uint64, len(d.forkStrikes))
+	for id, count := range d.forkStrikes {
+		strikes[id] = count
+	}
+
+	return strikes
+}
+
 func (d *Downloader) getMode() SyncMode {
 	return SyncMode(d.mode.Load())
 }
@@ -895,8 +1006,12 @@ func (d *Downloader) getFetchHeadersByNumber(p *peerConnection) func(number uint
 // the head links match), we do a binary search to find the common ancestor.
 func (d *Downloader) findAncestor(p *peerConnection, remoteHeader *types.Header) (uint64, error) {
 	// Check the validity of peer from which the chain is to be downloaded
-	if d.ChainValidator != nil {
+	if _, trusted := d.trustedUntil(p.id); d.ChainValidator != nil && !trusted {
 		if _, err := d.IsValidPeer(d.getFetchHeadersByNumber(p)); err != nil {
+			if errors.Is(err, whitelist.ErrMismatch) {
+				d.recordForkStrike(p.id)
+			}
+
 			return 0, err
 		}
 	}
@@ -1507,6 +1622,11 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 				chunkHeaders := headers[:limit]
 				chunkHashes := hashes[:limit]
 
+				// Only the headers synced from a trusted peer bypass the chain validator
+				if until := d.trustedSyncUntil.Load(); until != 0 {
+					d.trustHeaders(chunkHeaders, until)
+				}
+
 				// In case of header only syncing, validate the chunk immediately
 				if mode == SnapSync || mode == LightSync {
 					// Although the received headers might be all valid, a legacy
@@ -1924,6 +1924,7 @@ func (w *whitelistFake) GetWhitelistedMilestone() (bool, uint64, common.Hash) {
 	return false, 0, common.Hash{}
 }
 func (w *whitelistFake) PurgeWhitelistedMilestone() {}
+func (w *whitelistFake) PruneAbove(number uint64)   {}
 
 func (w *whitelistFake) GetCheckpoints(current, sidechainHeader *types.Header, sidechainCheckpoints []*types.Header) (map[uint64]*types.Header, error) {
 	return map[uint64]*types.Header{}, nil
@@ -1933,13 +1934,37 @@ func (w *whitelistFake) LockMutex(endBlockNum uint64) bool {
 }
 func (w *whitelistFake) UnlockMutex(doLock bool, milestoneId string, endBlockNum uint64, endBlockHash common.Hash) {
 }
-func (w *whitelistFake) UnlockSprint(endBlockNum uint64) {
+func (w *whitelistFake) UnlockSprint(endBlockNum uint64) bool {
+	return false
+}
+func (w *whitelistFake) UnlockSprintUpTo(endBlockNum uint64) bool {
+	return false
 }
 func (w *whitelistFake) RemoveMilestoneID(milestoneId string) {
 }
 func (w *whitelistFake) GetMilestoneIDsList() []string {
 	return nil
 }
+func (w *whitelistFake) RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64) {
+}
+func (w *whitelistFake) GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64) {
+	return false, "", 0, 0
+}
+func (w *whitelistFake) SubscribeMilestoneIDListChange(ch chan<- int) ethereum.Subscription {
+	return nil
+}
+func (w *whitelistFake) GetFutureMilestones() ([]uint64, []common.Hash) {
+	return nil, nil
+}
+func (w *whitelistFake) GetLockedSprintInfo() (bool, uint64, common.Hash, []string) {
+	return false, 0, common.Hash{}, nil
+}
+func (w *whitelistFake) ExpireMilestoneIDs(before time.Time) []string {
+	return nil
+}
+func (w *whitelistFake) CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error {
+	return nil
+}
 
 // TestFakedSyncProgress66WhitelistMismatch tests if in case of whitelisted
 // checkpoint mismatch with opposite peer, the sync should fail.
@@ -1964,6 +1989,18 @@ func TestFakedSyncProgress66WhitelistMismatch(t *testing.T) {
 	if err := tester.sync("light", nil, mode); err == nil {
 		t.Fatal("succeeded attacker synchronisation")
 	}
+
+	// The peer is struck for every refused chain, until it disconnects
+	assert.Equal(t, map[string]uint64{"light": 1}, tester.downloader.ForkStrikes())
+
+	if err := tester.sync("light", nil, mode); err == nil {
+		t.Fatal("succeeded attacker synchronisation")
+	}
+
+	assert.Equal(t, map[string]uint64{"light": 2}, tester.downloader.ForkStrikes())
+
+	tester.dropPeer("light")
+	assert.Empty(t, tester.downloader.ForkStrikes())
 }
 
 // TestFakedSyncProgress66WhitelistMatch tests if in case of whitelisted
@@ -1991,6 +2028,42 @@ func TestFakedSyncProgress66WhitelistMatch(t *testing.T) {
 	}
 }
 
+// TestFakedSyncProgress66TrustedPeer tests that a trusted peer bypasses the
+// whitelist mismatch, while the other peers are still validated.
+func TestFakedSyncProgress66TrustedPeer(t *testing.T) {
+	t.Parallel()
+
+	protocol := uint(eth.ETH66)
+	mode := FullSync
+
+	tester := newTester(t)
+	validate := func(count int) (bool, error) {
+		return false, whitelist.ErrMismatch
+	}
+	tester.downloader.ChainValidator = newWhitelistFake(validate)
+
+	defer tester.terminate()
+
+	chainA := testChainForkLightA.blocks
+	tester.newPeer("light", protocol, chainA[1:])
+	tester.newPeer("trusted", protocol, chainA[1:])
+
+	tester.downloader.TrustPeer("trusted", uint64(len(chainA)), time.Now().Add(time.Hour))
+
+	if err := tester.sync("light", nil, mode); err == nil {
+		t.Fatal("succeeded attacker synchronisation")
+	}
+
+	if err := tester.sync("trusted", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise with the trusted peer: %v", err)
+	}
+
+	assertOwnChain(t, tester, len(chainA))
+
+	// The trust is scoped to the sync with the peer
+	assert.Zero(t, tester.downloader.trustedSyncUntil.Load())
+}
+
 // TestFakedSyncProgress66NoRemoteCheckpoint tests if in case of missing/invalid
 // checkpointed blocks with opposite peer, the sync should fail initially but
 // with the retry mechanism, it should succeed eventually.
@@ -26,6 +26,7 @@ type finalityService interface {
 	Get() (bool, uint64, common.Hash)
 	Process(block uint64, hash common.Hash)
 	Purge()
+	PruneAbove(number uint64)
 }
 
 // IsValidPeer checks if the chain we're about to receive from a peer is valid or not
@@ -94,3 +95,40 @@ func (f *finality[T]) Purge() {
 
 	f.doExist = false
 }
+
+// PruneAbove drops the whitelisted entry, from memory and from the database, if
+// it's above the given block number.
+func (f *finality[T]) PruneAbove(number uint64) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.pruneAbove(number)
+}
+
+// pruneAbove is PruneAbove, with the lock held. It reports whether the entry was dropped.
+func (f *finality[T]) pruneAbove(number uint64) bool {
+	block := f.Number
+
+	if !f.doExist {
+		stored, _, err := rawdb.ReadFinality[T](f.db)
+		if err != nil {
+			return false
+		}
+
+		block = stored
+	}
+
+	if block <= number {
+		return false
+	}
+
+	f.doExist = false
+
+	if err := rawdb.DeleteLastFinality[T](f.db); err != nil {
+		log.Error("Error in deleting whitelist state from db", "err", err)
+	}
+
+	log.Info("Pruned the whitelisted entry above the head", "number", block, "head", number)
+
+	return true
+}
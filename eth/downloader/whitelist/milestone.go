@@ -1,10 +1,16 @@
 package whitelist
 
 import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/flags"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -17,9 +23,34 @@ type milestone struct {
 	Locked                bool                //
 	LockedMilestoneIDs    map[string]struct{} //list of milestone ids
 
+	milestoneIDTimes map[string]time.Time // Time at which each milestone id joined the list, for the ids not confirmed yet
+
 	FutureMilestoneList  map[uint64]common.Hash // Future Milestone list
 	FutureMilestoneOrder []uint64               // Future Milestone Order
 	MaxCapacity          int                    //Capacity of future Milestone list
+
+	History    []milestoneRecord // Recently whitelisted milestones, ordered by end block
+	MaxHistory int               // Capacity of the milestone history
+
+	idListSubs    []chan<- int // Subscribers notified of the length changes of the milestone ID list
+	idListLen     int          // Length of the milestone ID list last notified
+	idListSubLock sync.Mutex   // Protects idListSubs and idListLen
+
+	pinner     BlockPinner // Pins the block of the locked sprint from pruning, nil if none
+	pinnedHash common.Hash // Hash of the block pinned for the locked sprint, zero if none
+}
+
+// BlockPinner keeps the data of blocks from being pruned.
+type BlockPinner interface {
+	PinBlock(hash common.Hash) error
+	UnpinBlock(hash common.Hash)
+}
+
+// milestoneRecord is a whitelisted milestone along with the range of blocks it covers.
+type milestoneRecord struct {
+	ID         string
+	StartBlock uint64
+	EndBlock   uint64
 }
 
 type milestoneService interface {
@@ -29,8 +60,17 @@ type milestoneService interface {
 	RemoveMilestoneID(milestoneId string)
 	LockMutex(endBlockNum uint64) bool
 	UnlockMutex(doLock bool, milestoneId string, endBlockNum uint64, endBlockHash common.Hash)
-	UnlockSprint(endBlockNum uint64)
+	UnlockSprint(endBlockNum uint64) bool
+	UnlockSprintUpTo(endBlockNum uint64) bool
 	ProcessFutureMilestone(num uint64, hash common.Hash)
+	ExpireMilestoneIDs(before time.Time) []string
+	CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error
+	RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64)
+	GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64)
+	SubscribeMilestoneIDListChange(ch chan<- int) event.Subscription
+	GetFutureMilestones() ([]uint64, []common.Hash)
+	GetLockedSprintInfo() (bool, uint64, common.Hash, []string)
+	SetBlockPinner(pinner BlockPinner)
 }
 
 var (
@@ -40,6 +80,9 @@ var (
 	//Metrics for collecting the future milestone number
 	FutureMilestoneMeter = metrics.NewRegisteredGauge("chain/milestone/future", nil)
 
+	//Metrics for collecting the number of buffered future milestones
+	FutureMilestoneCountMeter = metrics.NewRegisteredGauge("chain/milestone/futurecount", nil)
+
 	//Metrics for collecting the length of the MilestoneIds map
 	MilestoneIdsLengthMeter = metrics.NewRegisteredGauge("chain/milestone/idslength", nil)
 
@@ -48,6 +91,12 @@ var (
 
 	//Metrics for collecting the number of valid peers received
 	MilestonePeerMeter = metrics.NewRegisteredMeter("chain/milestone/isvalidpeer", nil)
+
+	//Metrics for collecting the number of milestones matching the locked sprint
+	MilestoneMatchedExistingMeter = metrics.NewRegisteredMeter("chain/milestone/matchedexisting", nil)
+
+	//Metrics for collecting the number of milestones conflicting with the whitelisted one over their overlap
+	MilestoneOverlapConflictMeter = metrics.NewRegisteredMeter("chain/milestone/overlapconflict", nil)
 )
 
 // IsValidChain checks the validity of chain by comparing it
@@ -115,6 +164,11 @@ func (m *milestone) Process(block uint64, hash common.Hash) {
 	m.finality.Lock()
 	defer m.finality.Unlock()
 
+	if m.Locked && block == m.LockedMilestoneNumber && hash == m.LockedMilestoneHash {
+		MilestoneMatchedExistingMeter.Mark(1)
+		log.Debug("Milestone matched the locked sprint", "number", block, "hash", hash)
+	}
+
 	m.finality.Process(block, hash)
 
 	for i := 0; i < len(m.FutureMilestoneOrder); i++ {
@@ -127,7 +181,46 @@ func (m *milestone) Process(block uint64, hash common.Hash) {
 
 	whitelistedMilestoneMeter.Update(int64(block))
 
-	m.UnlockSprint(block)
+	m.unlockSprint(block)
+}
+
+// RecordMilestone adds a whitelisted milestone to the history used to look up
+// the milestone covering a block. Milestones which don't extend the history are
+// ignored and the oldest entries are dropped once the capacity is reached.
+func (m *milestone) RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64) {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	if n := len(m.History); n > 0 && endBlock <= m.History[n-1].EndBlock {
+		return
+	}
+
+	m.History = append(m.History, milestoneRecord{
+		ID:         milestoneId,
+		StartBlock: startBlock,
+		EndBlock:   endBlock,
+	})
+
+	if len(m.History) > m.MaxHistory {
+		m.History = m.History[len(m.History)-m.MaxHistory:]
+	}
+}
+
+// GetMilestoneForBlock returns the id, start block and end block of the recorded
+// milestone covering the given block number, if any.
+func (m *milestone) GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64) {
+	m.finality.RLock()
+	defer m.finality.RUnlock()
+
+	i := sort.Search(len(m.History), func(i int) bool {
+		return m.History[i].EndBlock >= number
+	})
+
+	if i == len(m.History) || m.History[i].StartBlock > number {
+		return false, "", 0, 0
+	}
+
+	return true, m.History[i].ID, m.History[i].StartBlock, m.History[i].EndBlock
 }
 
 // This function will Lock the mutex at the time of voting
@@ -151,14 +244,26 @@ func (m *milestone) LockMutex(endBlockNum uint64) bool {
 // This function will unlock the mutex locked in LockMutex
 // fixme: get rid of it
 func (m *milestone) UnlockMutex(doLock bool, milestoneId string, endBlockNum uint64, endBlockHash common.Hash) {
+	// A milestone voted on the already locked sprint and hash only confirms the
+	// lock, which is kept as is and handed over to the new milestone id
+	if doLock && m.Locked && endBlockNum == m.LockedMilestoneNumber && endBlockHash == m.LockedMilestoneHash {
+		MilestoneMatchedExistingMeter.Mark(1)
+		log.Debug("Milestone matched the locked sprint", "milestoneID", milestoneId, "number", endBlockNum, "hash", endBlockHash)
+
+		doLock = false
+		m.LockedMilestoneIDs = map[string]struct{}{milestoneId: {}}
+		m.recordMilestoneIDTime(milestoneId)
+	}
+
 	m.Locked = m.Locked || doLock
 
 	if doLock {
-		m.UnlockSprint(m.LockedMilestoneNumber)
+		m.unlockSprint(m.LockedMilestoneNumber)
 		m.Locked = true
 		m.LockedMilestoneHash = endBlockHash
 		m.LockedMilestoneNumber = endBlockNum
 		m.LockedMilestoneIDs[milestoneId] = struct{}{}
+		m.recordMilestoneIDTime(milestoneId)
 	}
 
 	err := rawdb.WriteLockField(m.db, m.Locked, m.LockedMilestoneNumber, m.LockedMilestoneHash, m.LockedMilestoneIDs)
@@ -166,16 +271,54 @@ func (m *milestone) UnlockMutex(doLock bool, milestoneId string, endBlockNum uin
 		log.Error("Error in writing lock data of milestone to db", "err", err)
 	}
 
+	m.pinLockedSprint()
+
 	milestoneIDLength := int64(len(m.LockedMilestoneIDs))
 	MilestoneIdsLengthMeter.Update(milestoneIDLength)
 
+	m.notifyMilestoneIDListChange()
+
 	m.finality.Unlock()
 }
 
-// This function will unlock the locked sprint
-func (m *milestone) UnlockSprint(endBlockNum uint64) {
+// UnlockSprint releases the lock of the sprint ending at endBlockNum. It's a no-op
+// if no sprint is locked or if another sprint is locked, leaving the lock
+// untouched, and reports whether a lock was released. See UnlockSprintUpTo for
+// releasing a lock superseded by a newer milestone.
+func (m *milestone) UnlockSprint(endBlockNum uint64) bool {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	if m.Locked && endBlockNum != m.LockedMilestoneNumber {
+		log.Debug("Ignoring unlock of a sprint other than the locked one", "endBlock", endBlockNum, "locked", m.LockedMilestoneNumber)
+		return false
+	}
+
+	return m.unlockSprint(endBlockNum)
+}
+
+// UnlockSprintUpTo releases the lock of the sprint ending at endBlockNum, or of an
+// older sprint superseded by it, e.g. after a milestone ending at endBlockNum
+// failed the verification. It reports whether a lock was released.
+func (m *milestone) UnlockSprintUpTo(endBlockNum uint64) bool {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	return m.unlockSprint(endBlockNum)
+}
+
+// unlockSprint releases the lock of the sprint ending at endBlockNum, or of an
+// older sprint superseded by it, with the finality lock held. It reports whether
+// a lock was released.
+func (m *milestone) unlockSprint(endBlockNum uint64) bool {
+	if !m.Locked {
+		log.Debug("Ignoring unlock of a sprint, no sprint is locked", "endBlock", endBlockNum)
+		return false
+	}
+
 	if endBlockNum < m.LockedMilestoneNumber {
-		return
+		log.Debug("Ignoring unlock of a sprint older than the locked one", "endBlock", endBlockNum, "locked", m.LockedMilestoneNumber)
+		return false
 	}
 
 	m.Locked = false
@@ -186,6 +329,47 @@ func (m *milestone) UnlockSprint(endBlockNum uint64) {
 	if err != nil {
 		log.Error("Error in writing lock data of milestone to db", "err", err)
 	}
+
+	m.pinLockedSprint()
+
+	return true
+}
+
+// PruneAbove drops the whitelisted milestone, the milestone history and the locked
+// sprint above the given block number, e.g. after the head was set back below them.
+// The future milestones are kept, they're still ahead of the chain.
+func (m *milestone) PruneAbove(number uint64) {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	m.finality.pruneAbove(number)
+
+	history := m.History[:0]
+
+	for _, record := range m.History {
+		if record.EndBlock <= number {
+			history = append(history, record)
+		}
+	}
+
+	m.History = history
+
+	if !m.Locked || m.LockedMilestoneNumber <= number {
+		return
+	}
+
+	log.Info("Pruned the locked sprint above the head", "locked", m.LockedMilestoneNumber, "head", number, "ids", len(m.LockedMilestoneIDs))
+
+	m.Locked = false
+	m.purgeMilestoneIDsList()
+	m.milestoneIDTimes = nil
+
+	err := rawdb.WriteLockField(m.db, m.Locked, m.LockedMilestoneNumber, m.LockedMilestoneHash, m.LockedMilestoneIDs)
+	if err != nil {
+		log.Error("Error in writing lock data of milestone to db", "err", err)
+	}
+
+	m.pinLockedSprint()
 }
 
 // This function will remove the stored milestoneID
@@ -198,14 +382,102 @@ func (m *milestone) RemoveMilestoneID(milestoneId string) {
 		m.Locked = false
 	}
 
+	m.notifyMilestoneIDListChange()
+
 	err := rawdb.WriteLockField(m.db, m.Locked, m.LockedMilestoneNumber, m.LockedMilestoneHash, m.LockedMilestoneIDs)
 	if err != nil {
 		log.Error("Error in writing lock data of milestone to db", "err", err)
 	}
 
+	m.pinLockedSprint()
+
 	m.finality.Unlock()
 }
 
+// recordMilestoneIDTime records the time at which the milestone id joined the list.
+func (m *milestone) recordMilestoneIDTime(milestoneId string) {
+	if m.milestoneIDTimes == nil {
+		m.milestoneIDTimes = make(map[string]time.Time)
+	}
+
+	m.milestoneIDTimes[milestoneId] = time.Now()
+}
+
+// CheckMilestoneOverlap checks a new milestone covering the end block of the
+// whitelisted milestone against it. The hash of the new milestone's chain at the
+// given number is returned by hashAt. An overlap disagreeing with the whitelisted
+// hash means heimdall revised the finalized history, ErrMilestoneOverlapConflict
+// is returned. Milestones not covering the whitelisted block are left to the
+// regular processing.
+func (m *milestone) CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error {
+	m.finality.RLock()
+	doExist, number, hash := m.doExist, m.Number, m.Hash
+	m.finality.RUnlock()
+
+	if !doExist || number < startBlock || number > endBlock {
+		return nil
+	}
+
+	if got := hashAt(number); got != hash {
+		MilestoneOverlapConflictMeter.Mark(1)
+		return fmt.Errorf("%w: milestone [%d, %d] has %s at %d, whitelisted %s", ErrMilestoneOverlapConflict, startBlock, endBlock, got, number, hash)
+	}
+
+	return nil
+}
+
+// ExpireMilestoneIDs removes the milestone ids which joined the list before the given
+// time, neither confirmed nor rejected since, and returns them. The sprint is unlocked
+// if no id is left. The ids loaded from the database are timed from their first check.
+func (m *milestone) ExpireMilestoneIDs(before time.Time) []string {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	for id := range m.milestoneIDTimes {
+		if _, ok := m.LockedMilestoneIDs[id]; !ok {
+			delete(m.milestoneIDTimes, id)
+		}
+	}
+
+	var expired []string
+
+	for id := range m.LockedMilestoneIDs {
+		added, ok := m.milestoneIDTimes[id]
+		if !ok {
+			m.recordMilestoneIDTime(id)
+			continue
+		}
+
+		if added.Before(before) {
+			delete(m.LockedMilestoneIDs, id)
+			delete(m.milestoneIDTimes, id)
+
+			expired = append(expired, id)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	sort.Strings(expired)
+
+	if len(m.LockedMilestoneIDs) == 0 {
+		m.Locked = false
+	}
+
+	m.notifyMilestoneIDListChange()
+
+	err := rawdb.WriteLockField(m.db, m.Locked, m.LockedMilestoneNumber, m.LockedMilestoneHash, m.LockedMilestoneIDs)
+	if err != nil {
+		log.Error("Error in writing lock data of milestone to db", "err", err)
+	}
+
+	m.pinLockedSprint()
+
+	return expired
+}
+
 // This will check whether the incoming chain matches the locked sprint hash
 func (m *milestone) IsReorgAllowed(chain []*types.Header, lockedMilestoneNumber uint64, lockedMilestoneHash common.Hash) bool {
 	if chain[len(chain)-1].Number.Uint64() <= lockedMilestoneNumber { //Can't reorg if the end block of incoming
@@ -238,6 +510,54 @@ func (m *milestone) GetMilestoneIDsList() []string {
 // This is remove the milestoneIDs stored in the list.
 func (m *milestone) purgeMilestoneIDsList() {
 	m.LockedMilestoneIDs = make(map[string]struct{})
+
+	m.notifyMilestoneIDListChange()
+}
+
+// SubscribeMilestoneIDListChange registers a channel receiving the new length of
+// the milestone ID list whenever it changes. The sends never block, a change is
+// dropped for a subscriber whose channel is full.
+func (m *milestone) SubscribeMilestoneIDListChange(ch chan<- int) event.Subscription {
+	m.idListSubLock.Lock()
+	m.idListSubs = append(m.idListSubs, ch)
+	m.idListSubLock.Unlock()
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+
+		m.idListSubLock.Lock()
+		defer m.idListSubLock.Unlock()
+
+		for i, sub := range m.idListSubs {
+			if sub == ch {
+				m.idListSubs = append(m.idListSubs[:i], m.idListSubs[i+1:]...)
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+// notifyMilestoneIDListChange sends the length of the milestone ID list to the
+// subscribers if it changed since the last notification.
+func (m *milestone) notifyMilestoneIDListChange() {
+	m.idListSubLock.Lock()
+	defer m.idListSubLock.Unlock()
+
+	length := len(m.LockedMilestoneIDs)
+	if length == m.idListLen {
+		return
+	}
+
+	m.idListLen = length
+
+	for _, sub := range m.idListSubs {
+		select {
+		case sub <- length:
+		default:
+		}
+	}
 }
 
 func (m *milestone) IsFutureMilestoneCompatible(chain []*types.Header) bool {
@@ -265,6 +585,9 @@ func (m *milestone) IsFutureMilestoneCompatible(chain []*types.Header) bool {
 }
 
 func (m *milestone) ProcessFutureMilestone(num uint64, hash common.Hash) {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
 	if len(m.FutureMilestoneOrder) < m.MaxCapacity {
 		m.enqueueFutureMilestone(num, hash)
 	}
@@ -281,6 +604,8 @@ func (m *milestone) ProcessFutureMilestone(num uint64, hash common.Hash) {
 	if err != nil {
 		log.Error("Error in writing lock data of milestone to db", "err", err)
 	}
+
+	m.pinLockedSprint()
 }
 
 // EnqueueFutureMilestone add the future milestone to the list
@@ -301,6 +626,7 @@ func (m *milestone) enqueueFutureMilestone(key uint64, hash common.Hash) {
 	}
 
 	FutureMilestoneMeter.Update(int64(key))
+	FutureMilestoneCountMeter.Update(int64(len(m.FutureMilestoneOrder)))
 }
 
 // DequeueFutureMilestone remove the future milestone entry from the list.
@@ -312,4 +638,93 @@ func (m *milestone) dequeueFutureMilestone() {
 	if err != nil {
 		log.Error("Error in writing future milestone data to db", "err", err)
 	}
+
+	FutureMilestoneCountMeter.Update(int64(len(m.FutureMilestoneOrder)))
+}
+
+// GetFutureMilestones returns the end block numbers and hashes of the buffered
+// future milestones, in the order they were received.
+func (m *milestone) GetFutureMilestones() ([]uint64, []common.Hash) {
+	m.finality.RLock()
+	defer m.finality.RUnlock()
+
+	numbers := make([]uint64, len(m.FutureMilestoneOrder))
+	hashes := make([]common.Hash, len(m.FutureMilestoneOrder))
+
+	for i, number := range m.FutureMilestoneOrder {
+		numbers[i] = number
+		hashes[i] = m.FutureMilestoneList[number]
+	}
+
+	return numbers, hashes
+}
+
+// GetLockedSprintInfo returns whether a sprint is locked and, if so, the number
+// and hash of its end block and the ids of the milestones holding the lock.
+func (m *milestone) GetLockedSprintInfo() (bool, uint64, common.Hash, []string) {
+	m.finality.RLock()
+	defer m.finality.RUnlock()
+
+	if !m.Locked {
+		return false, 0, common.Hash{}, nil
+	}
+
+	ids := make([]string, 0, len(m.LockedMilestoneIDs))
+	for id := range m.LockedMilestoneIDs {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return true, m.LockedMilestoneNumber, m.LockedMilestoneHash, ids
+}
+
+// SetBlockPinner sets the pinner keeping the block of the locked sprint from being
+// pruned for as long as the lock is held, so that the lock can always be checked
+// against the chain. The block of a lock restored from the database is pinned
+// right away.
+func (m *milestone) SetBlockPinner(pinner BlockPinner) {
+	m.finality.Lock()
+	defer m.finality.Unlock()
+
+	if m.pinner != nil && m.pinnedHash != (common.Hash{}) {
+		m.pinner.UnpinBlock(m.pinnedHash)
+	}
+
+	m.pinner, m.pinnedHash = pinner, common.Hash{}
+
+	m.pinLockedSprint()
+}
+
+// pinLockedSprint pins the block of the locked sprint, if any, releasing the block
+// pinned for a previous lock. A failed pin is retried on the next lock change.
+func (m *milestone) pinLockedSprint() {
+	if m.pinner == nil {
+		return
+	}
+
+	var hash common.Hash
+	if m.Locked {
+		hash = m.LockedMilestoneHash
+	}
+
+	if hash == m.pinnedHash {
+		return
+	}
+
+	if m.pinnedHash != (common.Hash{}) {
+		m.pinner.UnpinBlock(m.pinnedHash)
+		m.pinnedHash = common.Hash{}
+	}
+
+	if hash == (common.Hash{}) {
+		return
+	}
+
+	if err := m.pinner.PinBlock(hash); err != nil {
+		log.Warn("Failed to pin the block of the locked sprint", "number", m.LockedMilestoneNumber, "hash", hash, "err", err)
+		return
+	}
+
+	m.pinnedHash = hash
 }
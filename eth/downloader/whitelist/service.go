@@ -3,7 +3,9 @@ package whitelist
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -17,6 +19,8 @@ var (
 	ErrCheckpointMismatch = errors.New("checkpoint mismatch")
 	ErrLongFutureChain    = errors.New("received future chain of unacceptable length")
 	ErrNoRemoteCheckpoint = errors.New("remote peer doesn't have a checkpoint")
+
+	ErrMilestoneOverlapConflict = errors.New("milestone conflicts with the whitelisted milestone over their overlap")
 )
 
 type Service struct {
@@ -53,7 +57,7 @@ func NewService(db ethdb.Database) *Service {
 	}
 
 	return &Service{
-		&checkpoint{
+		checkpointService: &checkpoint{
 			finality[*rawdb.Checkpoint]{
 				doExist:  checkpointDoExist,
 				Number:   checkpointNumber,
@@ -63,7 +67,7 @@ func NewService(db ethdb.Database) *Service {
 			},
 		},
 
-		&milestone{
+		milestoneService: &milestone{
 			finality: finality[*rawdb.Milestone]{
 				doExist:  milestoneDoExist,
 				Number:   milestoneNumber,
@@ -79,6 +83,7 @@ func NewService(db ethdb.Database) *Service {
 			FutureMilestoneList:   list,
 			FutureMilestoneOrder:  order,
 			MaxCapacity:           10,
+			MaxHistory:            256,
 		},
 	}
 }
@@ -107,6 +112,13 @@ func (s *Service) PurgeWhitelistedMilestone() {
 	s.milestoneService.Purge()
 }
 
+// PruneAbove drops the whitelisted checkpoint and milestone, and the milestone
+// lock, above the given block number, once the head was set back below them.
+func (s *Service) PruneAbove(number uint64) {
+	s.checkpointService.PruneAbove(number)
+	s.milestoneService.PruneAbove(number)
+}
+
 func (s *Service) GetWhitelistedCheckpoint() (bool, uint64, common.Hash) {
 	return s.checkpointService.Get()
 }
@@ -119,6 +131,14 @@ func (s *Service) ProcessMilestone(endBlockNum uint64, endBlockHash common.Hash)
 	s.milestoneService.Process(endBlockNum, endBlockHash)
 }
 
+func (s *Service) RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64) {
+	s.milestoneService.RecordMilestone(milestoneId, startBlock, endBlock)
+}
+
+func (s *Service) GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64) {
+	return s.milestoneService.GetMilestoneForBlock(number)
+}
+
 func (s *Service) ProcessCheckpoint(endBlockNum uint64, endBlockHash common.Hash) {
 	s.checkpointService.Process(endBlockNum, endBlockHash)
 }
@@ -141,6 +161,32 @@ func (s *Service) GetMilestoneIDsList() []string {
 	return s.milestoneService.GetMilestoneIDsList()
 }
 
+// SubscribeMilestoneIDListChange registers a channel receiving the new length of
+// the milestone ID list whenever it changes, without ever blocking on it.
+func (s *Service) SubscribeMilestoneIDListChange(ch chan<- int) ethereum.Subscription {
+	return s.milestoneService.SubscribeMilestoneIDListChange(ch)
+}
+
+// GetFutureMilestones returns the end block numbers and hashes of the milestones
+// received above the current head, waiting to be whitelisted.
+func (s *Service) GetFutureMilestones() ([]uint64, []common.Hash) {
+	return s.milestoneService.GetFutureMilestones()
+}
+
+// GetLockedSprintInfo returns whether a sprint is locked by the milestone voting,
+// the number and hash of its end block and the ids of the milestones holding it.
+func (s *Service) GetLockedSprintInfo() (bool, uint64, common.Hash, []string) {
+	return s.milestoneService.GetLockedSprintInfo()
+}
+
+func (s *Service) ExpireMilestoneIDs(before time.Time) []string {
+	return s.milestoneService.ExpireMilestoneIDs(before)
+}
+
+func (s *Service) CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error {
+	return s.milestoneService.CheckMilestoneOverlap(startBlock, endBlock, hashAt)
+}
+
 func splitChain(current uint64, chain []*types.Header) ([]*types.Header, []*types.Header) {
 	var (
 		pastChain   []*types.Header
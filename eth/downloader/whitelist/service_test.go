@@ -23,8 +23,7 @@ import (
 // NewMockService creates a new mock whitelist service
 func NewMockService(db ethdb.Database) *Service {
 	return &Service{
-
-		&checkpoint{
+		checkpointService: &checkpoint{
 			finality[*rawdb.Checkpoint]{
 				doExist:  false,
 				interval: 256,
@@ -32,7 +31,7 @@ func NewMockService(db ethdb.Database) *Service {
 			},
 		},
 
-		&milestone{
+		milestoneService: &milestone{
 			finality: finality[*rawdb.Milestone]{
 				doExist:  false,
 				interval: 256,
@@ -42,6 +41,7 @@ func NewMockService(db ethdb.Database) *Service {
 			FutureMilestoneList:  make(map[uint64]common.Hash),
 			FutureMilestoneOrder: make([]uint64, 0),
 			MaxCapacity:          10,
+			MaxHistory:           256,
 		},
 	}
 }
@@ -240,6 +240,10 @@ func TestMilestone(t *testing.T) {
 	require.Equal(t, order[0], uint64(16), "expected number to be 16 but got", order[0])
 	require.Equal(t, list[order[0]], common.Hash{16}, "expected value is", common.Hash{16}.String()[2:], "but got", list[order[0]])
 
+	numbers, hashes := s.GetFutureMilestones()
+	require.Equal(t, []uint64{16}, numbers)
+	require.Equal(t, []common.Hash{{16}}, hashes)
+
 	capicity := milestone.MaxCapacity
 	for i := 16; i <= 16*(capicity+1); i = i + 16 {
 		s.ProcessFutureMilestone(uint64(i), common.Hash{16})
@@ -249,6 +253,315 @@ func TestMilestone(t *testing.T) {
 	require.Equal(t, milestone.FutureMilestoneOrder[capicity-1], uint64(16*capicity), "expected value is", uint64(16*capicity), "but got", milestone.FutureMilestoneOrder[capicity-1])
 }
 
+// TestUnlockSprint checks that only the locked sprint is unlocked, once.
+func TestUnlockSprint(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	milestone := s.milestoneService.(*milestone)
+
+	// Nothing to unlock
+	require.False(t, milestone.UnlockSprint(8), "expected no-op as no sprint is locked")
+	require.False(t, milestone.Locked)
+
+	hash := common.Hash{16}
+
+	milestone.LockMutex(16)
+	milestone.UnlockMutex(true, "milestoneID1", 16, hash)
+
+	// An older (e.g. overridden) sprint doesn't touch the active lock
+	require.False(t, milestone.UnlockSprint(8), "expected no-op as the locked sprint is newer")
+	require.True(t, milestone.Locked)
+	require.Equal(t, uint64(16), milestone.LockedMilestoneNumber)
+	require.Equal(t, hash, milestone.LockedMilestoneHash)
+	require.Equal(t, []string{"milestoneID1"}, milestone.GetMilestoneIDsList())
+
+	// Nor does a newer sprint, only a milestone superseding the lock releases it
+	require.False(t, milestone.UnlockSprint(24), "expected no-op as the locked sprint is older")
+	require.True(t, milestone.Locked)
+	require.Equal(t, uint64(16), milestone.LockedMilestoneNumber)
+
+	require.False(t, milestone.UnlockSprintUpTo(8), "expected no-op as the locked sprint is newer")
+	require.True(t, milestone.Locked)
+
+	locked, number, lockedHash, lockIDs := s.GetLockedSprintInfo()
+	require.True(t, locked)
+	require.Equal(t, uint64(16), number)
+	require.Equal(t, hash, lockedHash)
+	require.Equal(t, []string{"milestoneID1"}, lockIDs)
+
+	locked, number, lockedHash, ids, err := rawdb.ReadLockField(db)
+	require.NoError(t, err)
+	require.True(t, locked)
+	require.Equal(t, uint64(16), number)
+	require.Equal(t, hash, lockedHash)
+	require.Len(t, ids, 1)
+
+	// The locked sprint is unlocked, once
+	require.True(t, milestone.UnlockSprint(16))
+	require.False(t, milestone.Locked)
+	require.Empty(t, milestone.GetMilestoneIDsList())
+
+	locked, number, lockedHash, lockIDs = s.GetLockedSprintInfo()
+	require.False(t, locked)
+	require.Zero(t, number)
+	require.Equal(t, common.Hash{}, lockedHash)
+	require.Empty(t, lockIDs)
+
+	require.False(t, milestone.UnlockSprint(16), "expected no-op as the sprint is already unlocked")
+
+	// A milestone processed past the locked sprint supersedes the lock
+	milestone.LockMutex(32)
+	milestone.UnlockMutex(true, "milestoneID2", 32, common.Hash{32})
+	require.True(t, milestone.Locked)
+
+	s.ProcessMilestone(40, common.Hash{40})
+	require.False(t, milestone.Locked)
+}
+
+func TestLockOnSameHash(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	milestone := s.milestoneService.(*milestone)
+
+	chain := createMockChain(1, 12)
+	hash := chain[7].Hash()
+
+	require.True(t, s.LockMutex(8))
+	s.UnlockMutex(true, "milestoneID1", 8, hash)
+
+	// A second milestone on the same sprint and hash keeps the lock, under its id
+	require.True(t, s.LockMutex(8))
+	s.UnlockMutex(true, "milestoneID2", 8, hash)
+
+	locked, number, lockedHash, ids := s.GetLockedSprintInfo()
+	require.True(t, locked)
+	require.Equal(t, uint64(8), number)
+	require.Equal(t, hash, lockedHash)
+	require.Equal(t, []string{"milestoneID2"}, ids)
+
+	// The chain holding the locked hash is accepted, no reorg is considered
+	res, err := s.IsValidChain(chain[11], chain)
+	require.NoError(t, err)
+	require.True(t, res)
+
+	// The milestone whitelisted on the locked hash confirms and releases the lock
+	s.ProcessMilestone(8, hash)
+	require.False(t, milestone.Locked)
+
+	res, err = s.IsValidChain(chain[11], chain)
+	require.NoError(t, err)
+	require.True(t, res)
+}
+
+// TestMilestoneForBlock checks the lookup of the whitelisted milestone covering a block.
+func TestMilestoneForBlock(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	milestone := s.milestoneService.(*milestone)
+	milestone.MaxHistory = 2
+
+	found, _, _, _ := s.GetMilestoneForBlock(5)
+	require.False(t, found, "expected no milestone before any is recorded")
+
+	s.RecordMilestone("id1", 0, 15)
+	s.RecordMilestone("id2", 16, 31)
+
+	found, id, start, end := s.GetMilestoneForBlock(20)
+	require.True(t, found)
+	require.Equal(t, "id2", id)
+	require.Equal(t, uint64(16), start)
+	require.Equal(t, uint64(31), end)
+
+	found, id, _, _ = s.GetMilestoneForBlock(15)
+	require.True(t, found)
+	require.Equal(t, "id1", id)
+
+	found, _, _, _ = s.GetMilestoneForBlock(32)
+	require.False(t, found, "expected no milestone above the latest one")
+
+	// A milestone which doesn't extend the history is ignored
+	s.RecordMilestone("stale", 10, 20)
+	_, id, _, _ = s.GetMilestoneForBlock(20)
+	require.Equal(t, "id2", id)
+
+	// The oldest milestone is dropped once the capacity is reached
+	s.RecordMilestone("id3", 32, 47)
+	require.Len(t, milestone.History, 2)
+
+	found, _, _, _ = s.GetMilestoneForBlock(5)
+	require.False(t, found, "expected the oldest milestone to be dropped")
+
+	found, id, _, _ = s.GetMilestoneForBlock(40)
+	require.True(t, found)
+	require.Equal(t, "id3", id)
+}
+
+func TestMilestoneIDListChange(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	ch := make(chan int, 1)
+	sub := s.SubscribeMilestoneIDListChange(ch)
+
+	s.LockMutex(10)
+	s.UnlockMutex(true, "id1", 10, common.Hash{1})
+	require.Equal(t, 1, <-ch)
+
+	// Locking a newer milestone purges the IDs first, the channel is full by then
+	// so the addition of the new ID is dropped instead of blocking
+	s.LockMutex(20)
+	s.UnlockMutex(true, "id2", 20, common.Hash{2})
+	require.Equal(t, 0, <-ch)
+	require.Empty(t, ch)
+	require.Len(t, s.GetMilestoneIDsList(), 1)
+
+	// No notification if the length doesn't change
+	s.RemoveMilestoneID("unknown")
+	require.Empty(t, ch)
+
+	sub.Unsubscribe()
+
+	s.RemoveMilestoneID("id2")
+	require.Empty(t, ch)
+}
+
+func TestCheckMilestoneOverlap(t *testing.T) {
+	t.Parallel()
+
+	s := NewMockService(rawdb.NewMemoryDatabase())
+
+	// The hashes of the chain of the new milestone
+	chain := map[uint64]common.Hash{12: {12}, 20: {20}}
+	hashAt := func(number uint64) common.Hash { return chain[number] }
+
+	require.NoError(t, s.CheckMilestoneOverlap(1, 20, hashAt), "expected no overlap without a whitelisted milestone")
+
+	s.ProcessMilestone(12, common.Hash{12})
+
+	// [1, 20] covers [1, 12] and agrees with it
+	require.NoError(t, s.CheckMilestoneOverlap(1, 20, hashAt))
+
+	// A milestone after the whitelisted one doesn't overlap it
+	require.NoError(t, s.CheckMilestoneOverlap(13, 20, func(uint64) common.Hash { return common.Hash{} }))
+
+	// [1, 20] covers [1, 12] with a different block 12, heimdall revised the finalized history
+	chain[12] = common.Hash{0x1}
+	require.ErrorIs(t, s.CheckMilestoneOverlap(1, 20, hashAt), ErrMilestoneOverlapConflict)
+	require.ErrorIs(t, s.CheckMilestoneOverlap(12, 12, hashAt), ErrMilestoneOverlapConflict)
+
+	// Once agreed, the whitelist extends to the new end
+	chain[12] = common.Hash{12}
+	require.NoError(t, s.CheckMilestoneOverlap(1, 20, hashAt))
+	s.ProcessMilestone(20, common.Hash{20})
+
+	doExist, number, hash := s.GetWhitelistedMilestone()
+	require.True(t, doExist)
+	require.Equal(t, uint64(20), number)
+	require.Equal(t, common.Hash{20}, hash)
+}
+
+func TestExpireMilestoneIDs(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	milestone := s.milestoneService.(*milestone)
+
+	require.Empty(t, s.ExpireMilestoneIDs(time.Now()), "expected nothing to expire without a lock")
+
+	require.True(t, s.LockMutex(8))
+	s.UnlockMutex(true, "milestoneID1", 8, common.Hash{1})
+
+	// The id is recent enough
+	require.Empty(t, s.ExpireMilestoneIDs(time.Now().Add(-time.Hour)))
+	require.Equal(t, []string{"milestoneID1"}, s.GetMilestoneIDsList())
+	require.True(t, milestone.Locked)
+
+	// The id expires, releasing the lock
+	require.Equal(t, []string{"milestoneID1"}, s.ExpireMilestoneIDs(time.Now().Add(time.Second)))
+	require.Empty(t, s.GetMilestoneIDsList())
+	require.False(t, milestone.Locked)
+
+	locked, _, _, _ := s.GetLockedSprintInfo()
+	require.False(t, locked)
+
+	// The lock is persisted without the expired id
+	locked, _, _, ids, err := rawdb.ReadLockField(db)
+	require.NoError(t, err)
+	require.False(t, locked)
+	require.Empty(t, ids)
+}
+
+func TestPruneAbove(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	s := NewMockService(db)
+
+	s.ProcessCheckpoint(64, common.Hash{1})
+	s.ProcessMilestone(96, common.Hash{2})
+	s.RecordMilestone("milestoneID1", 65, 80)
+	s.RecordMilestone("milestoneID2", 81, 96)
+
+	require.True(t, s.LockMutex(112))
+	s.UnlockMutex(true, "milestoneID3", 112, common.Hash{3})
+
+	// Nothing above the head
+	s.PruneAbove(112)
+
+	exists, number, _ := s.GetWhitelistedMilestone()
+	require.True(t, exists)
+	require.Equal(t, uint64(96), number)
+	require.Equal(t, []string{"milestoneID3"}, s.GetMilestoneIDsList())
+
+	// The lock and the milestone are above the head, not the checkpoint
+	s.PruneAbove(90)
+
+	require.Empty(t, s.GetMilestoneIDsList())
+
+	locked, _, _, _ := s.GetLockedSprintInfo()
+	require.False(t, locked)
+
+	exists, _, _ = s.GetWhitelistedMilestone()
+	require.False(t, exists)
+
+	exists, number, _ = s.GetWhitelistedCheckpoint()
+	require.True(t, exists)
+	require.Equal(t, uint64(64), number)
+
+	found, id, _, _ := s.GetMilestoneForBlock(70)
+	require.True(t, found)
+	require.Equal(t, "milestoneID1", id)
+
+	found, _, _, _ = s.GetMilestoneForBlock(90)
+	require.False(t, found)
+
+	// The pruning is persisted
+	_, _, err := rawdb.ReadFinality[*rawdb.Milestone](db)
+	require.Error(t, err)
+
+	locked, _, _, _, err = rawdb.ReadLockField(db)
+	require.NoError(t, err)
+	require.False(t, locked)
+
+	s.PruneAbove(10)
+
+	exists, _, _ = s.GetWhitelistedCheckpoint()
+	require.False(t, exists)
+}
+
 // TestIsValidPeer checks the IsValidPeer function in isolation
 // for different cases by providing a mock fetchHeadersByNumber function
 func TestIsValidPeer(t *testing.T) {
@@ -19,6 +19,7 @@ package ethconfig
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -193,6 +194,18 @@ type Config struct {
 	// Use child heimdall process to fetch data, Only works when RunHeimdall is true
 	UseHeimdallApp bool
 
+	// URL of a caching proxy serving the heimdall REST api, which all the heimdall calls are routed through
+	HeimdallProxyURL string
+
+	// Maximum number of concurrent heimdall calls, further calls wait for a free slot (0 = unlimited)
+	HeimdallMaxConcurrentRequests int
+
+	// Version of the heimdall REST api, selecting the paths of its endpoints (empty = default)
+	HeimdallAPIVersion string
+
+	// Directory which all the heimdall responses are recorded to, for a later replay (empty = disabled)
+	HeimdallRecordDir string
+
 	// Bor logs flag
 	BorLogs bool
 
@@ -202,6 +215,102 @@ type Config struct {
 	// Develop Fake Author mode to produce blocks without authorisation
 	DevFakeAuthor bool `hcl:"devfakeauthor,optional" toml:"devfakeauthor,optional"`
 
+	// Fake authors the proposer rotates through at every sprint in DevFakeAuthor mode
+	DevFakeAuthors []common.Address `toml:",omitempty"`
+
+	// Validate the full layout of the header's extra-data in bor
+	BorStrictExtraDataValidation bool
+
+	// Number of blocks after which bor stores a validator snapshot to the database
+	BorSnapshotCheckpointInterval uint64
+
+	// Disable out-of-turn sealing in bor, the node only seals when in-turn
+	BorDisallowOutOfTurn bool
+
+	// Pause sealing this many blocks before the end of the current span until the next span is fetched (0 = disabled)
+	BorMaxSpanStaleness uint64
+
+	// Policy used to choose between two heads of equal total difficulty and height ('highesthash', 'lowesthash', 'firstseen')
+	BorForkTiebreak string
+
+	// Number of consecutive consistent milestones needed before a milestone is whitelisted (0 or 1 = whitelist right away)
+	BorMilestoneConfirmations uint64
+
+	// Maximum size of the bor snapshot recents, in percent of the validator set size plus one (0 = 50)
+	BorRecentsLimitPercent uint64
+
+	// Check the span committed in a span boundary block against heimdall before sealing it
+	BorVerifySpanCommit bool
+
+	// Check at startup that the bor system contracts have code in the genesis state
+	BorVerifyGenesisContracts bool
+
+	// Interval between the fetches of the latest milestone from heimdall
+	BorMilestonePollInterval time.Duration
+
+	// Behaviour of the milestone verification when the end block isn't available locally: 'defer' keeps the milestone pending until it is (default), 'trust' whitelists it unverified
+	BorMilestoneVerifyMissingDataPolicy string
+
+	// Number of validator set contract reads cached by block hash (0 = disabled)
+	BorValidatorSetCacheSize int
+
+	// Maximum number of headers verified concurrently in a batch (0 = number of CPUs)
+	BorVerifyConcurrency int
+
+	// Time after which the node stops fetching the chain of a conflicting milestone (0 = never)
+	BorMilestoneFetchTimeout time.Duration
+
+	// Skew of the local clock from heimdall's over which a warning is logged (0 = not measured)
+	BorMaxHeimdallClockSkew time.Duration
+
+	// Gap between the head and the latest milestone, in blocks, over which a finality warning is logged (0 = disabled)
+	BorMilestoneGapWarnThreshold uint64
+
+	// Number of blocks before the end of the span the sealing stops if the signer isn't a producer of the next span (0 = disabled)
+	BorSealStopBeforeSpanChange uint64
+
+	// Whether the chain of a milestone conflicting with the local chain is requested from all the peers right away
+	BorEagerMilestoneResync bool
+
+	// Source of the validator set of the genesis span (span 0): contract, heimdall or strict
+	BorGenesisSpanSource string
+
+	// Maximum number of state receivers whose state-sync events are executed speculatively in parallel, committed sequentially on a conflict (0 = disabled)
+	BorParallelStateSync int
+
+	// Check the difficulty of the headers against the turn of their signer before the validator list, not only when verifying their seal
+	BorStrictDifficultyValidation bool
+
+	// Time after which a milestone id locking a sprint, neither confirmed nor rejected by heimdall, is dropped (0 = never)
+	BorMilestoneIDTTL time.Duration
+
+	// Reconciliation of the persisted milestone with heimdall's latest one on startup: heimdall, persisted or newest
+	BorMilestoneStartupPolicy string
+
+	// Persist the last applied state-sync event atomically with the block which applied it
+	BorPersistStateSyncProgress bool
+
+	// Behaviour of the milestone verification when a milestone disagrees with the whitelisted one over their overlap: reject or accept
+	BorMilestoneOverlapPolicy string
+
+	// Log every decision taken while processing each milestone, tagged with the milestone id
+	BorTraceMilestoneProcessing bool
+
+	// Most headers walked back to reconstruct a snapshot before failing as its checkpoint snapshot is missing (0 = unbounded)
+	BorMaxSnapshotWalkback uint64
+
+	// Seconds a header's timestamp may be ahead of the local clock before it's deferred as a future block, for clock skew
+	BorFutureBlockTolerance uint64
+
+	// Restart the miner once when the node misses a block it's the in-turn proposer of, until it seals a block again
+	BorAutoRecoverSealing bool
+
+	// Prune the tracked milestone ids, the milestone lock and the whitelisted entries above the new head on SetHead
+	BorPruneMilestonesOnSetHead bool
+
+	// Behaviour of the milestone processing while the snap sync is in progress: defer or skip
+	BorMilestoneDuringSnapSync string
+
 	// OverrideVerkle (TODO: remove after the fork)
 	OverrideVerkle *big.Int `toml:",omitempty"`
 }
@@ -215,26 +324,93 @@ func CreateConsensusEngine(chainConfig *params.ChainConfig, ethConfig *Config, d
 		// If Matic bor consensus is requested, set it up
 		// In order to pass the ethereum transaction tests, we need to set the burn contract which is in the bor config
 		// Then, bor != nil will also be enabled for ethash and clique. Only enable Bor for real if there is a validator contract present.
+		if ethConfig.BorVerifyGenesisContracts {
+			if err := bor.VerifyGenesisContracts(db, chainConfig.Bor); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := bor.ValidateStateReceiverContracts(db, chainConfig.Bor); err != nil {
+			return nil, err
+		}
+
+		genesisSpanSource, err := bor.ParseGenesisSpanSource(ethConfig.BorGenesisSpanSource)
+		if err != nil {
+			return nil, err
+		}
+
+		// make sure the spans stay aligned with the sprints across the sprint forks
+		if err := bor.ValidateSpanSprintAlignment(chainConfig.Bor); err != nil {
+			return nil, fmt.Errorf("invalid sprint schedule in genesis: %w", err)
+		}
+
+		if err := bor.ValidateFutureBlockTolerance(chainConfig.Bor, ethConfig.BorFutureBlockTolerance); err != nil {
+			return nil, err
+		}
+
+		if err := bor.ValidateRecentsLimitPercent(ethConfig.BorRecentsLimitPercent); err != nil {
+			return nil, err
+		}
+
+		options := append(borOptions(ethConfig), bor.WithGenesisSpanSource(genesisSpanSource))
+
 		genesisContractsClient := contract.NewGenesisContractsClient(chainConfig, chainConfig.Bor.ValidatorContract, chainConfig.Bor.StateReceiverContract, blockchainAPI)
 		spanner := span.NewChainSpanner(blockchainAPI, contract.ValidatorSet(), chainConfig, common.HexToAddress(chainConfig.Bor.ValidatorContract))
+		spanner.EnableValidatorSetCache(ethConfig.BorValidatorSetCacheSize)
 
 		if ethConfig.WithoutHeimdall {
-			return bor.New(chainConfig, db, blockchainAPI, spanner, nil, genesisContractsClient, ethConfig.DevFakeAuthor), nil
+			return bor.New(chainConfig, db, blockchainAPI, spanner, nil, genesisContractsClient, ethConfig.DevFakeAuthor, options...), nil
 		} else {
 			if ethConfig.DevFakeAuthor {
 				log.Warn("Sanitizing DevFakeAuthor", "Use DevFakeAuthor with", "--bor.withoutheimdall")
 			}
 
-			var heimdallClient bor.IHeimdallClient
-			if ethConfig.RunHeimdall && ethConfig.UseHeimdallApp {
+			var (
+				heimdallClient bor.IHeimdallClient
+				err            error
+			)
+
+			switch {
+			case ethConfig.RunHeimdall && ethConfig.UseHeimdallApp:
 				heimdallClient = heimdallapp.NewHeimdallAppClient()
-			} else if ethConfig.HeimdallgRPCAddress != "" {
-				heimdallClient = heimdallgrpc.NewHeimdallGRPCClient(ethConfig.HeimdallgRPCAddress)
-			} else {
-				heimdallClient = heimdall.NewHeimdallClient(ethConfig.HeimdallURL)
+			case ethConfig.HeimdallgRPCAddress != "":
+				heimdallClient, err = NewHeimdallClient("grpc", ethConfig.HeimdallgRPCAddress, ethConfig)
+			default:
+				heimdallClient, err = NewHeimdallClient("http", ethConfig.HeimdallURL, ethConfig)
+			}
+
+			if err != nil {
+				return nil, err
 			}
 
-			return bor.New(chainConfig, db, blockchainAPI, spanner, heimdallClient, genesisContractsClient, false), nil
+			// Allow swapping the client at runtime, see admin_borSetHeimdallClient. The
+			// swappable client is the innermost one so that the swapped in clients are
+			// proxied, recorded and limited as the initial one.
+			swappable := bor.NewHeimdallSwappableClient(heimdallClient)
+			heimdallClient = swappable
+			options = append(options, bor.WithSwappableHeimdallClient(swappable))
+
+			if ethConfig.HeimdallProxyURL != "" {
+				proxyClient, err := heimdall.NewHeimdallClientWithAPIVersion(ethConfig.HeimdallProxyURL, ethConfig.HeimdallAPIVersion)
+				if err != nil {
+					return nil, err
+				}
+
+				heimdallClient = bor.NewHeimdallProxyClient(proxyClient, heimdallClient)
+			}
+
+			if ethConfig.HeimdallRecordDir != "" {
+				heimdallClient, err = bor.NewHeimdallRecordingClient(heimdallClient, ethConfig.HeimdallRecordDir)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if ethConfig.HeimdallMaxConcurrentRequests > 0 {
+				heimdallClient = bor.NewHeimdallLimitedClient(heimdallClient, ethConfig.HeimdallMaxConcurrentRequests)
+			}
+
+			return bor.New(chainConfig, db, blockchainAPI, spanner, heimdallClient, genesisContractsClient, false, options...), nil
 		}
 	}
 	if !chainConfig.TerminalTotalDifficultyPassed {
@@ -242,3 +418,42 @@ func CreateConsensusEngine(chainConfig *params.ChainConfig, ethConfig *Config, d
 	}
 	return beacon.New(ethash.NewFaker()), nil
 }
+
+// NewHeimdallClient creates a heimdall client of the given type ("http" or
+// "grpc") targeting the given endpoint. Wrapping it in the proxy and concurrency
+// limiting clients is left to the caller.
+func NewHeimdallClient(kind string, endpoint string, ethConfig *Config) (bor.IHeimdallClient, error) {
+	switch kind {
+	case "http":
+		client, err := heimdall.NewHeimdallClientWithAPIVersion(endpoint, ethConfig.HeimdallAPIVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		return client, nil
+	case "grpc":
+		return heimdallgrpc.NewHeimdallGRPCClient(endpoint), nil
+	}
+
+	return nil, fmt.Errorf("unknown heimdall client type %q", kind)
+}
+
+// borOptions translates the bor related fields of the config into the options
+// of the bor consensus engine.
+func borOptions(ethConfig *Config) []bor.Option {
+	return []bor.Option{
+		bor.WithStrictExtraDataValidation(ethConfig.BorStrictExtraDataValidation),
+		bor.WithStrictDifficultyValidation(ethConfig.BorStrictDifficultyValidation),
+		bor.WithFutureBlockTolerance(ethConfig.BorFutureBlockTolerance),
+		bor.WithMaxSnapshotWalkback(ethConfig.BorMaxSnapshotWalkback),
+		bor.WithSnapshotCheckpointInterval(ethConfig.BorSnapshotCheckpointInterval),
+		bor.WithAllowOutOfTurn(!ethConfig.BorDisallowOutOfTurn),
+		bor.WithMaxSpanStaleness(ethConfig.BorMaxSpanStaleness),
+		bor.WithRecentsLimitPercent(ethConfig.BorRecentsLimitPercent),
+		bor.WithVerifySpanCommit(ethConfig.BorVerifySpanCommit),
+		bor.WithVerifyConcurrency(ethConfig.BorVerifyConcurrency),
+		bor.WithSealStopBeforeSpanChange(ethConfig.BorSealStopBeforeSpanChange),
+		bor.WithParallelStateSync(ethConfig.BorParallelStateSync),
+		bor.WithDevFakeAuthors(ethConfig.DevFakeAuthors...),
+	}
+}
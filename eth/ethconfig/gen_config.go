@@ -65,10 +65,46 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RunHeimdall                          bool
 		RunHeimdallArgs                      string
 		UseHeimdallApp                       bool
+		HeimdallProxyURL                     string
+		HeimdallMaxConcurrentRequests        int
+		HeimdallAPIVersion                   string
+		HeimdallRecordDir                    string
 		BorLogs                              bool
 		ParallelEVM                          core.ParallelEVMConfig `toml:",omitempty"`
 		DevFakeAuthor                        bool                   `hcl:"devfakeauthor,optional" toml:"devfakeauthor,optional"`
-		OverrideVerkle                       *big.Int               `toml:",omitempty"`
+		DevFakeAuthors                       []common.Address       `toml:",omitempty"`
+		BorStrictExtraDataValidation         bool
+		BorSnapshotCheckpointInterval        uint64
+		BorDisallowOutOfTurn                 bool
+		BorMaxSpanStaleness                  uint64
+		BorForkTiebreak                      string
+		BorMilestoneConfirmations            uint64
+		BorRecentsLimitPercent               uint64
+		BorVerifySpanCommit                  bool
+		BorVerifyGenesisContracts            bool
+		BorMilestonePollInterval             time.Duration
+		BorMilestoneVerifyMissingDataPolicy  string
+		BorValidatorSetCacheSize             int
+		BorVerifyConcurrency                 int
+		BorMilestoneFetchTimeout             time.Duration
+		BorMaxHeimdallClockSkew              time.Duration
+		BorMilestoneGapWarnThreshold         uint64
+		BorSealStopBeforeSpanChange          uint64
+		BorEagerMilestoneResync              bool
+		BorGenesisSpanSource                 string
+		BorParallelStateSync                 int
+		BorStrictDifficultyValidation        bool
+		BorMilestoneIDTTL                    time.Duration
+		BorMilestoneStartupPolicy            string
+		BorPersistStateSyncProgress          bool
+		BorMilestoneOverlapPolicy            string
+		BorTraceMilestoneProcessing          bool
+		BorMaxSnapshotWalkback               uint64
+		BorFutureBlockTolerance              uint64
+		BorAutoRecoverSealing                bool
+		BorPruneMilestonesOnSetHead          bool
+		BorMilestoneDuringSnapSync           string
+		OverrideVerkle                       *big.Int `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -118,9 +154,45 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RunHeimdall = c.RunHeimdall
 	enc.RunHeimdallArgs = c.RunHeimdallArgs
 	enc.UseHeimdallApp = c.UseHeimdallApp
+	enc.HeimdallProxyURL = c.HeimdallProxyURL
+	enc.HeimdallMaxConcurrentRequests = c.HeimdallMaxConcurrentRequests
+	enc.HeimdallAPIVersion = c.HeimdallAPIVersion
+	enc.HeimdallRecordDir = c.HeimdallRecordDir
 	enc.BorLogs = c.BorLogs
 	enc.ParallelEVM = c.ParallelEVM
 	enc.DevFakeAuthor = c.DevFakeAuthor
+	enc.DevFakeAuthors = c.DevFakeAuthors
+	enc.BorStrictExtraDataValidation = c.BorStrictExtraDataValidation
+	enc.BorSnapshotCheckpointInterval = c.BorSnapshotCheckpointInterval
+	enc.BorDisallowOutOfTurn = c.BorDisallowOutOfTurn
+	enc.BorMaxSpanStaleness = c.BorMaxSpanStaleness
+	enc.BorForkTiebreak = c.BorForkTiebreak
+	enc.BorMilestoneConfirmations = c.BorMilestoneConfirmations
+	enc.BorRecentsLimitPercent = c.BorRecentsLimitPercent
+	enc.BorVerifySpanCommit = c.BorVerifySpanCommit
+	enc.BorVerifyGenesisContracts = c.BorVerifyGenesisContracts
+	enc.BorMilestonePollInterval = c.BorMilestonePollInterval
+	enc.BorMilestoneVerifyMissingDataPolicy = c.BorMilestoneVerifyMissingDataPolicy
+	enc.BorValidatorSetCacheSize = c.BorValidatorSetCacheSize
+	enc.BorVerifyConcurrency = c.BorVerifyConcurrency
+	enc.BorMilestoneFetchTimeout = c.BorMilestoneFetchTimeout
+	enc.BorMaxHeimdallClockSkew = c.BorMaxHeimdallClockSkew
+	enc.BorMilestoneGapWarnThreshold = c.BorMilestoneGapWarnThreshold
+	enc.BorSealStopBeforeSpanChange = c.BorSealStopBeforeSpanChange
+	enc.BorEagerMilestoneResync = c.BorEagerMilestoneResync
+	enc.BorGenesisSpanSource = c.BorGenesisSpanSource
+	enc.BorParallelStateSync = c.BorParallelStateSync
+	enc.BorStrictDifficultyValidation = c.BorStrictDifficultyValidation
+	enc.BorMilestoneIDTTL = c.BorMilestoneIDTTL
+	enc.BorMilestoneStartupPolicy = c.BorMilestoneStartupPolicy
+	enc.BorPersistStateSyncProgress = c.BorPersistStateSyncProgress
+	enc.BorMilestoneOverlapPolicy = c.BorMilestoneOverlapPolicy
+	enc.BorTraceMilestoneProcessing = c.BorTraceMilestoneProcessing
+	enc.BorMaxSnapshotWalkback = c.BorMaxSnapshotWalkback
+	enc.BorFutureBlockTolerance = c.BorFutureBlockTolerance
+	enc.BorAutoRecoverSealing = c.BorAutoRecoverSealing
+	enc.BorPruneMilestonesOnSetHead = c.BorPruneMilestonesOnSetHead
+	enc.BorMilestoneDuringSnapSync = c.BorMilestoneDuringSnapSync
 	enc.OverrideVerkle = c.OverrideVerkle
 	return &enc, nil
 }
@@ -175,10 +247,46 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RunHeimdall                          *bool
 		RunHeimdallArgs                      *string
 		UseHeimdallApp                       *bool
+		HeimdallProxyURL                     *string
+		HeimdallMaxConcurrentRequests        *int
+		HeimdallAPIVersion                   *string
+		HeimdallRecordDir                    *string
 		BorLogs                              *bool
 		ParallelEVM                          *core.ParallelEVMConfig `toml:",omitempty"`
 		DevFakeAuthor                        *bool                   `hcl:"devfakeauthor,optional" toml:"devfakeauthor,optional"`
-		OverrideVerkle                       *big.Int                `toml:",omitempty"`
+		DevFakeAuthors                       []common.Address        `toml:",omitempty"`
+		BorStrictExtraDataValidation         *bool
+		BorSnapshotCheckpointInterval        *uint64
+		BorDisallowOutOfTurn                 *bool
+		BorMaxSpanStaleness                  *uint64
+		BorForkTiebreak                      *string
+		BorMilestoneConfirmations            *uint64
+		BorRecentsLimitPercent               *uint64
+		BorVerifySpanCommit                  *bool
+		BorVerifyGenesisContracts            *bool
+		BorMilestonePollInterval             *time.Duration
+		BorMilestoneVerifyMissingDataPolicy  *string
+		BorValidatorSetCacheSize             *int
+		BorVerifyConcurrency                 *int
+		BorMilestoneFetchTimeout             *time.Duration
+		BorMaxHeimdallClockSkew              *time.Duration
+		BorMilestoneGapWarnThreshold         *uint64
+		BorSealStopBeforeSpanChange          *uint64
+		BorEagerMilestoneResync              *bool
+		BorGenesisSpanSource                 *string
+		BorParallelStateSync                 *int
+		BorStrictDifficultyValidation        *bool
+		BorMilestoneIDTTL                    *time.Duration
+		BorMilestoneStartupPolicy            *string
+		BorPersistStateSyncProgress          *bool
+		BorMilestoneOverlapPolicy            *string
+		BorTraceMilestoneProcessing          *bool
+		BorMaxSnapshotWalkback               *uint64
+		BorFutureBlockTolerance              *uint64
+		BorAutoRecoverSealing                *bool
+		BorPruneMilestonesOnSetHead          *bool
+		BorMilestoneDuringSnapSync           *string
+		OverrideVerkle                       *big.Int `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -325,6 +433,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.UseHeimdallApp != nil {
 		c.UseHeimdallApp = *dec.UseHeimdallApp
 	}
+	if dec.HeimdallProxyURL != nil {
+		c.HeimdallProxyURL = *dec.HeimdallProxyURL
+	}
+	if dec.HeimdallMaxConcurrentRequests != nil {
+		c.HeimdallMaxConcurrentRequests = *dec.HeimdallMaxConcurrentRequests
+	}
+	if dec.HeimdallAPIVersion != nil {
+		c.HeimdallAPIVersion = *dec.HeimdallAPIVersion
+	}
+	if dec.HeimdallRecordDir != nil {
+		c.HeimdallRecordDir = *dec.HeimdallRecordDir
+	}
 	if dec.BorLogs != nil {
 		c.BorLogs = *dec.BorLogs
 	}
@@ -334,6 +454,102 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DevFakeAuthor != nil {
 		c.DevFakeAuthor = *dec.DevFakeAuthor
 	}
+	if dec.DevFakeAuthors != nil {
+		c.DevFakeAuthors = dec.DevFakeAuthors
+	}
+	if dec.BorStrictExtraDataValidation != nil {
+		c.BorStrictExtraDataValidation = *dec.BorStrictExtraDataValidation
+	}
+	if dec.BorSnapshotCheckpointInterval != nil {
+		c.BorSnapshotCheckpointInterval = *dec.BorSnapshotCheckpointInterval
+	}
+	if dec.BorDisallowOutOfTurn != nil {
+		c.BorDisallowOutOfTurn = *dec.BorDisallowOutOfTurn
+	}
+	if dec.BorMaxSpanStaleness != nil {
+		c.BorMaxSpanStaleness = *dec.BorMaxSpanStaleness
+	}
+	if dec.BorForkTiebreak != nil {
+		c.BorForkTiebreak = *dec.BorForkTiebreak
+	}
+	if dec.BorMilestoneConfirmations != nil {
+		c.BorMilestoneConfirmations = *dec.BorMilestoneConfirmations
+	}
+	if dec.BorRecentsLimitPercent != nil {
+		c.BorRecentsLimitPercent = *dec.BorRecentsLimitPercent
+	}
+	if dec.BorVerifySpanCommit != nil {
+		c.BorVerifySpanCommit = *dec.BorVerifySpanCommit
+	}
+	if dec.BorVerifyGenesisContracts != nil {
+		c.BorVerifyGenesisContracts = *dec.BorVerifyGenesisContracts
+	}
+	if dec.BorMilestonePollInterval != nil {
+		c.BorMilestonePollInterval = *dec.BorMilestonePollInterval
+	}
+	if dec.BorMilestoneVerifyMissingDataPolicy != nil {
+		c.BorMilestoneVerifyMissingDataPolicy = *dec.BorMilestoneVerifyMissingDataPolicy
+	}
+	if dec.BorValidatorSetCacheSize != nil {
+		c.BorValidatorSetCacheSize = *dec.BorValidatorSetCacheSize
+	}
+	if dec.BorVerifyConcurrency != nil {
+		c.BorVerifyConcurrency = *dec.BorVerifyConcurrency
+	}
+	if dec.BorMilestoneFetchTimeout != nil {
+		c.BorMilestoneFetchTimeout = *dec.BorMilestoneFetchTimeout
+	}
+	if dec.BorMaxHeimdallClockSkew != nil {
+		c.BorMaxHeimdallClockSkew = *dec.BorMaxHeimdallClockSkew
+	}
+	if dec.BorMilestoneGapWarnThreshold != nil {
+		c.BorMilestoneGapWarnThreshold = *dec.BorMilestoneGapWarnThreshold
+	}
+	if dec.BorSealStopBeforeSpanChange != nil {
+		c.BorSealStopBeforeSpanChange = *dec.BorSealStopBeforeSpanChange
+	}
+	if dec.BorEagerMilestoneResync != nil {
+		c.BorEagerMilestoneResync = *dec.BorEagerMilestoneResync
+	}
+	if dec.BorGenesisSpanSource != nil {
+		c.BorGenesisSpanSource = *dec.BorGenesisSpanSource
+	}
+	if dec.BorParallelStateSync != nil {
+		c.BorParallelStateSync = *dec.BorParallelStateSync
+	}
+	if dec.BorStrictDifficultyValidation != nil {
+		c.BorStrictDifficultyValidation = *dec.BorStrictDifficultyValidation
+	}
+	if dec.BorMilestoneIDTTL != nil {
+		c.BorMilestoneIDTTL = *dec.BorMilestoneIDTTL
+	}
+	if dec.BorMilestoneStartupPolicy != nil {
+		c.BorMilestoneStartupPolicy = *dec.BorMilestoneStartupPolicy
+	}
+	if dec.BorPersistStateSyncProgress != nil {
+		c.BorPersistStateSyncProgress = *dec.BorPersistStateSyncProgress
+	}
+	if dec.BorMilestoneOverlapPolicy != nil {
+		c.BorMilestoneOverlapPolicy = *dec.BorMilestoneOverlapPolicy
+	}
+	if dec.BorTraceMilestoneProcessing != nil {
+		c.BorTraceMilestoneProcessing = *dec.BorTraceMilestoneProcessing
+	}
+	if dec.BorMaxSnapshotWalkback != nil {
+		c.BorMaxSnapshotWalkback = *dec.BorMaxSnapshotWalkback
+	}
+	if dec.BorFutureBlockTolerance != nil {
+		c.BorFutureBlockTolerance = *dec.BorFutureBlockTolerance
+	}
+	if dec.BorAutoRecoverSealing != nil {
+		c.BorAutoRecoverSealing = *dec.BorAutoRecoverSealing
+	}
+	if dec.BorPruneMilestonesOnSetHead != nil {
+		c.BorPruneMilestonesOnSetHead = *dec.BorPruneMilestonesOnSetHead
+	}
+	if dec.BorMilestoneDuringSnapSync != nil {
+		c.BorMilestoneDuringSnapSync = *dec.BorMilestoneDuringSnapSync
+	}
 	if dec.OverrideVerkle != nil {
 		c.OverrideVerkle = dec.OverrideVerkle
 	}
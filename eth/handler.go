@@ -126,6 +126,9 @@ type handler struct {
 
 	requiredBlocks map[uint64]common.Hash
 
+	milestoneResyncs    map[string]time.Time // Last eager milestone resync request sent to each peer
+	milestoneResyncLock sync.Mutex
+
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
 
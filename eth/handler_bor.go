@@ -3,13 +3,22 @@ package eth
 import (
 	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/bor"
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// milestoneResyncPeerInterval is the minimum interval between two eager milestone
+// resync requests sent to the same peer.
+const milestoneResyncPeerInterval = 30 * time.Second
+
 var (
 	// errCheckpoint is returned when we are unable to fetch the
 	// latest checkpoint from the local heimdall.
@@ -56,39 +65,36 @@ func (h *ethHandler) fetchWhitelistCheckpoint(ctx context.Context, bor *bor.Bor,
 
 // fetchWhitelistMilestone fetches the latest milestone from it's local heimdall
 // and verifies the data against bor data.
-func (h *ethHandler) fetchWhitelistMilestone(ctx context.Context, bor *bor.Bor, eth *Ethereum, verifier *borVerifier) (uint64, common.Hash, error) {
-	var (
-		num  uint64
-		hash common.Hash
-	)
-
+func (h *ethHandler) fetchWhitelistMilestone(ctx context.Context, bor *bor.Bor, eth *Ethereum, verifier *borVerifier) (*milestone.Milestone, error) {
 	// fetch latest milestone
 	milestone, err := bor.HeimdallClient.FetchMilestone(ctx)
 	if errors.Is(err, heimdall.ErrServiceUnavailable) {
 		log.Debug("Failed to fetch latest milestone for whitelisting", "err", err)
-		return num, hash, err
+		return nil, err
 	}
 
 	if err != nil {
 		log.Error("Failed to fetch latest milestone for whitelisting", "err", err)
-		return num, hash, errMilestone
+		return nil, errMilestone
 	}
 
-	num = milestone.EndBlock.Uint64()
-	hash = milestone.Hash
-
 	log.Info("Got new milestone from heimdall", "start", milestone.StartBlock.Uint64(), "end", milestone.EndBlock.Uint64(), "hash", milestone.Hash.String())
 
+	tracer := milestoneTracerFrom(ctx)
+	tracer.fetched(milestone)
+
 	// Verify if the milestone fetched can be added to the local whitelist entry or not
 	// If verified, it returns the hash of the end block of the milestone. If not,
 	// it will return appropriate error.
 	_, err = verifier.verify(ctx, eth, h, milestone.StartBlock.Uint64(), milestone.EndBlock.Uint64(), milestone.Hash.String()[2:], false)
 	if err != nil {
-		h.downloader.UnlockSprint(milestone.EndBlock.Uint64())
-		return num, hash, err
+		unlocked := h.downloader.UnlockSprintUpTo(milestone.EndBlock.Uint64())
+		tracer.trace("verification failed", "err", err, "sprintUnlocked", unlocked)
+
+		return milestone, err
 	}
 
-	return num, hash, nil
+	return milestone, nil
 }
 
 func (h *ethHandler) fetchNoAckMilestone(ctx context.Context, bor *bor.Bor) (string, error) {
@@ -133,3 +139,87 @@ func (h *ethHandler) fetchNoAckMilestoneByID(ctx context.Context, bor *bor.Bor,
 
 	return nil
 }
+
+// eagerMilestoneResync requests the end block of a milestone conflicting with the
+// local chain from all the peers, and syncs with the first one having it instead
+// of waiting for the regular sync to pick a peer. Each peer is asked at most once
+// per milestoneResyncPeerInterval, as the milestone keeps conflicting at every
+// poll until the chain is fetched.
+func (h *handler) eagerMilestoneResync(number uint64, hash common.Hash) {
+	var (
+		now     = time.Now()
+		synced  atomic.Bool
+		request int
+	)
+
+	for _, peer := range h.peers.allPeers() {
+		if !h.allowMilestoneResync(peer.ID(), now) {
+			continue
+		}
+
+		resCh := make(chan *eth.Response)
+
+		req, err := peer.RequestHeadersByHash(hash, 1, 0, false, resCh)
+		if err != nil {
+			peer.Log().Debug("Failed to request the milestone end block", "number", number, "hash", hash, "err", err)
+			continue
+		}
+
+		request++
+
+		go func(peer *eth.Peer, req *eth.Request) {
+			defer req.Close()
+
+			timeout := time.NewTimer(syncChallengeTimeout)
+			defer timeout.Stop()
+
+			select {
+			case res := <-resCh:
+				headers := ([]*types.Header)(*res.Res.(*eth.BlockHeadersPacket))
+				res.Done <- nil
+
+				if len(headers) != 1 || headers[0].Number.Uint64() != number || headers[0].Hash() != hash {
+					return
+				}
+
+				if synced.CompareAndSwap(false, true) {
+					peer.Log().Info("Syncing with peer having the milestone end block", "number", number, "hash", hash)
+					h.chainSync.syncWith(peer)
+				}
+			case <-timeout.C:
+			case <-h.quitSync:
+			}
+		}(peer.Peer, req)
+	}
+
+	if request > 0 {
+		milestoneEagerResyncMeter.Mark(1)
+		log.Info("Requested the end block of the conflicting milestone from peers", "number", number, "hash", hash, "peers", request)
+	}
+}
+
+// allowMilestoneResync reports whether an eager milestone resync request can be
+// sent to the given peer, and records it if so. The records older than the
+// interval are dropped along the way.
+func (h *handler) allowMilestoneResync(id string, now time.Time) bool {
+	h.milestoneResyncLock.Lock()
+	defer h.milestoneResyncLock.Unlock()
+
+	if h.milestoneResyncs == nil {
+		h.milestoneResyncs = make(map[string]time.Time)
+	}
+
+	for peer, last := range h.milestoneResyncs {
+		if now.Sub(last) >= milestoneResyncPeerInterval {
+			delete(h.milestoneResyncs, peer)
+		}
+	}
+
+	if _, ok := h.milestoneResyncs[id]; ok {
+		return false
+	}
+
+	h.milestoneResyncs[id] = now
+
+	return true
+}
@@ -2,6 +2,7 @@ package eth
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -14,6 +15,10 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/checkpoint"
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/milestone"
 	"github.com/ethereum/go-ethereum/consensus/bor/heimdall/span"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/downloader/whitelist"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
 )
 
 type mockHeimdall struct {
@@ -132,18 +137,18 @@ func fetchMilestoneTest(t *testing.T, heimdall *mockHeimdall, bor *bor.Bor, hand
 	// create a background context
 	ctx := context.Background()
 
-	_, _, err := handler.fetchWhitelistMilestone(ctx, bor, nil, verifier)
+	_, err := handler.fetchWhitelistMilestone(ctx, bor, nil, verifier)
 	require.Equal(t, err, errMilestone)
 
 	// create 4 mock checkpoints
 	milestones = createMockMilestones(4)
 
-	num, hash, err := handler.fetchWhitelistMilestone(ctx, bor, nil, verifier)
+	milestone, err := handler.fetchWhitelistMilestone(ctx, bor, nil, verifier)
 
 	// Check if we have expected result
 	require.Equal(t, err, nil)
-	require.Equal(t, milestones[len(milestones)-1].EndBlock.Uint64(), num)
-	require.Equal(t, milestones[len(milestones)-1].Hash, hash)
+	require.Equal(t, milestones[len(milestones)-1].EndBlock.Uint64(), milestone.EndBlock.Uint64())
+	require.Equal(t, milestones[len(milestones)-1].Hash, milestone.Hash)
 }
 
 func createMockCheckpoints(count int) []*checkpoint.Checkpoint {
@@ -187,3 +192,342 @@ func createMockMilestones(count int) []*milestone.Milestone {
 
 	return milestones
 }
+
+func TestParseMilestoneMissingDataPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := parseMilestoneMissingDataPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, milestoneMissingDataDefer, policy)
+
+	policy, err = parseMilestoneMissingDataPolicy("trust")
+	require.NoError(t, err)
+	require.Equal(t, milestoneMissingDataTrust, policy)
+
+	_, err = parseMilestoneMissingDataPolicy("unknown")
+	require.Error(t, err)
+}
+
+func TestAdoptHeimdallMilestone(t *testing.T) {
+	t.Parallel()
+
+	fetched := &milestone.Milestone{StartBlock: big.NewInt(1), EndBlock: big.NewInt(16), Hash: common.Hash{1}}
+
+	cases := []struct {
+		name      string
+		policy    milestoneStartupPolicy
+		persisted *persistedMilestone
+		adopt     bool
+	}{
+		{"no persisted milestone", milestoneStartupPersisted, nil, true},
+		{"same milestone", milestoneStartupHeimdall, &persistedMilestone{number: 16, hash: common.Hash{1}, verified: true}, false},
+		{"heimdall trusted", milestoneStartupHeimdall, &persistedMilestone{number: 32, hash: common.Hash{2}, verified: true}, true},
+		{"persisted trusted", milestoneStartupPersisted, &persistedMilestone{number: 8, hash: common.Hash{2}}, false},
+		{"newest persisted", milestoneStartupNewest, &persistedMilestone{number: 32, hash: common.Hash{2}, verified: true}, false},
+		{"newest heimdall", milestoneStartupNewest, &persistedMilestone{number: 8, hash: common.Hash{2}, verified: true}, true},
+		{"newest unverified", milestoneStartupNewest, &persistedMilestone{number: 32, hash: common.Hash{2}}, true},
+		{"conflicting", milestoneStartupNewest, &persistedMilestone{number: 16, hash: common.Hash{2}, verified: true}, true},
+	}
+
+	for _, c := range cases {
+		adopt, reason := adoptHeimdallMilestone(c.policy, c.persisted, fetched)
+		require.Equal(t, c.adopt, adopt, c.name)
+		require.NotEmpty(t, reason, c.name)
+	}
+
+	policy, err := parseMilestoneStartupPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, milestoneStartupNewest, policy)
+
+	_, err = parseMilestoneStartupPolicy("unknown")
+	require.Error(t, err)
+}
+
+func TestPersistedMilestoneFloor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s     = &Ethereum{persistedMilestoneFloor: 32}
+		calls int
+	)
+
+	verify := s.withPersistedMilestoneFloor(func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		calls++
+		return hash, nil
+	})
+
+	// The older milestones of heimdall are ignored, not the checkpoints
+	_, err := verify(context.Background(), s, nil, 1, 16, "a", false)
+	require.ErrorIs(t, err, errMilestoneBelowPersisted)
+
+	_, err = verify(context.Background(), s, nil, 1, 16, "a", true)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// Until heimdall catches up
+	_, err = verify(context.Background(), s, nil, 17, 32, "b", false)
+	require.NoError(t, err)
+	require.Zero(t, s.persistedMilestoneFloor)
+
+	_, err = verify(context.Background(), s, nil, 1, 16, "a", false)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestMilestoneTracer(t *testing.T) {
+	t.Parallel()
+
+	// Disabled, nothing is traced
+	s := &Ethereum{config: &ethconfig.Config{}}
+
+	tracer := s.newMilestoneTracer()
+	require.Nil(t, tracer)
+	require.Nil(t, milestoneTracerFrom(withMilestoneTracer(context.Background(), tracer)))
+
+	tracer.trace("ignored")
+
+	// Enabled, the decisions are tagged with the fetched milestone
+	s.config.BorTraceMilestoneProcessing = true
+
+	tracer = s.newMilestoneTracer()
+	ctx := withMilestoneTracer(context.Background(), tracer)
+
+	var traced []uint64
+
+	verifier := newBorVerifier()
+	verifier.setVerify(func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		milestoneTracerFrom(ctx).trace("verified")
+		traced = append(traced, end)
+
+		return hash, nil
+	})
+
+	milestones := createMockMilestones(1)
+	milestones[0].MilestoneID = "milestoneID1"
+
+	heimdall := &mockHeimdall{fetchMilestone: func(context.Context) (*milestone.Milestone, error) { return milestones[0], nil }}
+
+	_, err := (&ethHandler{}).fetchWhitelistMilestone(ctx, &bor.Bor{HeimdallClient: heimdall}, s, verifier)
+	require.NoError(t, err)
+
+	require.Equal(t, []uint64{milestones[0].EndBlock.Uint64()}, traced)
+	require.Equal(t, "milestoneID1", tracer.id)
+	require.Equal(t, milestones[0].StartBlock.Uint64(), tracer.start)
+	require.Equal(t, 2, tracer.step, "expected the fetch and the verification traced")
+}
+
+func TestMilestoneFetchTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s     = &Ethereum{}
+		calls int
+		err   error
+	)
+
+	verify := s.withMilestoneFetchTimeout(func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		calls++
+		return hash, err
+	}, 50*time.Millisecond)
+
+	// The conflicting milestone is retried until the timeout
+	err = errHashMismatch
+	_, got := verify(context.Background(), s, nil, 1, 10, "a", false)
+	require.ErrorIs(t, got, errHashMismatch)
+
+	err = errMissingBlocks
+	_, got = verify(context.Background(), s, nil, 1, 10, "a", false)
+	require.ErrorIs(t, got, errMissingBlocks)
+	require.Equal(t, 2, calls)
+
+	// Then it's given up, without verifying it again
+	time.Sleep(50 * time.Millisecond)
+
+	_, got = verify(context.Background(), s, nil, 1, 10, "a", false)
+	require.ErrorIs(t, got, errMilestoneFetchFailed)
+
+	_, got = verify(context.Background(), s, nil, 1, 10, "a", false)
+	require.ErrorIs(t, got, errMilestoneFetchFailed)
+	require.Equal(t, 3, calls)
+
+	// A new milestone is verified again
+	err = nil
+	_, got = verify(context.Background(), s, nil, 11, 20, "b", false)
+	require.NoError(t, got)
+	require.Nil(t, s.milestoneConflict)
+	require.Equal(t, 4, calls)
+}
+
+func TestMilestoneSnapSyncPolicy(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s       = &Ethereum{}
+		handler = &ethHandler{}
+		calls   int
+	)
+
+	verify := s.withMilestoneSnapSyncPolicy(func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		calls++
+		return hash, nil
+	})
+
+	// Verified as usual out of the snap sync
+	_, err := verify(context.Background(), s, handler, 1, 16, "a", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// Deferred as a future milestone during the snap sync, not the checkpoints
+	handler.snapSync.Store(true)
+
+	_, err = verify(context.Background(), s, handler, 17, 32, "b", false)
+	require.ErrorIs(t, err, errMilestoneDeferredSnapSync)
+	require.ErrorIs(t, err, errMissingBlocks)
+
+	_, err = verify(context.Background(), s, handler, 1, 32, "b", true)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Or skipped
+	s.milestoneSnapSyncPolicy = milestoneSnapSyncSkip
+
+	_, err = verify(context.Background(), s, handler, 17, 32, "b", false)
+	require.ErrorIs(t, err, errMilestoneSkippedSnapSync)
+	require.NotErrorIs(t, err, errMissingBlocks)
+	require.Equal(t, 2, calls)
+
+	// Verified again once the snap sync completes
+	handler.snapSync.Store(false)
+
+	_, err = verify(context.Background(), s, handler, 17, 32, "b", false)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	policy, err := parseMilestoneSnapSyncPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, milestoneSnapSyncDefer, policy)
+
+	_, err = parseMilestoneSnapSyncPolicy("unknown")
+	require.Error(t, err)
+}
+
+// TestFetchWhitelistMilestoneUnlock checks that a milestone failing the verification
+// releases the locked sprint it supersedes, and only that one.
+func TestFetchWhitelistMilestoneUnlock(t *testing.T) {
+	t.Parallel()
+
+	service := whitelist.NewService(rawdb.NewMemoryDatabase())
+
+	service.LockMutex(16)
+	service.UnlockMutex(true, "milestoneID1", 16, common.Hash{16})
+
+	var (
+		h        = &ethHandler{downloader: &downloader.Downloader{ChainValidator: service}}
+		verifier = newBorVerifier()
+		latest   = &milestone.Milestone{StartBlock: big.NewInt(1), EndBlock: big.NewInt(8)}
+		heimdall = &mockHeimdall{fetchMilestone: func(context.Context) (*milestone.Milestone, error) { return latest, nil }}
+	)
+
+	verifier.setVerify(func(ctx context.Context, eth *Ethereum, handler *ethHandler, start uint64, end uint64, hash string, isCheckpoint bool) (string, error) {
+		return "", errHashMismatch
+	})
+
+	// An older milestone leaves the lock alone
+	_, err := h.fetchWhitelistMilestone(context.Background(), &bor.Bor{HeimdallClient: heimdall}, nil, verifier)
+	require.ErrorIs(t, err, errHashMismatch)
+
+	locked, number, _, _ := service.GetLockedSprintInfo()
+	require.True(t, locked)
+	require.Equal(t, uint64(16), number)
+
+	// A newer one supersedes it
+	latest.StartBlock, latest.EndBlock = big.NewInt(17), big.NewInt(24)
+
+	_, err = h.fetchWhitelistMilestone(context.Background(), &bor.Bor{HeimdallClient: heimdall}, nil, verifier)
+	require.ErrorIs(t, err, errHashMismatch)
+
+	locked, _, _, _ = service.GetLockedSprintInfo()
+	require.False(t, locked)
+}
+
+func TestAllowMilestoneResync(t *testing.T) {
+	t.Parallel()
+
+	var (
+		h   = &handler{}
+		now = time.Now()
+	)
+
+	// Each peer is asked once per interval
+	require.True(t, h.allowMilestoneResync("a", now))
+	require.True(t, h.allowMilestoneResync("b", now))
+	require.False(t, h.allowMilestoneResync("a", now.Add(milestoneResyncPeerInterval/2)))
+
+	// And again once the interval elapsed, dropping the expired records
+	require.True(t, h.allowMilestoneResync("a", now.Add(milestoneResyncPeerInterval)))
+	require.Len(t, h.milestoneResyncs, 1)
+}
+
+func TestHeadScore(t *testing.T) {
+	t.Parallel()
+
+	var (
+		head  = common.Hash{0x1}
+		known = common.Hash{0x2}
+	)
+
+	canonical := func(hash common.Hash) bool { return hash == known }
+
+	// Peers on the local chain don't compete with the head
+	score := headScore(10, head, big.NewInt(100), []peerHead{
+		{id: "a", hash: head, td: big.NewInt(100)},
+		{id: "b", hash: known, td: big.NewInt(90)},
+	}, canonical)
+	require.Nil(t, score.Alternative)
+	require.Nil(t, score.Margin)
+	require.Equal(t, big.NewInt(100), score.TD)
+
+	// The best competing head is the alternative
+	score = headScore(10, head, big.NewInt(100), []peerHead{
+		{id: "b", hash: known, td: big.NewInt(90)},
+		{id: "c", enode: "enode://c", hash: common.Hash{0x3}, td: big.NewInt(97)},
+		{id: "d", hash: common.Hash{0x4}, td: big.NewInt(95)},
+	}, canonical)
+	require.Equal(t, &AlternativeHead{Hash: common.Hash{0x3}, TD: big.NewInt(97), Peer: "c", Enode: "enode://c"}, score.Alternative)
+	require.Equal(t, big.NewInt(3), score.Margin)
+
+	// A heavier competing head gives a negative margin
+	score = headScore(10, head, big.NewInt(100), []peerHead{
+		{id: "e", hash: common.Hash{0x5}, td: big.NewInt(104)},
+	}, canonical)
+	require.Equal(t, big.NewInt(-4), score.Margin)
+}
+
+type clockSkewFake struct {
+	skew time.Duration
+	err  error
+}
+
+func (c *clockSkewFake) ClockSkew(context.Context) (time.Duration, error) {
+	return c.skew, c.err
+}
+
+func TestHeimdallClockSkew(t *testing.T) {
+	t.Parallel()
+
+	s := &Ethereum{config: &ethconfig.Config{BorMaxHeimdallClockSkew: 2 * time.Second}}
+	api := NewBorAPI(s)
+
+	// Not measured yet
+	require.Empty(t, api.Status().HeimdallClockSkew)
+
+	s.checkHeimdallClockSkew(context.Background(), &clockSkewFake{skew: -3 * time.Second})
+	require.Equal(t, "-3s", api.Status().HeimdallClockSkew)
+
+	// A failed measure keeps the last one
+	s.checkHeimdallClockSkew(context.Background(), &clockSkewFake{err: errors.New("unreachable")})
+	require.Equal(t, "-3s", api.Status().HeimdallClockSkew)
+
+	s.checkHeimdallClockSkew(context.Background(), &clockSkewFake{skew: 500 * time.Millisecond})
+	require.Equal(t, "500ms", api.Status().HeimdallClockSkew)
+}
@@ -54,7 +54,8 @@ type chainSyncer struct {
 	forced      bool // true when force timer fired
 	warned      time.Time
 	peerEventCh chan struct{}
-	doneCh      chan error // non-nil when sync is running
+	syncPeerCh  chan *eth.Peer // Peers to sync with right away, regardless of their total difficulty
+	doneCh      chan error     // non-nil when sync is running
 }
 
 // chainSyncOp is a scheduled sync operation.
@@ -70,6 +71,7 @@ func newChainSyncer(handler *handler) *chainSyncer {
 	return &chainSyncer{
 		handler:     handler,
 		peerEventCh: make(chan struct{}),
+		syncPeerCh:  make(chan *eth.Peer),
 	}
 }
 
@@ -85,6 +87,18 @@ func (cs *chainSyncer) handlePeerEvent() bool {
 	}
 }
 
+// syncWith asks the syncer to sync with the given peer right away, e.g. because it
+// has the chain of a milestone conflicting with the local one. The request is
+// dropped if a sync is already running.
+func (cs *chainSyncer) syncWith(peer *eth.Peer) bool {
+	select {
+	case cs.syncPeerCh <- peer:
+		return true
+	case <-cs.handler.quitSync:
+		return false
+	}
+}
+
 // loop runs in its own goroutine and launches the sync when necessary.
 func (cs *chainSyncer) loop() {
 	defer cs.handler.wg.Done()
@@ -108,6 +122,11 @@ func (cs *chainSyncer) loop() {
 		select {
 		case <-cs.peerEventCh:
 			// Peer information changed, recheck.
+		case peer := <-cs.syncPeerCh:
+			if cs.doneCh == nil {
+				mode, _ := cs.modeAndLocalHead()
+				cs.startSync(peerToSyncOp(mode, peer))
+			}
 		case err := <-cs.doneCh:
 			cs.doneCh = nil
 			cs.force.Reset(forceSyncCycle)
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -259,10 +260,19 @@ type ChainValidator interface {
 	ProcessFutureMilestone(num uint64, hash common.Hash)
 	PurgeWhitelistedCheckpoint()
 	PurgeWhitelistedMilestone()
+	PruneAbove(number uint64)
 
 	LockMutex(endBlockNum uint64) bool
 	UnlockMutex(doLock bool, milestoneId string, endBlockNum uint64, endBlockHash common.Hash)
-	UnlockSprint(endBlockNum uint64)
+	UnlockSprint(endBlockNum uint64) bool
+	UnlockSprintUpTo(endBlockNum uint64) bool
 	RemoveMilestoneID(milestoneId string)
 	GetMilestoneIDsList() []string
+	RecordMilestone(milestoneId string, startBlock uint64, endBlock uint64)
+	GetMilestoneForBlock(number uint64) (bool, string, uint64, uint64)
+	SubscribeMilestoneIDListChange(ch chan<- int) Subscription
+	GetFutureMilestones() ([]uint64, []common.Hash)
+	GetLockedSprintInfo() (bool, uint64, common.Hash, []string)
+	ExpireMilestoneIDs(before time.Time) []string
+	CheckMilestoneOverlap(startBlock uint64, endBlock uint64, hashAt func(number uint64) common.Hash) error
 }
@@ -24,6 +24,8 @@ import (
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/consensus/bor"
+	"github.com/ethereum/go-ethereum/consensus/bor/heimdall"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
@@ -102,6 +104,9 @@ type Config struct {
 	// Heimdall has the heimdall connection related settings
 	Heimdall *HeimdallConfig `hcl:"heimdall,block" toml:"heimdall,block"`
 
+	// Bor has the bor consensus engine related settings
+	Bor *BorConfig `hcl:"bor,block" toml:"bor,block"`
+
 	// TxPool has the transaction pool related settings
 	TxPool *TxPoolConfig `hcl:"txpool,block" toml:"txpool,block"`
 
@@ -137,6 +142,9 @@ type Config struct {
 	// Develop Fake Author mode to produce blocks without authorisation
 	DevFakeAuthor bool `hcl:"devfakeauthor,optional" toml:"devfakeauthor,optional"`
 
+	// DevFakeAuthors are the fake authors the proposer rotates through at every sprint in DevFakeAuthor mode
+	DevFakeAuthors []string `hcl:"devfakeauthors,optional" toml:"devfakeauthors,optional"`
+
 	// Pprof has the pprof related settings
 	Pprof *PprofConfig `hcl:"pprof,block" toml:"pprof,block"`
 }
@@ -262,6 +270,120 @@ type HeimdallConfig struct {
 
 	// UseHeimdallApp is used to fetch data from heimdall app when running heimdall as a child process
 	UseHeimdallApp bool `hcl:"bor.useheimdallapp,optional" toml:"bor.useheimdallapp,optional"`
+
+	// ProxyURL is the url of a caching proxy which all the heimdall calls are routed through
+	ProxyURL string `hcl:"proxy-url,optional" toml:"proxy-url,optional"`
+
+	// MaxConcurrentRequests is the maximum number of heimdall calls in flight (0 = unlimited)
+	MaxConcurrentRequests int `hcl:"max-concurrent-requests,optional" toml:"max-concurrent-requests,optional"`
+
+	// APIVersion is the version of the heimdall REST api, selecting the paths of its endpoints
+	APIVersion string `hcl:"api-version,optional" toml:"api-version,optional"`
+
+	// RecordDir is the directory which all the heimdall responses are recorded to, for a later replay
+	RecordDir string `hcl:"record-dir,optional" toml:"record-dir,optional"`
+}
+
+type BorConfig struct {
+	// StrictExtraData enables the strict validation of the header's extra-data layout
+	StrictExtraData bool `hcl:"strictextradata,optional" toml:"strictextradata,optional"`
+
+	// SnapshotCheckpointInterval is the number of blocks after which a validator snapshot is stored to the database
+	SnapshotCheckpointInterval uint64 `hcl:"snapshotcheckpointinterval,optional" toml:"snapshotcheckpointinterval,optional"`
+
+	// AllowOutOfTurn enables sealing blocks out-of-turn when the in-turn proposer is down
+	AllowOutOfTurn bool `hcl:"allowoutofturn,optional" toml:"allowoutofturn,optional"`
+
+	// ForkTiebreak is the policy used to choose between two heads of equal total difficulty and height
+	ForkTiebreak string `hcl:"forktiebreak,optional" toml:"forktiebreak,optional"`
+
+	// MaxSpanStaleness is the number of blocks before the end of the current span from which sealing
+	// is paused until the next span is fetched (0 = disabled)
+	MaxSpanStaleness uint64 `hcl:"maxspanstaleness,optional" toml:"maxspanstaleness,optional"`
+
+	// MilestoneConfirmations is the number of consecutive consistent milestones needed before a milestone is whitelisted
+	MilestoneConfirmations uint64 `hcl:"milestoneconfirmations,optional" toml:"milestoneconfirmations,optional"`
+
+	// RecentsLimitPercent is the maximum size of the snapshot recents, in percent of the validator set size plus one
+	RecentsLimitPercent uint64 `hcl:"recentslimitpercent,optional" toml:"recentslimitpercent,optional"`
+
+	// VerifySpanCommit enables checking the span committed in a span boundary block against heimdall before sealing it
+	VerifySpanCommit bool `hcl:"verifyspancommit,optional" toml:"verifyspancommit,optional"`
+
+	// VerifyGenesisContracts checks at startup that the validator set and state receiver
+	// contracts have code in the genesis state
+	VerifyGenesisContracts bool `hcl:"verifygenesiscontracts,optional" toml:"verifygenesiscontracts,optional"`
+
+	// MilestonePollInterval is the interval between the fetches of the latest milestone from heimdall
+	MilestonePollInterval    time.Duration `hcl:"-,optional" toml:"-"`
+	MilestonePollIntervalRaw string        `hcl:"milestonepollinterval,optional" toml:"milestonepollinterval,optional"`
+
+	// MilestoneVerifyMissingDataPolicy is the behaviour of the milestone verification when the end block isn't available locally
+	MilestoneVerifyMissingDataPolicy string `hcl:"milestoneverifymissingdatapolicy,optional" toml:"milestoneverifymissingdatapolicy,optional"`
+
+	// ValidatorSetCacheSize is the number of validator set contract reads cached by block hash (0 = disabled)
+	ValidatorSetCacheSize int `hcl:"validatorsetcachesize,optional" toml:"validatorsetcachesize,optional"`
+
+	// VerifyConcurrency is the maximum number of headers verified concurrently in a batch (0 = number of CPUs)
+	VerifyConcurrency int `hcl:"verifyconcurrency,optional" toml:"verifyconcurrency,optional"`
+
+	// MilestoneFetchTimeout is the time after which the node stops fetching the chain of a milestone conflicting with the local chain (0 = never)
+	MilestoneFetchTimeout    time.Duration `hcl:"-,optional" toml:"-"`
+	MilestoneFetchTimeoutRaw string        `hcl:"milestonefetchtimeout,optional" toml:"milestonefetchtimeout,optional"`
+
+	// MaxHeimdallClockSkew is the skew of the local clock from heimdall's, periodically measured, over which a warning is logged (0 = not measured)
+	MaxHeimdallClockSkew    time.Duration `hcl:"-,optional" toml:"-"`
+	MaxHeimdallClockSkewRaw string        `hcl:"maxheimdallclockskew,optional" toml:"maxheimdallclockskew,optional"`
+
+	// MilestoneGapWarnThreshold is the gap between the head and the latest milestone, in blocks, over which a finality warning is logged (0 = disabled)
+	MilestoneGapWarnThreshold uint64 `hcl:"milestonegapwarnthreshold,optional" toml:"milestonegapwarnthreshold,optional"`
+
+	// SealStopBeforeSpanChange is the number of blocks before the end of the span the sealing stops if the signer isn't a producer of the next span (0 = disabled)
+	SealStopBeforeSpanChange uint64 `hcl:"sealstopbeforespanchange,optional" toml:"sealstopbeforespanchange,optional"`
+
+	// EagerMilestoneResync requests the chain of a milestone conflicting with the local chain from all the peers right away,
+	// instead of waiting for the regular sync
+	EagerMilestoneResync bool `hcl:"eagermilestoneresync,optional" toml:"eagermilestoneresync,optional"`
+
+	// GenesisSpanSource is the source of the validator set of the genesis span (span 0): the genesis contract, heimdall's span 0, or the genesis contract checked against heimdall
+	GenesisSpanSource string `hcl:"genesisspansource,optional" toml:"genesisspansource,optional"`
+
+	// ParallelStateSync is the maximum number of state receiver contracts whose state-sync events of a sprint are executed speculatively in parallel, experimental (0 = disabled)
+	ParallelStateSync int `hcl:"parallelstatesync,optional" toml:"parallelstatesync,optional"`
+
+	// StrictDifficulty enables the check of the headers' difficulty against the turn of their signer before the validator list
+	StrictDifficulty bool `hcl:"strictdifficulty,optional" toml:"strictdifficulty,optional"`
+
+	// MilestoneIDTTL is the time after which a milestone id locking a sprint, neither confirmed nor rejected by heimdall, is dropped (0 = never)
+	MilestoneIDTTL    time.Duration `hcl:"-,optional" toml:"-"`
+	MilestoneIDTTLRaw string        `hcl:"milestoneidttl,optional" toml:"milestoneidttl,optional"`
+
+	// MilestoneStartupPolicy is the reconciliation of the persisted milestone with heimdall's latest one on startup
+	MilestoneStartupPolicy string `hcl:"milestonestartuppolicy,optional" toml:"milestonestartuppolicy,optional"`
+
+	// PersistStateSyncProgress enables the persistence of the last applied state-sync event with the block which applied it
+	PersistStateSyncProgress bool `hcl:"persiststatesyncprogress,optional" toml:"persiststatesyncprogress,optional"`
+
+	// MilestoneOverlapPolicy is the behaviour of the milestone verification when a milestone covering the whitelisted milestone disagrees with it over their overlap
+	MilestoneOverlapPolicy string `hcl:"milestoneoverlappolicy,optional" toml:"milestoneoverlappolicy,optional"`
+
+	// TraceMilestoneProcessing enables the logging of every decision taken while processing each milestone, tagged with the milestone id
+	TraceMilestoneProcessing bool `hcl:"tracemilestoneprocessing,optional" toml:"tracemilestoneprocessing,optional"`
+
+	// MaxSnapshotWalkback is the most headers walked back to reconstruct a snapshot before failing as its checkpoint snapshot is missing (0 = unbounded)
+	MaxSnapshotWalkback uint64 `hcl:"maxsnapshotwalkback,optional" toml:"maxsnapshotwalkback,optional"`
+
+	// FutureBlockTolerance is the number of seconds a header's timestamp may be ahead of the local clock before it's deferred as a future block, for clock skew
+	FutureBlockTolerance uint64 `hcl:"futureblocktolerance,optional" toml:"futureblocktolerance,optional"`
+
+	// AutoRecoverSealing enables restarting the miner once when the node misses a block it's the in-turn proposer of
+	AutoRecoverSealing bool `hcl:"autorecoversealing,optional" toml:"autorecoversealing,optional"`
+
+	// PruneMilestonesOnSetHead enables the pruning of the milestone state above the new head when the head is set back
+	PruneMilestonesOnSetHead bool `hcl:"prunemilestonesonsethead,optional" toml:"prunemilestonesonsethead,optional"`
+
+	// MilestoneDuringSnapSync is the behaviour of the milestone processing while the snap sync is in progress, the blocks needed to verify the milestones not being available yet
+	MilestoneDuringSnapSync string `hcl:"milestoneduringsnapsync,optional" toml:"milestoneduringsnapsync,optional"`
 }
 
 type TxPoolConfig struct {
@@ -587,6 +709,9 @@ type ParallelEVMConfig struct {
 	Enable bool `hcl:"enable,optional" toml:"enable,optional"`
 
 	SpeculativeProcesses int `hcl:"procs,optional" toml:"procs,optional"`
+
+	// SequentialTo are the addresses of hot contracts, whose transactions are executed one after the other
+	SequentialTo []string `hcl:"sequentialto,optional" toml:"sequentialto,optional"`
 }
 
 func DefaultConfig() *Config {
@@ -633,6 +758,40 @@ func DefaultConfig() *Config {
 			URL:         "http://localhost:1317",
 			Without:     false,
 			GRPCAddress: "",
+			APIVersion:  heimdall.DefaultAPIVersion,
+		},
+		Bor: &BorConfig{
+			StrictExtraData:                  false,
+			SnapshotCheckpointInterval:       1024,
+			AllowOutOfTurn:                   true,
+			ForkTiebreak:                     "highesthash",
+			MaxSpanStaleness:                 0,
+			MilestoneConfirmations:           1,
+			RecentsLimitPercent:              50,
+			VerifySpanCommit:                 false,
+			VerifyGenesisContracts:           true,
+			MilestonePollInterval:            12 * time.Second,
+			MilestoneVerifyMissingDataPolicy: "defer",
+			ValidatorSetCacheSize:            128,
+			VerifyConcurrency:                0,
+			MilestoneFetchTimeout:            0,
+			MaxHeimdallClockSkew:             2 * time.Second,
+			MilestoneGapWarnThreshold:        0,
+			SealStopBeforeSpanChange:         0,
+			EagerMilestoneResync:             false,
+			GenesisSpanSource:                "contract",
+			ParallelStateSync:                0,
+			StrictDifficulty:                 false,
+			MilestoneIDTTL:                   0,
+			MilestoneStartupPolicy:           "newest",
+			PersistStateSyncProgress:         false,
+			MilestoneOverlapPolicy:           "reject",
+			TraceMilestoneProcessing:         false,
+			MaxSnapshotWalkback:              0,
+			FutureBlockTolerance:             0,
+			AutoRecoverSealing:               false,
+			PruneMilestonesOnSetHead:         true,
+			MilestoneDuringSnapSync:          "defer",
 		},
 		SyncMode: "full",
 		GcMode:   "full",
@@ -769,7 +928,8 @@ func DefaultConfig() *Config {
 			Period:   0,
 			GasLimit: 11500000,
 		},
-		DevFakeAuthor: false,
+		DevFakeAuthor:  false,
+		DevFakeAuthors: []string{},
 		Pprof: &PprofConfig{
 			Enabled:          false,
 			Port:             6060,
@@ -781,6 +941,7 @@ func DefaultConfig() *Config {
 		ParallelEVM: &ParallelEVMConfig{
 			Enable:               true,
 			SpeculativeProcesses: 8,
+			SequentialTo:         []string{},
 		},
 	}
 }
@@ -837,6 +998,10 @@ func (c *Config) fillTimeDurations() error {
 		{"txpool.rejournal", &c.TxPool.Rejournal, &c.TxPool.RejournalRaw},
 		{"cache.timeout", &c.Cache.TrieTimeout, &c.Cache.TrieTimeoutRaw},
 		{"p2p.txarrivalwait", &c.P2P.TxArrivalWait, &c.P2P.TxArrivalWaitRaw},
+		{"bor.milestonepollinterval", &c.Bor.MilestonePollInterval, &c.Bor.MilestonePollIntervalRaw},
+		{"bor.milestoneidttl", &c.Bor.MilestoneIDTTL, &c.Bor.MilestoneIDTTLRaw},
+		{"bor.milestonefetchtimeout", &c.Bor.MilestoneFetchTimeout, &c.Bor.MilestoneFetchTimeoutRaw},
+		{"bor.maxheimdallclockskew", &c.Bor.MaxHeimdallClockSkew, &c.Bor.MaxHeimdallClockSkewRaw},
 	}
 
 	for _, x := range tds {
@@ -919,6 +1084,55 @@ func (c *Config) buildEth(stack *node.Node, accountManager *accounts.Manager) (*
 	n.RunHeimdall = c.Heimdall.RunHeimdall
 	n.RunHeimdallArgs = c.Heimdall.RunHeimdallArgs
 	n.UseHeimdallApp = c.Heimdall.UseHeimdallApp
+	n.HeimdallProxyURL = c.Heimdall.ProxyURL
+	n.HeimdallMaxConcurrentRequests = c.Heimdall.MaxConcurrentRequests
+	n.HeimdallAPIVersion = c.Heimdall.APIVersion
+	n.HeimdallRecordDir = c.Heimdall.RecordDir
+
+	if err := heimdall.ValidateAPIVersion(c.Heimdall.APIVersion); err != nil {
+		return nil, err
+	}
+
+	// bor consensus engine
+	n.BorStrictExtraDataValidation = c.Bor.StrictExtraData
+	n.BorSnapshotCheckpointInterval = c.Bor.SnapshotCheckpointInterval
+	n.BorDisallowOutOfTurn = !c.Bor.AllowOutOfTurn
+	n.BorForkTiebreak = c.Bor.ForkTiebreak
+	n.BorMaxSpanStaleness = c.Bor.MaxSpanStaleness
+	n.BorMilestoneConfirmations = c.Bor.MilestoneConfirmations
+	n.BorRecentsLimitPercent = c.Bor.RecentsLimitPercent
+	n.BorVerifySpanCommit = c.Bor.VerifySpanCommit
+	n.BorVerifyGenesisContracts = c.Bor.VerifyGenesisContracts
+	n.BorMilestonePollInterval = c.Bor.MilestonePollInterval
+	n.BorMilestoneVerifyMissingDataPolicy = c.Bor.MilestoneVerifyMissingDataPolicy
+	n.BorValidatorSetCacheSize = c.Bor.ValidatorSetCacheSize
+	n.BorVerifyConcurrency = c.Bor.VerifyConcurrency
+	n.BorMilestoneFetchTimeout = c.Bor.MilestoneFetchTimeout
+	n.BorMaxHeimdallClockSkew = c.Bor.MaxHeimdallClockSkew
+	n.BorMilestoneGapWarnThreshold = c.Bor.MilestoneGapWarnThreshold
+	n.BorSealStopBeforeSpanChange = c.Bor.SealStopBeforeSpanChange
+	n.BorEagerMilestoneResync = c.Bor.EagerMilestoneResync
+	n.BorGenesisSpanSource = c.Bor.GenesisSpanSource
+	n.BorParallelStateSync = c.Bor.ParallelStateSync
+	n.BorStrictDifficultyValidation = c.Bor.StrictDifficulty
+	n.BorMilestoneIDTTL = c.Bor.MilestoneIDTTL
+	n.BorMilestoneStartupPolicy = c.Bor.MilestoneStartupPolicy
+	n.BorPersistStateSyncProgress = c.Bor.PersistStateSyncProgress
+	n.BorMilestoneOverlapPolicy = c.Bor.MilestoneOverlapPolicy
+	n.BorTraceMilestoneProcessing = c.Bor.TraceMilestoneProcessing
+	n.BorMaxSnapshotWalkback = c.Bor.MaxSnapshotWalkback
+	n.BorFutureBlockTolerance = c.Bor.FutureBlockTolerance
+	n.BorAutoRecoverSealing = c.Bor.AutoRecoverSealing
+	n.BorPruneMilestonesOnSetHead = c.Bor.PruneMilestonesOnSetHead
+	n.BorMilestoneDuringSnapSync = c.Bor.MilestoneDuringSnapSync
+
+	if err := bor.ValidateRecentsLimitPercent(c.Bor.RecentsLimitPercent); err != nil {
+		return nil, fmt.Errorf("invalid bor.recentslimitpercent: %w", err)
+	}
+
+	if c.Bor.MilestonePollInterval < time.Second {
+		return nil, fmt.Errorf("bor.milestonepollinterval must be at least 1s, got %v", c.Bor.MilestonePollInterval)
+	}
 
 	// Developer Fake Author for producing blocks without authorisation on bor consensus
 	n.DevFakeAuthor = c.DevFakeAuthor
@@ -926,6 +1140,14 @@ func (c *Config) buildEth(stack *node.Node, accountManager *accounts.Manager) (*
 	// Developer Fake Author for producing blocks without authorisation on bor consensus
 	n.DevFakeAuthor = c.DevFakeAuthor
 
+	for _, author := range c.DevFakeAuthors {
+		if !common.IsHexAddress(author) {
+			return nil, fmt.Errorf("dev fake author is not an address: %s", author)
+		}
+
+		n.DevFakeAuthors = append(n.DevFakeAuthors, common.HexToAddress(author))
+	}
+
 	// gas price oracle
 	{
 		n.GPO.Blocks = int(c.Gpo.Blocks)
@@ -1174,6 +1396,14 @@ func (c *Config) buildEth(stack *node.Node, accountManager *accounts.Manager) (*
 
 	n.ParallelEVM.Enable = c.ParallelEVM.Enable
 	n.ParallelEVM.SpeculativeProcesses = c.ParallelEVM.SpeculativeProcesses
+
+	for _, addr := range c.ParallelEVM.SequentialTo {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("parallelevm.sequentialto is not an address: %s", addr)
+		}
+
+		n.ParallelEVM.SequentialToAddresses = append(n.ParallelEVM.SequentialToAddresses, common.HexToAddress(addr))
+	}
 	n.RPCReturnDataLimit = c.RPCReturnDataLimit
 
 	if c.Ancient != "" {
@@ -166,6 +166,12 @@ func (c *Command) Flags(config *Config) *flagset.Flagset {
 		Value:   &c.cliConfig.DevFakeAuthor,
 		Default: c.cliConfig.DevFakeAuthor,
 	})
+	f.SliceStringFlag(&flagset.SliceStringFlag{
+		Name:    "bor.devfakeauthors",
+		Usage:   "Comma separated fake authors the proposer rotates through at every sprint [dev mode] : Use with '--bor.devfakeauthor'",
+		Value:   &c.cliConfig.DevFakeAuthors,
+		Default: c.cliConfig.DevFakeAuthors,
+	})
 	f.StringFlag(&flagset.StringFlag{
 		Name:    "bor.heimdallgRPC",
 		Usage:   "Address of Heimdall gRPC service",
@@ -190,6 +196,218 @@ func (c *Command) Flags(config *Config) *flagset.Flagset {
 		Value:   &c.cliConfig.Heimdall.UseHeimdallApp,
 		Default: c.cliConfig.Heimdall.UseHeimdallApp,
 	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.heimdallproxy",
+		Usage:   "URL of a caching proxy for the Heimdall REST api, all Heimdall calls are routed through it when set",
+		Value:   &c.cliConfig.Heimdall.ProxyURL,
+		Default: c.cliConfig.Heimdall.ProxyURL,
+	})
+	f.IntFlag(&flagset.IntFlag{
+		Name:    "bor.heimdallmaxconcurrentrequests",
+		Usage:   "Maximum number of concurrent Heimdall requests, further requests wait for a free slot (0 = unlimited)",
+		Value:   &c.cliConfig.Heimdall.MaxConcurrentRequests,
+		Default: c.cliConfig.Heimdall.MaxConcurrentRequests,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.heimdallapiversion",
+		Usage:   "Version of the Heimdall REST api, selecting the paths of its endpoints (v1 or v2)",
+		Value:   &c.cliConfig.Heimdall.APIVersion,
+		Default: c.cliConfig.Heimdall.APIVersion,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.heimdallrecorddir",
+		Usage:   "Directory which all the Heimdall responses are recorded to, for a later replay in tests",
+		Value:   &c.cliConfig.Heimdall.RecordDir,
+		Default: c.cliConfig.Heimdall.RecordDir,
+	})
+
+	// bor
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.strictextradata",
+		Usage:   "Strictly validate the layout of the header's extra-data (vanity, validator bytes and seal)",
+		Value:   &c.cliConfig.Bor.StrictExtraData,
+		Default: c.cliConfig.Bor.StrictExtraData,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.snapshotcheckpointinterval",
+		Usage:   "Number of blocks after which a validator snapshot is stored to the database",
+		Value:   &c.cliConfig.Bor.SnapshotCheckpointInterval,
+		Default: c.cliConfig.Bor.SnapshotCheckpointInterval,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.allowoutofturn",
+		Usage:   "Allow sealing blocks out-of-turn, if disabled the node only seals when in-turn (the out-of-turn blocks of the other signers are still accepted)",
+		Value:   &c.cliConfig.Bor.AllowOutOfTurn,
+		Default: c.cliConfig.Bor.AllowOutOfTurn,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.forktiebreak",
+		Usage:   "Policy used to choose between two heads of equal total difficulty and height ('highesthash', 'lowesthash' or 'firstseen')",
+		Value:   &c.cliConfig.Bor.ForkTiebreak,
+		Default: c.cliConfig.Bor.ForkTiebreak,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.maxspanstaleness",
+		Usage:   "Number of blocks before the end of the current span from which sealing is paused until the next span is fetched (0 = disabled)",
+		Value:   &c.cliConfig.Bor.MaxSpanStaleness,
+		Default: c.cliConfig.Bor.MaxSpanStaleness,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.milestoneconfirmations",
+		Usage:   "Number of consecutive consistent milestones needed before a milestone is whitelisted, the newer ones confirming the older one (1 = whitelist right away)",
+		Value:   &c.cliConfig.Bor.MilestoneConfirmations,
+		Default: c.cliConfig.Bor.MilestoneConfirmations,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.recentslimitpercent",
+		Usage:   "Maximum size of the snapshot recents, in percent of the validator set size plus one (up to 100, 0 for the default of 50)",
+		Value:   &c.cliConfig.Bor.RecentsLimitPercent,
+		Default: c.cliConfig.Bor.RecentsLimitPercent,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.verifyspancommit",
+		Usage:   "Check the span committed in a span boundary block against Heimdall before sealing it, sealing is paused on a mismatch",
+		Value:   &c.cliConfig.Bor.VerifySpanCommit,
+		Default: c.cliConfig.Bor.VerifySpanCommit,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.verifygenesiscontracts",
+		Usage:   "Check at startup that the validator set and state receiver contracts have code in the genesis state",
+		Value:   &c.cliConfig.Bor.VerifyGenesisContracts,
+		Default: c.cliConfig.Bor.VerifyGenesisContracts,
+	})
+	f.DurationFlag(&flagset.DurationFlag{
+		Name:    "bor.milestonepollinterval",
+		Usage:   "Interval between the fetches of the latest milestone from heimdall, at least 1s",
+		Value:   &c.cliConfig.Bor.MilestonePollInterval,
+		Default: c.cliConfig.Bor.MilestonePollInterval,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.milestoneverifymissingdatapolicy",
+		Usage:   "Behaviour of the milestone verification when the end block isn't available locally ('defer' or 'trust')",
+		Value:   &c.cliConfig.Bor.MilestoneVerifyMissingDataPolicy,
+		Default: c.cliConfig.Bor.MilestoneVerifyMissingDataPolicy,
+	})
+	f.IntFlag(&flagset.IntFlag{
+		Name:    "bor.validatorsetcachesize",
+		Usage:   "Number of validator set contract reads cached by block hash (0 = disabled)",
+		Value:   &c.cliConfig.Bor.ValidatorSetCacheSize,
+		Default: c.cliConfig.Bor.ValidatorSetCacheSize,
+	})
+	f.IntFlag(&flagset.IntFlag{
+		Name:    "bor.verifyconcurrency",
+		Usage:   "Maximum number of headers verified concurrently in a batch (0 = number of CPUs)",
+		Value:   &c.cliConfig.Bor.VerifyConcurrency,
+		Default: c.cliConfig.Bor.VerifyConcurrency,
+	})
+	f.DurationFlag(&flagset.DurationFlag{
+		Name:    "bor.milestonefetchtimeout",
+		Usage:   "Time after which the node stops fetching the chain of a milestone conflicting with the local chain and raises a finality alert (0 = never)",
+		Value:   &c.cliConfig.Bor.MilestoneFetchTimeout,
+		Default: c.cliConfig.Bor.MilestoneFetchTimeout,
+	})
+	f.DurationFlag(&flagset.DurationFlag{
+		Name:    "bor.maxheimdallclockskew",
+		Usage:   "Skew of the local clock from heimdall's, measured every minute against the heimdall REST server, over which a warning is logged (0 = not measured)",
+		Value:   &c.cliConfig.Bor.MaxHeimdallClockSkew,
+		Default: c.cliConfig.Bor.MaxHeimdallClockSkew,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.milestonegapwarnthreshold",
+		Usage:   "Gap between the head and the latest milestone, in blocks, which logs a finality warning if exceeded for a minute (0 = disabled)",
+		Value:   &c.cliConfig.Bor.MilestoneGapWarnThreshold,
+		Default: c.cliConfig.Bor.MilestoneGapWarnThreshold,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.sealstopbeforespanchange",
+		Usage:   "Number of blocks before the end of the span the sealing stops if the signer isn't a producer of the next span (0 = disabled)",
+		Value:   &c.cliConfig.Bor.SealStopBeforeSpanChange,
+		Default: c.cliConfig.Bor.SealStopBeforeSpanChange,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.eagermilestoneresync",
+		Usage:   "Request the end block of a milestone conflicting with the local chain from all the peers right away and sync with the first one having it, instead of waiting for the regular sync",
+		Value:   &c.cliConfig.Bor.EagerMilestoneResync,
+		Default: c.cliConfig.Bor.EagerMilestoneResync,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.genesisspansource",
+		Usage:   "Source of the validator set of the genesis span, 'contract' (genesis contract), 'heimdall' (heimdall's span 0) or 'strict' (genesis contract, refusing to start if it doesn't match heimdall's span 0)",
+		Value:   &c.cliConfig.Bor.GenesisSpanSource,
+		Default: c.cliConfig.Bor.GenesisSpanSource,
+	})
+	f.IntFlag(&flagset.IntFlag{
+		Name:    "bor.parallelstatesync",
+		Usage:   "Experimental: maximum number of state receiver contracts whose state-sync events of a sprint are executed speculatively in parallel, committed if the receivers are independent and sequentially otherwise (0 = disabled)",
+		Value:   &c.cliConfig.Bor.ParallelStateSync,
+		Default: c.cliConfig.Bor.ParallelStateSync,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.strictdifficulty",
+		Usage:   "Check the difficulty of the headers against the turn of their signer before the validator list, rather than last when verifying their seal",
+		Value:   &c.cliConfig.Bor.StrictDifficulty,
+		Default: c.cliConfig.Bor.StrictDifficulty,
+	})
+	f.DurationFlag(&flagset.DurationFlag{
+		Name:    "bor.milestoneidttl",
+		Usage:   "Time after which a milestone id voted on, neither confirmed nor rejected by heimdall, is dropped and its sprint unlocked (0 = never)",
+		Value:   &c.cliConfig.Bor.MilestoneIDTTL,
+		Default: c.cliConfig.Bor.MilestoneIDTTL,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.milestonestartuppolicy",
+		Usage:   "Reconciliation of the persisted milestone with heimdall's latest one on startup, 'heimdall' (adopt heimdall's, rewinding if needed), 'persisted' (keep the persisted one until heimdall catches up) or 'newest' (the newest one, once verified against the local chain)",
+		Value:   &c.cliConfig.Bor.MilestoneStartupPolicy,
+		Default: c.cliConfig.Bor.MilestoneStartupPolicy,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.persiststatesyncprogress",
+		Usage:   "Persist the last applied state-sync event id and its block atomically with the block commit, loaded and checked against the chain on startup",
+		Value:   &c.cliConfig.Bor.PersistStateSyncProgress,
+		Default: c.cliConfig.Bor.PersistStateSyncProgress,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.milestoneoverlappolicy",
+		Usage:   "Behaviour when a milestone covering the whitelisted milestone disagrees with it over their overlap, 'reject' (heimdall revised the finalized history) or 'accept' (whitelist it anyway, logging the conflict)",
+		Value:   &c.cliConfig.Bor.MilestoneOverlapPolicy,
+		Default: c.cliConfig.Bor.MilestoneOverlapPolicy,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.tracemilestoneprocessing",
+		Usage:   "Log every decision taken while processing each milestone (block lookup, hash comparison, reorg computation, lock interaction and outcome) as a set of logs tagged with the milestone id",
+		Value:   &c.cliConfig.Bor.TraceMilestoneProcessing,
+		Default: c.cliConfig.Bor.TraceMilestoneProcessing,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.maxsnapshotwalkback",
+		Usage:   "Most headers walked back to reconstruct a snapshot before failing with a missing checkpoint snapshot error, instead of walking back unbounded on a corrupted database (0 = unbounded)",
+		Value:   &c.cliConfig.Bor.MaxSnapshotWalkback,
+		Default: c.cliConfig.Bor.MaxSnapshotWalkback,
+	})
+	f.Uint64Flag(&flagset.Uint64Flag{
+		Name:    "bor.futureblocktolerance",
+		Usage:   "Number of seconds a header's timestamp may be ahead of the local clock before it's deferred as a future block, to absorb the clock skew of the validators (at most the block period)",
+		Value:   &c.cliConfig.Bor.FutureBlockTolerance,
+		Default: c.cliConfig.Bor.FutureBlockTolerance,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.autorecoversealing",
+		Usage:   "Restart the miner once when the node misses a block it's the in-turn proposer of (the missed slots are always logged and counted)",
+		Value:   &c.cliConfig.Bor.AutoRecoverSealing,
+		Default: c.cliConfig.Bor.AutoRecoverSealing,
+	})
+	f.BoolFlag(&flagset.BoolFlag{
+		Name:    "bor.prunemilestonesonsethead",
+		Usage:   "Prune the tracked milestone ids, the milestone lock and the whitelisted checkpoint and milestone above the new head when the head is set back (debug_setHead)",
+		Value:   &c.cliConfig.Bor.PruneMilestonesOnSetHead,
+		Default: c.cliConfig.Bor.PruneMilestonesOnSetHead,
+	})
+	f.StringFlag(&flagset.StringFlag{
+		Name:    "bor.milestoneduringsnapsync",
+		Usage:   "Behaviour of the milestone processing while the snap sync is in progress, 'defer' (buffer the milestone as a future milestone, verified once the sync completes) or 'skip' (ignore it)",
+		Value:   &c.cliConfig.Bor.MilestoneDuringSnapSync,
+		Default: c.cliConfig.Bor.MilestoneDuringSnapSync,
+	})
 
 	// txpool options
 	f.SliceStringFlag(&flagset.SliceStringFlag{
@@ -959,6 +1177,12 @@ func (c *Command) Flags(config *Config) *flagset.Flagset {
 		Value:   &c.cliConfig.ParallelEVM.SpeculativeProcesses,
 		Default: c.cliConfig.ParallelEVM.SpeculativeProcesses,
 	})
+	f.SliceStringFlag(&flagset.SliceStringFlag{
+		Name:    "parallelevm.sequentialto",
+		Usage:   "Comma separated addresses of hot contracts, whose transactions are executed one after the other in Block STM",
+		Value:   &c.cliConfig.ParallelEVM.SequentialTo,
+		Default: c.cliConfig.ParallelEVM.SequentialTo,
+	})
 	f.Uint64Flag(&flagset.Uint64Flag{
 		Name:    "dev.gaslimit",
 		Usage:   "Initial block gas limit",
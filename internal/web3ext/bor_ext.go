@@ -11,6 +11,18 @@ web3._extend({
 			params: 1,
 			inputFormatter: [null]
 		}),
+		new web3._extend.Method({
+			name: 'decodeExtraData',
+			call: 'bor_decodeExtraData',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'exportSnapshot',
+			call: 'bor_exportSnapshot',
+			params: 1,
+			inputFormatter: [null]
+		}),
 		new web3._extend.Method({
 			name: 'getAuthor',
 			call: 'bor_getAuthor',
@@ -55,6 +67,122 @@ web3._extend({
 			call: 'bor_getCurrentValidators',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'getBorConfig',
+			call: 'bor_getBorConfig',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getRecentsLockout',
+			call: 'bor_getRecentsLockout',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getCurrentProposerSchedule',
+			call: 'bor_getCurrentProposerSchedule',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSealerForBlock',
+			call: 'bor_getSealerForBlock',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'blocksUntilNextSpan',
+			call: 'bor_blocksUntilNextSpan',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSpanCommitBlock',
+			call: 'bor_getSpanCommitBlock',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSpanForFutureBlock',
+			call: 'bor_getSpanForFutureBlock',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getStateSyncStatus',
+			call: 'bor_getStateSyncStatus',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'validateBlockRange',
+			call: 'bor_validateBlockRange',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getPerfectProposers',
+			call: 'bor_getPerfectProposers',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getHeimdallClientType',
+			call: 'bor_getHeimdallClientType',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSealHash',
+			call: 'bor_getSealHash',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getMilestoneForBlock',
+			call: 'bor_getMilestoneForBlock',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getPendingMilestones',
+			call: 'bor_getPendingMilestones',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'bor_status',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'rewindToMilestone',
+			call: 'bor_rewindToMilestone',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getReorgStats',
+			call: 'bor_getReorgStats',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'compareForks',
+			call: 'bor_compareForks',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getHeadScore',
+			call: 'bor_getHeadScore',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getMilestoneHistory',
+			call: 'bor_getMilestoneHistory',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getActiveLock',
+			call: 'bor_getActiveLock',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getForkedPeers',
+			call: 'bor_getForkedPeers',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSyncProgress',
+			call: 'bor_getSyncProgress',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'getRootHash',
 			call: 'bor_getRootHash',
@@ -65,6 +193,21 @@ web3._extend({
 			call: 'bor_getVoteOnHash',
 			params: 4,
 		}),
+		new web3._extend.Method({
+			name: 'voteOnMilestone',
+			call: 'bor_voteOnMilestone',
+			params: 4
+		}),
+		new web3._extend.Method({
+			name: 'getLastSpanChange',
+			call: 'bor_getLastSpanChange',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getMilestoneVoteHistory',
+			call: 'bor_getMilestoneVoteHistory',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sendRawTransactionConditional',
 			call: 'bor_sendRawTransactionConditional',
@@ -161,6 +161,30 @@ web3._extend({
 			call: 'admin_sleepBlocks',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'borTrustPeer',
+			call: 'admin_borTrustPeer',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'borPauseStateSync',
+			call: 'admin_borPauseStateSync'
+		}),
+		new web3._extend.Method({
+			name: 'borResumeStateSync',
+			call: 'admin_borResumeStateSync'
+		}),
+		new web3._extend.Method({
+			name: 'borWarmSignerCache',
+			call: 'admin_borWarmSignerCache',
+			params: 4,
+			inputFormatter: [null, null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'borSetHeimdallClient',
+			call: 'admin_borSetHeimdallClient',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'startHTTP',
 			call: 'admin_startHTTP',
@@ -565,6 +589,22 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'borReplayStateSync',
+			call: 'debug_borReplayStateSync',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'borProcessMilestone',
+			call: 'debug_borProcessMilestone',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'borGetParallelEVMStats',
+			call: 'debug_borGetParallelEVMStats',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: []
 });
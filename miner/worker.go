@@ -1116,10 +1116,19 @@ mainloop:
 
 			delayFlag := true
 
+			// The fees are credited to the engine's fee recipient if it has one, as
+			// in the state transition, rather than to the coinbase
+			feeBeneficiary := env.coinbase
+			if engine, ok := w.engine.(consensus.FeeRecipientEngine); ok {
+				if recipient := engine.FeeRecipient(env.header); recipient != nil {
+					feeBeneficiary = *recipient
+				}
+			}
+
 			for i := 1; i <= len(mvReadMapList)-1; i++ {
 				reads := mvReadMapList[i-1]
 
-				_, ok1 := reads[blockstm.NewSubpathKey(env.coinbase, state.BalancePath)]
+				_, ok1 := reads[blockstm.NewSubpathKey(feeBeneficiary, state.BalancePath)]
 				_, ok2 := reads[blockstm.NewSubpathKey(common.HexToAddress(w.chainConfig.Bor.CalculateBurntContract(env.header.Number.Uint64())), state.BalancePath)]
 
 				if ok1 || ok2 {
@@ -1600,8 +1609,13 @@ func (w *worker) commitWork(ctx context.Context, interrupt *atomic.Int32, noempt
 		_ = w.commit(ctx, work.copy(), nil, false, start)
 	}
 	// Fill pending transactions from the txpool into the block.
+	fillStart := time.Now()
 	err = w.fillTransactions(ctx, interrupt, work, interruptCtx)
 
+	if borEngine, ok := w.engine.(*bor.Bor); ok {
+		borEngine.RecordTxExecution(work.header, time.Since(fillStart))
+	}
+
 	switch {
 	case err == nil:
 		// The entire block is filled, decrease resubmit interval in case
@@ -548,20 +548,27 @@ func (c *CliqueConfig) String() string {
 
 // BorConfig is the consensus engine configs for Matic bor based sealing.
 type BorConfig struct {
-	Period                     map[string]uint64      `json:"period"`                   // Number of seconds between blocks to enforce
-	ProducerDelay              map[string]uint64      `json:"producerDelay"`            // Number of seconds delay between two producer interval
-	Sprint                     map[string]uint64      `json:"sprint"`                   // Epoch length to proposer
-	BackupMultiplier           map[string]uint64      `json:"backupMultiplier"`         // Backup multiplier to determine the wiggle time
-	ValidatorContract          string                 `json:"validatorContract"`        // Validator set contract
-	StateReceiverContract      string                 `json:"stateReceiverContract"`    // State receiver contract
-	OverrideStateSyncRecords   map[string]int         `json:"overrideStateSyncRecords"` // override state records count
-	BlockAlloc                 map[string]interface{} `json:"blockAlloc"`
-	BurntContract              map[string]string      `json:"burntContract"`              // governance contract where the token will be sent to and burnt in london fork
-	JaipurBlock                *big.Int               `json:"jaipurBlock"`                // Jaipur switch block (nil = no fork, 0 = already on jaipur)
-	DelhiBlock                 *big.Int               `json:"delhiBlock"`                 // Delhi switch block (nil = no fork, 0 = already on delhi)
-	ParallelUniverseBlock      *big.Int               `json:"parallelUniverseBlock"`      // TODO: update all occurrence, change name and finalize number (hardfork for block-stm related changes)
-	IndoreBlock                *big.Int               `json:"indoreBlock"`                // Indore switch block (nil = no fork, 0 = already on indore)
-	StateSyncConfirmationDelay map[string]uint64      `json:"stateSyncConfirmationDelay"` // StateSync Confirmation Delay, in seconds, to calculate `to`
+	Period                     map[string]uint64            `json:"period"`                           // Number of seconds between blocks to enforce
+	ProducerDelay              map[string]uint64            `json:"producerDelay"`                    // Number of seconds delay between two producer interval
+	Sprint                     map[string]uint64            `json:"sprint"`                           // Epoch length to proposer
+	BackupMultiplier           map[string]uint64            `json:"backupMultiplier"`                 // Backup multiplier to determine the wiggle time
+	ValidatorContract          string                       `json:"validatorContract"`                // Validator set contract
+	StateReceiverContract      string                       `json:"stateReceiverContract"`            // State receiver contract
+	StateReceiverContracts     map[string]map[string]string `json:"stateReceiverContracts,omitempty"` // State receiver contracts by the target contract of the events, from the given block on, the others use StateReceiverContract (none may require contiguous event ids)
+	OverrideStateSyncRecords   map[string]int               `json:"overrideStateSyncRecords"`         // override state records count
+	BlockAlloc                 map[string]interface{}       `json:"blockAlloc"`
+	BurntContract              map[string]string            `json:"burntContract"`              // governance contract where the token will be sent to and burnt in london fork
+	JaipurBlock                *big.Int                     `json:"jaipurBlock"`                // Jaipur switch block (nil = no fork, 0 = already on jaipur)
+	DelhiBlock                 *big.Int                     `json:"delhiBlock"`                 // Delhi switch block (nil = no fork, 0 = already on delhi)
+	ParallelUniverseBlock      *big.Int                     `json:"parallelUniverseBlock"`      // TODO: update all occurrence, change name and finalize number (hardfork for block-stm related changes)
+	IndoreBlock                *big.Int                     `json:"indoreBlock"`                // Indore switch block (nil = no fork, 0 = already on indore)
+	StateSyncConfirmationDelay map[string]uint64            `json:"stateSyncConfirmationDelay"` // StateSync Confirmation Delay, in seconds, to calculate `to`
+	MaxStateSyncPerSprint      map[string]uint64            `json:"maxStateSyncPerSprint"`      // Maximum number of state-sync events applied per sprint, the rest is deferred (0 = no limit)
+	MinValidators              map[string]uint64            `json:"minValidators"`              // Minimum number of validators of a committed span, a block committing a smaller one being invalid (at least 1)
+	MaxValidators              map[string]uint64            `json:"maxValidators"`              // Maximum number of validators of a committed span, a block committing a larger one being invalid (0 = no maximum)
+	MaxStateSyncPayloadBytes   map[string]uint64            `json:"maxStateSyncPayloadBytes"`   // Maximum payload size of a state-sync event, a block applying a larger one being invalid (0 = no limit)
+	FeeRecipient               map[string]string            `json:"feeRecipient,omitempty"`     // Address credited with the transaction fees of the blocks instead of their author (empty or zero address = author)
+	OutOfTurnDelays            map[string]map[string]uint64 `json:"outOfTurnDelays,omitempty"`  // Out-of-turn delay per succession, in seconds, of the given validators instead of the backup multiplier
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -601,6 +608,84 @@ func (c *BorConfig) CalculateStateSyncDelay(number uint64) uint64 {
 	return borKeyValueConfigHelper(c.StateSyncConfirmationDelay, number)
 }
 
+// CalculateMaxStateSyncPerSprint returns the maximum number of state-sync events
+// which can be applied in the sprint of the given block, 0 meaning no limit.
+func (c *BorConfig) CalculateMaxStateSyncPerSprint(number uint64) uint64 {
+	if len(c.MaxStateSyncPerSprint) == 0 {
+		return 0
+	}
+
+	return borKeyValueConfigHelper(c.MaxStateSyncPerSprint, number)
+}
+
+// CalculateMinValidators returns the minimum number of validators of a span
+// committed in the given block, never less than 1.
+func (c *BorConfig) CalculateMinValidators(number uint64) uint64 {
+	if len(c.MinValidators) == 0 {
+		return 1
+	}
+
+	return max(borKeyValueConfigHelper(c.MinValidators, number), 1)
+}
+
+// CalculateMaxValidators returns the maximum number of validators of a span
+// committed in the given block, 0 meaning no maximum.
+func (c *BorConfig) CalculateMaxValidators(number uint64) uint64 {
+	if len(c.MaxValidators) == 0 {
+		return 0
+	}
+
+	return borKeyValueConfigHelper(c.MaxValidators, number)
+}
+
+// CalculateMaxStateSyncPayloadBytes returns the maximum payload size of a state-sync
+// event applied in the given block, 0 meaning no limit.
+func (c *BorConfig) CalculateMaxStateSyncPayloadBytes(number uint64) uint64 {
+	if len(c.MaxStateSyncPayloadBytes) == 0 {
+		return 0
+	}
+
+	return borKeyValueConfigHelper(c.MaxStateSyncPayloadBytes, number)
+}
+
+// CalculateOutOfTurnDelay returns the out-of-turn delay per succession of the given
+// validator at the given block, and whether it has one instead of the backup
+// multiplier.
+func (c *BorConfig) CalculateOutOfTurnDelay(number uint64, validator common.Address) (uint64, bool) {
+	if len(c.OutOfTurnDelays) == 0 {
+		return 0, false
+	}
+
+	for address, delay := range borKeyValueConfigHelper(c.OutOfTurnDelays, number) {
+		if common.HexToAddress(address) == validator {
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// CalculateFeeRecipient returns the address credited with the transaction fees of
+// the given block, the empty string meaning the block's author.
+func (c *BorConfig) CalculateFeeRecipient(number uint64) string {
+	if len(c.FeeRecipient) == 0 {
+		return ""
+	}
+
+	return borKeyValueConfigHelper(c.FeeRecipient, number)
+}
+
+// CalculateStateReceiverContracts returns the state receiver contracts, by the target
+// contract of the events, in effect at the given block. The events of the other
+// targets are committed to StateReceiverContract.
+func (c *BorConfig) CalculateStateReceiverContracts(number uint64) map[string]string {
+	if len(c.StateReceiverContracts) == 0 {
+		return nil
+	}
+
+	return borKeyValueConfigHelper(c.StateReceiverContracts, number)
+}
+
 // TODO: modify this function once the block number is finalized
 func (c *BorConfig) IsParallelUniverse(number *big.Int) bool {
 	if c.ParallelUniverseBlock != nil {
@@ -616,7 +701,7 @@ func (c *BorConfig) IsSprintStart(number uint64) bool {
 	return number%c.CalculateSprint(number) == 0
 }
 
-func borKeyValueConfigHelper[T uint64 | string](field map[string]T, number uint64) T {
+func borKeyValueConfigHelper[T uint64 | string | map[string]uint64 | map[string]string](field map[string]T, number uint64) T {
 	keys := make([]uint64, 0, len(field))
 	fieldUint := make(map[uint64]T)
 
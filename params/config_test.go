@@ -24,6 +24,7 @@ import (
 
 	"gotest.tools/assert"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 )
 
@@ -194,3 +195,118 @@ func TestBorKeyValueConfigHelper(t *testing.T) {
 	assert.Equal(t, borKeyValueConfigHelper(burntContract, 41824608), "0x617b94CCCC2511808A3C9478ebb96f455CF167aA")
 	assert.Equal(t, borKeyValueConfigHelper(burntContract, 41824608+1), "0x617b94CCCC2511808A3C9478ebb96f455CF167aA")
 }
+
+func TestCalculateMaxStateSyncPerSprint(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Equal(t, config.CalculateMaxStateSyncPerSprint(100), uint64(0))
+
+	config.MaxStateSyncPerSprint = map[string]uint64{
+		"0":   0,
+		"100": 10,
+	}
+	assert.Equal(t, config.CalculateMaxStateSyncPerSprint(99), uint64(0))
+	assert.Equal(t, config.CalculateMaxStateSyncPerSprint(100), uint64(10))
+	assert.Equal(t, config.CalculateMaxStateSyncPerSprint(101), uint64(10))
+}
+
+func TestCalculateMinValidators(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Equal(t, config.CalculateMinValidators(100), uint64(1))
+
+	config.MinValidators = map[string]uint64{
+		"0":   0,
+		"100": 4,
+	}
+	assert.Equal(t, config.CalculateMinValidators(99), uint64(1))
+	assert.Equal(t, config.CalculateMinValidators(100), uint64(4))
+	assert.Equal(t, config.CalculateMinValidators(101), uint64(4))
+}
+
+func TestCalculateMaxValidators(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Equal(t, config.CalculateMaxValidators(100), uint64(0))
+
+	config.MaxValidators = map[string]uint64{
+		"0":   0,
+		"100": 4,
+	}
+	assert.Equal(t, config.CalculateMaxValidators(99), uint64(0))
+	assert.Equal(t, config.CalculateMaxValidators(100), uint64(4))
+	assert.Equal(t, config.CalculateMaxValidators(101), uint64(4))
+}
+
+func TestCalculateMaxStateSyncPayloadBytes(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Equal(t, config.CalculateMaxStateSyncPayloadBytes(100), uint64(0))
+
+	config.MaxStateSyncPayloadBytes = map[string]uint64{
+		"0":   0,
+		"100": 1024,
+	}
+	assert.Equal(t, config.CalculateMaxStateSyncPayloadBytes(99), uint64(0))
+	assert.Equal(t, config.CalculateMaxStateSyncPayloadBytes(100), uint64(1024))
+	assert.Equal(t, config.CalculateMaxStateSyncPayloadBytes(101), uint64(1024))
+}
+
+func TestCalculateOutOfTurnDelay(t *testing.T) {
+	t.Parallel()
+
+	validator := common.Address{0x1}
+
+	config := &BorConfig{}
+	_, ok := config.CalculateOutOfTurnDelay(100, validator)
+	assert.Assert(t, !ok)
+
+	config.OutOfTurnDelays = map[string]map[string]uint64{
+		"0":   {},
+		"100": {validator.Hex(): 1},
+	}
+
+	_, ok = config.CalculateOutOfTurnDelay(99, validator)
+	assert.Assert(t, !ok)
+
+	delay, ok := config.CalculateOutOfTurnDelay(100, validator)
+	assert.Assert(t, ok)
+	assert.Equal(t, uint64(1), delay)
+
+	_, ok = config.CalculateOutOfTurnDelay(100, common.Address{0x2})
+	assert.Assert(t, !ok)
+}
+
+func TestCalculateFeeRecipient(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Equal(t, config.CalculateFeeRecipient(100), "")
+
+	config.FeeRecipient = map[string]string{
+		"0":   "",
+		"100": "0x0000000000000000000000000000000000000002",
+	}
+	assert.Equal(t, config.CalculateFeeRecipient(99), "")
+	assert.Equal(t, config.CalculateFeeRecipient(100), "0x0000000000000000000000000000000000000002")
+	assert.Equal(t, config.CalculateFeeRecipient(101), "0x0000000000000000000000000000000000000002")
+}
+
+func TestCalculateStateReceiverContracts(t *testing.T) {
+	t.Parallel()
+
+	config := &BorConfig{}
+	assert.Assert(t, config.CalculateStateReceiverContracts(100) == nil)
+
+	config.StateReceiverContracts = map[string]map[string]string{
+		"0":   {},
+		"100": {"0x0000000000000000000000000000000000000011": "0x0000000000000000000000000000000000001002"},
+	}
+	assert.Equal(t, len(config.CalculateStateReceiverContracts(99)), 0)
+	assert.Equal(t, config.CalculateStateReceiverContracts(100)["0x0000000000000000000000000000000000000011"], "0x0000000000000000000000000000000000001002")
+	assert.Equal(t, config.CalculateStateReceiverContracts(101)["0x0000000000000000000000000000000000000011"], "0x0000000000000000000000000000000000001002")
+}
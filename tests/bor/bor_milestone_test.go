@@ -4,6 +4,7 @@
 package bor
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"errors"
 
@@ -13,7 +14,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/consensus/bor"
 	"github.com/ethereum/go-ethereum/core"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -21,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/tests/bor/bortest"
 )
 
 var (
@@ -95,48 +99,44 @@ func TestMiningAfterLocking(t *testing.T) {
 		}
 	}
 
-	for {
-
-		// for block 0 to 7, the primary validator is node0
-		// for block 8 to 15, the primary validator is node1
-		// for block 16 to 23, the primary validator is node0
-		// for block 24 to 31, the primary validator is node1
-		blockHeaderVal0 := nodes[0].BlockChain().CurrentHeader()
-		blockHeaderVal1 := nodes[1].BlockChain().CurrentHeader()
-
-		//Lock the sprint at 8th block
-		if blockHeaderVal0.Number.Uint64() == 8 {
-			block8Hash := blockHeaderVal0.Hash()
-
-			nodes[0].Downloader().ChainValidator.LockMutex(uint64(8))
-			nodes[0].Downloader().ChainValidator.UnlockMutex(true, "MilestoneID1", uint64(8), block8Hash)
-		}
+	// for block 0 to 7, the primary validator is node0
+	// for block 8 to 15, the primary validator is node1
+	// for block 16 to 23, the primary validator is node0
+	// for block 24 to 31, the primary validator is node1
+	timeline := []bortest.Step{
+		// Lock the sprint at 8th block, and unlock it
+		{Watch: 0, AtBlock: 8, Action: bortest.Lock(0, "MilestoneID1")},
+		{Watch: 0, AtBlock: 12, Action: bortest.Unlock(0, 8)},
+		{Watch: 1, AtBlock: 16, Action: bortest.Lock(1, "MilestoneID2")},
+		{Watch: 1, AtBlock: 20, Action: bortest.Unlock(1, 16)},
+		{Watch: 0, AtBlock: 30, Action: bortest.Mark("reached block 30")},
+	}
 
-		//Unlock the locked sprint
-		if blockHeaderVal0.Number.Uint64() == 12 {
-			nodes[0].Downloader().ChainValidator.UnlockSprint(8)
-		}
+	timelineNodes := []bortest.TimelineNode{{Stack: stacks[0], Eth: nodes[0]}, {Stack: stacks[1], Eth: nodes[1]}}
 
-		if blockHeaderVal1.Number.Uint64() == 16 {
-			block16Hash := blockHeaderVal1.Hash()
-			nodes[1].Downloader().ChainValidator.LockMutex(uint64(16))
-			nodes[1].Downloader().ChainValidator.UnlockMutex(true, "MilestoneID2", uint64(16), block16Hash)
+	// Save the network on failure, to replay it with bortest.RestoreNetwork
+	defer func() {
+		if t.Failed() {
+			dir, err := bortest.SnapshotNetwork(timelineNodes)
+			t.Log("Saved the network to", dir, "err", err)
 		}
+	}()
 
-		if blockHeaderVal1.Number.Uint64() == 20 {
-			nodes[1].Downloader().ChainValidator.UnlockSprint(16)
-		}
+	timelineCtx, timelineCancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer timelineCancel()
 
-		if blockHeaderVal0.Number.Uint64() == 30 {
-			break
-		}
+	executed, err := bortest.RunTimeline(timelineCtx, timelineNodes, timeline)
+	for _, step := range executed {
+		t.Log(step)
 	}
 
-	blockHeaderVal0 := nodes[0].BlockChain().GetHeaderByNumber(29)
-	blockHeaderVal1 := nodes[1].BlockChain().GetHeaderByNumber(29)
+	assert.NoError(t, err)
 
 	//Both nodes should have same blockheader at 29th block
-	assert.Equal(t, blockHeaderVal0, blockHeaderVal1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assert.NoError(t, bortest.WaitForConvergence(ctx, nodes, 29))
 
 	milestoneListVal0 := nodes[0].Downloader().ChainValidator.GetMilestoneIDsList()
 
@@ -147,6 +147,104 @@ func TestMiningAfterLocking(t *testing.T) {
 	assert.Equal(t, len(milestoneListVal1), int(0))
 }
 
+func TestSnapshotNetwork(t *testing.T) {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	_, err := fdlimit.Raise(2048)
+
+	if err != nil {
+		panic(err)
+	}
+
+	faucets := make([]*ecdsa.PrivateKey, 128)
+	for i := 0; i < len(faucets); i++ {
+		faucets[i], _ = crypto.GenerateKey()
+	}
+
+	genesis := InitGenesis(t, faucets, "./testdata/genesis_2val.json", 8)
+
+	// newNodes starts two nodes, connected to each other if requested
+	newNodes := func(connect bool) []bortest.TimelineNode {
+		var nodes []bortest.TimelineNode
+
+		for i := 0; i < 2; i++ {
+			stack, ethBackend, err := InitMiner(genesis, keysMilestone[i], true)
+			if err != nil {
+				panic(err)
+			}
+			t.Cleanup(func() { stack.Close() })
+
+			for stack.Server().NodeInfo().Ports.Listener == 0 {
+				time.Sleep(250 * time.Millisecond)
+			}
+
+			if connect {
+				for _, n := range nodes {
+					stack.Server().AddPeer(n.Stack.Server().Self())
+				}
+			}
+
+			nodes = append(nodes, bortest.TimelineNode{Stack: stack, Eth: ethBackend})
+		}
+
+		return nodes
+	}
+
+	nodes := newNodes(true)
+
+	time.Sleep(3 * time.Second)
+
+	for _, node := range nodes {
+		if err := node.Eth.StartMining(); err != nil {
+			panic(err)
+		}
+	}
+
+	timeline := []bortest.Step{
+		{Watch: 0, AtBlock: 8, Action: bortest.Lock(0, "MilestoneID1")},
+		{Watch: 1, AtBlock: 12, Action: bortest.ProcessMilestone(1)},
+		{Watch: 0, AtBlock: 14, Action: bortest.Mark("reached block 14")},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	_, err = bortest.RunTimeline(ctx, nodes, timeline)
+	assert.NoError(t, err)
+
+	for _, node := range nodes {
+		node.Eth.StopMining()
+	}
+
+	dir, err := bortest.SnapshotNetwork(nodes)
+	assert.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	// Restore the network to fresh nodes, not connected to each other
+	restored := newNodes(false)
+
+	snapshots, err := bortest.RestoreNetwork(dir, restored)
+	assert.NoError(t, err)
+
+	for i, node := range restored {
+		head := node.Eth.BlockChain().CurrentBlock()
+		assert.Equal(t, snapshots[i].HeadHash, head.Hash())
+		assert.Equal(t, nodes[i].Eth.BlockChain().GetHeaderByNumber(head.Number.Uint64()).Hash(), head.Hash())
+	}
+
+	locked, number, hash, ids := restored[0].Eth.Downloader().ChainValidator.GetLockedSprintInfo()
+	assert.True(t, locked)
+	assert.Equal(t, uint64(8), number)
+	assert.Equal(t, nodes[0].Eth.BlockChain().GetHeaderByNumber(8).Hash(), hash)
+	assert.Equal(t, []string{"MilestoneID1"}, ids)
+
+	exists, number, hash := restored[1].Eth.Downloader().ChainValidator.GetWhitelistedMilestone()
+	assert.True(t, exists)
+	assert.Equal(t, uint64(12), number)
+	assert.Equal(t, nodes[1].Eth.BlockChain().GetHeaderByNumber(12).Hash(), hash)
+}
+
 func TestReorgingAfterLockingSprint(t *testing.T) {
 	t.Skip()
 	// t.Parallel()
@@ -244,22 +342,11 @@ func TestReorgingAfterLockingSprint(t *testing.T) {
 		}
 	}
 
-	// check block 10 block ; expected author is node1 signer
-	blockHeader10Val0 := nodes[0].BlockChain().GetHeaderByNumber(10)
-
-	author10Val0, err := nodes[0].Engine().Author(blockHeader10Val0)
-
-	if err == nil {
-		assert.Equal(t, author10Val0, nodes[0].AccountManager().Accounts()[0])
-	}
-
-	blockHeader12Val0 := nodes[0].BlockChain().GetHeaderByNumber(12)
-
-	author12Val0, err := nodes[0].Engine().Author(blockHeader12Val0)
-
-	if err == nil {
-		assert.Equal(t, author12Val0, nodes[0].AccountManager().Accounts()[0])
-	}
+	// check blocks 10 and 12 ; expected author is node0 signer
+	bortest.AssertAuthors(t, nodes[0], map[uint64]common.Address{
+		10: nodes[0].AccountManager().Accounts()[0],
+		12: nodes[0].AccountManager().Accounts()[0],
+	})
 
 	//milestoneIDList should contain only one milestoneID
 	milestoneListVal1 := nodes[0].Downloader().ChainValidator.GetMilestoneIDsList()
@@ -367,17 +454,11 @@ func TestReorgingAfterWhitelisting(t *testing.T) {
 		}
 	}
 
-	arr := []uint64{10, 12, 13}
-
-	for _, val := range arr {
-		blockHeaderVal := nodes[0].BlockChain().GetHeaderByNumber(val)
-
-		authorVal, err := nodes[0].Engine().Author(blockHeaderVal)
-
-		if err == nil {
-			assert.Equal(t, authorVal, nodes[0].AccountManager().Accounts()[0])
-		}
-	}
+	bortest.AssertAuthors(t, nodes[0], map[uint64]common.Address{
+		10: nodes[0].AccountManager().Accounts()[0],
+		12: nodes[0].AccountManager().Accounts()[0],
+		13: nodes[0].AccountManager().Accounts()[0],
+	})
 }
 
 func TestPeerConnectionAfterWhitelisting(t *testing.T) {
@@ -484,21 +565,8 @@ func TestPeerConnectionAfterWhitelisting(t *testing.T) {
 		assert.Equal(t, val0PeerCount, 0)
 		assert.Equal(t, val1PeerCount, 0)
 
-		blockHeader13Val0 := nodes[0].BlockChain().GetHeaderByNumber(13)
-
-		author13Val0, err := nodes[0].Engine().Author(blockHeader13Val0)
-
-		if err == nil {
-			assert.Equal(t, author13Val0, nodes[0].AccountManager().Accounts()[0])
-		}
-
-		blockHeader13Val1 := nodes[1].BlockChain().GetHeaderByNumber(13)
-
-		author13Val1, err := nodes[1].Engine().Author(blockHeader13Val1)
-
-		if err == nil {
-			assert.Equal(t, author13Val1, nodes[1].AccountManager().Accounts()[0])
-		}
+		bortest.AssertAuthors(t, nodes[0], map[uint64]common.Address{13: nodes[0].AccountManager().Accounts()[0]})
+		bortest.AssertAuthors(t, nodes[1], map[uint64]common.Address{13: nodes[1].AccountManager().Accounts()[0]})
 	}
 }
 
@@ -577,17 +645,11 @@ func TestReorgingFutureSprintAfterLocking(t *testing.T) {
 		}
 	}
 
-	node1Arr := []uint64{8, 15, 24}
-
-	for _, val := range node1Arr {
-		blockHeader := nodes[0].BlockChain().GetHeaderByNumber(val)
-
-		authorVal, err := nodes[0].Engine().Author(blockHeader)
-
-		if err == nil {
-			assert.Equal(t, authorVal, nodes[1].AccountManager().Accounts()[0])
-		}
-	}
+	bortest.AssertAuthors(t, nodes[0], map[uint64]common.Address{
+		8:  nodes[1].AccountManager().Accounts()[0],
+		15: nodes[1].AccountManager().Accounts()[0],
+		24: nodes[1].AccountManager().Accounts()[0],
+	})
 }
 
 func TestReorgingFutureSprintAfterLockingOnSameHash(t *testing.T) {
@@ -666,17 +728,11 @@ func TestReorgingFutureSprintAfterLockingOnSameHash(t *testing.T) {
 
 	}
 
-	node1Arr := []uint64{8, 15, 24}
-
-	for _, val := range node1Arr {
-		blockHeader := nodes[0].BlockChain().GetHeaderByNumber(val)
-
-		authorVal, err := nodes[0].Engine().Author(blockHeader)
-
-		if err == nil {
-			assert.Equal(t, authorVal, nodes[1].AccountManager().Accounts()[0])
-		}
-	}
+	bortest.AssertAuthors(t, nodes[0], map[uint64]common.Address{
+		8:  nodes[1].AccountManager().Accounts()[0],
+		15: nodes[1].AccountManager().Accounts()[0],
+		24: nodes[1].AccountManager().Accounts()[0],
+	})
 }
 
 func TestReorgingAfterLockingOnDifferentHash(t *testing.T) {
@@ -742,11 +798,16 @@ func TestReorgingAfterLockingOnDifferentHash(t *testing.T) {
 	stacks[0].Server().RemovePeer(enodes[1])
 	stacks[1].Server().RemovePeer(enodes[0])
 
-	chain2HeadChNode0 := make(chan core.Chain2HeadEvent, 64)
-	chain2HeadChNode1 := make(chan core.Chain2HeadEvent, 64)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	nodes[0].BlockChain().SubscribeChain2HeadEvent(chain2HeadChNode0)
-	nodes[1].BlockChain().SubscribeChain2HeadEvent(chain2HeadChNode1)
+	noReorgErrs := make(chan error, len(nodes))
+
+	for _, node := range nodes {
+		go func(chain *core.BlockChain) {
+			noReorgErrs <- bortest.AssertNoReorg(ctx, chain, 30)
+		}(node.BlockChain())
+	}
 
 	for {
 		// for block 0 to 7, the primary validator is node0
@@ -774,28 +835,15 @@ func TestReorgingAfterLockingOnDifferentHash(t *testing.T) {
 			stacks[1].Server().AddPeer(enodes[0])
 		}
 
-		select {
-		case ev := <-chain2HeadChNode0:
-			if ev.Type == core.Chain2HeadReorgEvent {
-				assert.Fail(t, "Node 0 should not get reorged")
-				break
-
-			}
-
-		case ev := <-chain2HeadChNode1:
-			if ev.Type == core.Chain2HeadReorgEvent {
-				assert.Fail(t, "Node 1 should not get reorged")
-				break
-			}
-
-		default:
-			time.Sleep(1 * time.Millisecond)
-
-		}
-
 		if blockHeaderVal0.Number.Uint64() == 30 {
 			break
 		}
+
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	for range nodes {
+		assert.NoError(t, <-noReorgErrs, "Nodes should not get reorged")
 	}
 }
 
@@ -863,11 +911,16 @@ func TestReorgingAfterWhitelistingOnDifferentHash(t *testing.T) {
 	stacks[0].Server().RemovePeer(enodes[1])
 	stacks[1].Server().RemovePeer(enodes[0])
 
-	chain2HeadChNode0 := make(chan core.Chain2HeadEvent, 64)
-	chain2HeadChNode1 := make(chan core.Chain2HeadEvent, 64)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	noReorgErrs := make(chan error, len(nodes))
 
-	nodes[0].BlockChain().SubscribeChain2HeadEvent(chain2HeadChNode0)
-	nodes[1].BlockChain().SubscribeChain2HeadEvent(chain2HeadChNode1)
+	for _, node := range nodes {
+		go func(chain *core.BlockChain) {
+			noReorgErrs <- bortest.AssertNoReorg(ctx, chain, 20)
+		}(node.BlockChain())
+	}
 
 	for {
 		// for block 0 to 7, the primary validator is node0
@@ -897,27 +950,15 @@ func TestReorgingAfterWhitelistingOnDifferentHash(t *testing.T) {
 			stacks[1].Server().AddPeer(enodes[0])
 		}
 
-		select {
-		case ev := <-chain2HeadChNode0:
-			if ev.Type == core.Chain2HeadReorgEvent {
-				assert.Fail(t, "Node 0 should not get reorged as it was whitelisted on different hash")
-				break
-			}
-
-		case ev := <-chain2HeadChNode1:
-
-			if ev.Type == core.Chain2HeadReorgEvent {
-				assert.Fail(t, "Node 1 should not get reorged as it was whiteliseted on different hash")
-				break
-			}
-
-		default:
-			time.Sleep(1 * time.Millisecond)
-		}
-
 		if blockHeaderVal0.Number.Uint64() == 30 {
 			break
 		}
+
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	for range nodes {
+		assert.NoError(t, <-noReorgErrs, "Nodes should not get reorged as they were whitelisted on different hash")
 	}
 }
 
@@ -1007,15 +1048,12 @@ func TestNonMinerNodeWithWhitelisting(t *testing.T) {
 		}
 	}
 
+	expected := make(map[uint64]common.Address)
 	for i := uint64(0); i < nodes[1].BlockChain().CurrentBlock().Number.Uint64(); i++ {
-		blockHeader := nodes[1].BlockChain().GetHeaderByNumber(i)
-
-		authorVal, err := nodes[1].Engine().Author(blockHeader)
-
-		if err == nil {
-			assert.Equal(t, authorVal, nodes[0].AccountManager().Accounts()[0])
-		}
+		expected[i] = nodes[0].AccountManager().Accounts()[0]
 	}
+
+	bortest.AssertAuthors(t, nodes[1], expected)
 }
 
 func TestNonMinerNodeWithTryToLock(t *testing.T) {
@@ -1076,6 +1114,11 @@ func TestNonMinerNodeWithTryToLock(t *testing.T) {
 		panic(err)
 	}
 
+	milestoneListCh := make(chan int, 1)
+	milestoneListSub := nodes[0].Downloader().ChainValidator.SubscribeMilestoneIDListChange(milestoneListCh)
+
+	defer milestoneListSub.Unsubscribe()
+
 	for {
 		blockHeaderVal0 := nodes[0].BlockChain().CurrentHeader()
 		blockHeaderVal1 := nodes[1].BlockChain().CurrentHeader()
@@ -1085,6 +1128,17 @@ func TestNonMinerNodeWithTryToLock(t *testing.T) {
 			blockHash := blockHeaderVal1.Hash()
 			_, _ = nodes[1].APIBackend.GetVoteOnHash(nil, 0, 7, "0x"+blockHash.String(), "MilestoneID1")
 
+			// The non mining node doesn't vote
+			rootHash, err := nodes[1].APIBackend.GetRootHash(context.Background(), 0, 7)
+			assert.NoError(t, err)
+
+			borAPI := nodes[1].Engine().APIs(nodes[1].BlockChain())[0].Service.(*bor.API)
+
+			vote, err := borAPI.VoteOnMilestone(context.Background(), 0, 7, rootHash, "MilestoneID1")
+			assert.NoError(t, err)
+			assert.False(t, vote.Validator)
+			assert.False(t, vote.Vote)
+			assert.Equal(t, rootHash, vote.LocalRootHash)
 		}
 
 		//Asking for the vote
@@ -1099,9 +1153,10 @@ func TestNonMinerNodeWithTryToLock(t *testing.T) {
 			_, _ = nodes[1].APIBackend.GetVoteOnHash(nil, 0, 7, "0x"+blockHash.String(), "MilestoneID3")
 		}
 
-		milestoneList := nodes[0].Downloader().ChainValidator.GetMilestoneIDsList()
-		if len(milestoneList) > 0 {
-			assert.Fail(t, "MilestoneList should be of zero length")
+		select {
+		case length := <-milestoneListCh:
+			assert.Fail(t, "MilestoneList should be of zero length", "length %d", length)
+		default:
 		}
 
 		if blockHeaderVal0.Number.Uint64() == 30 {
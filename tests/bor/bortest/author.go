@@ -0,0 +1,42 @@
+package bortest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth"
+)
+
+// AssertAuthors checks that the author recovered by the node at each of the given
+// block numbers is the expected address, reporting a failure per mismatching
+// block. Blocks whose author can't be recovered are skipped and logged, the node
+// missing a block is reported as a failure.
+func AssertAuthors(t testing.TB, node *eth.Ethereum, expected map[uint64]common.Address) {
+	t.Helper()
+
+	numbers := make([]uint64, 0, len(expected))
+	for number := range expected {
+		numbers = append(numbers, number)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	for _, number := range numbers {
+		header := node.BlockChain().GetHeaderByNumber(number)
+		if header == nil {
+			t.Errorf("block %d: header missing, expected author %s", number, expected[number])
+			continue
+		}
+
+		author, err := node.Engine().Author(header)
+		if err != nil {
+			t.Logf("block %d: skipped, author not recovered: %v", number, err)
+			continue
+		}
+
+		if author != expected[number] {
+			t.Errorf("block %d (%s): author %s, expected %s", number, header.Hash().TerminalString(), author, expected[number])
+		}
+	}
+}
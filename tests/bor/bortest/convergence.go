@@ -0,0 +1,85 @@
+// Package bortest contains helpers shared by the bor integration tests.
+package bortest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth"
+)
+
+// convergencePollInterval is the delay between two checks of the nodes' headers.
+const convergencePollInterval = 100 * time.Millisecond
+
+// DivergenceError is returned by WaitForConvergence when the nodes didn't agree on
+// the header at the given number in time. Hashes holds the hash seen by each node,
+// in the order of the nodes, with the zero hash for a node missing the header.
+type DivergenceError struct {
+	Number uint64
+	Hashes []common.Hash
+	Err    error
+}
+
+func (e *DivergenceError) Error() string {
+	var diff strings.Builder
+
+	for i, hash := range e.Hashes {
+		if i > 0 {
+			diff.WriteString(", ")
+		}
+
+		if hash == (common.Hash{}) {
+			fmt.Fprintf(&diff, "node%d=missing", i)
+		} else {
+			fmt.Fprintf(&diff, "node%d=%s", i, hash.TerminalString())
+		}
+	}
+
+	return fmt.Sprintf("nodes didn't converge at block %d (%s): %v", e.Number, diff.String(), e.Err)
+}
+
+func (e *DivergenceError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForConvergence polls the nodes until all of them have the same header at the
+// given number, or returns a DivergenceError once the context is done.
+func WaitForConvergence(ctx context.Context, nodes []*eth.Ethereum, number uint64) error {
+	ticker := time.NewTicker(convergencePollInterval)
+	defer ticker.Stop()
+
+	for {
+		hashes, converged := headerHashes(nodes, number)
+		if converged {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &DivergenceError{Number: number, Hashes: hashes, Err: ctx.Err()}
+		case <-ticker.C:
+		}
+	}
+}
+
+// headerHashes returns the hash of the header at the given number on each node and
+// whether all the nodes have the same one.
+func headerHashes(nodes []*eth.Ethereum, number uint64) ([]common.Hash, bool) {
+	hashes := make([]common.Hash, len(nodes))
+	converged := true
+
+	for i, node := range nodes {
+		if header := node.BlockChain().GetHeaderByNumber(number); header != nil {
+			hashes[i] = header.Hash()
+		}
+
+		if hashes[i] == (common.Hash{}) || hashes[i] != hashes[0] {
+			converged = false
+		}
+	}
+
+	return hashes, converged
+}
@@ -0,0 +1,221 @@
+package bortest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// networkManifestFile is the file of a network snapshot describing its nodes.
+	networkManifestFile = "network.json"
+
+	// restoreBatchSize is the number of blocks inserted at once by RestoreNetwork.
+	restoreBatchSize = 256
+)
+
+// WhitelistEntry is a whitelisted checkpoint or milestone, or a future milestone.
+type WhitelistEntry struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// SprintLock is the sprint locked by the milestone voting.
+type SprintLock struct {
+	Number       uint64      `json:"number"`
+	Hash         common.Hash `json:"hash"`
+	MilestoneIDs []string    `json:"milestoneIDs"`
+}
+
+// NodeSnapshot is the state of a node saved by SnapshotNetwork: its canonical chain,
+// stored in ChainFile, and the state of its chain validator.
+type NodeSnapshot struct {
+	ChainFile        string           `json:"chainFile"`
+	Head             uint64           `json:"head"`
+	HeadHash         common.Hash      `json:"headHash"`
+	Checkpoint       *WhitelistEntry  `json:"checkpoint,omitempty"`
+	Milestone        *WhitelistEntry  `json:"milestone,omitempty"`
+	FutureMilestones []WhitelistEntry `json:"futureMilestones"`
+	Lock             *SprintLock      `json:"lock,omitempty"`
+}
+
+// SnapshotNetwork saves the canonical chain and the chain validator state of the
+// nodes to a new temporary directory, whose path is returned, to replay them later
+// with RestoreNetwork, e.g. when a test fails. The nodes aren't paused, so the state
+// of a node still importing blocks may be slightly off from its chain. The side
+// chains aren't saved.
+func SnapshotNetwork(nodes []TimelineNode) (string, error) {
+	dir, err := os.MkdirTemp("", "bor-network-")
+	if err != nil {
+		return "", err
+	}
+
+	snapshots := make([]NodeSnapshot, len(nodes))
+
+	for i, node := range nodes {
+		snapshot, err := snapshotNode(dir, i, node)
+		if err != nil {
+			return dir, fmt.Errorf("node%d: %w", i, err)
+		}
+
+		snapshots[i] = *snapshot
+	}
+
+	manifest, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return dir, err
+	}
+
+	return dir, os.WriteFile(filepath.Join(dir, networkManifestFile), manifest, 0600)
+}
+
+// snapshotNode saves the canonical chain of the node to the given directory and
+// returns its snapshot.
+func snapshotNode(dir string, index int, node TimelineNode) (*NodeSnapshot, error) {
+	var (
+		chain     = node.Eth.BlockChain()
+		validator = node.Eth.Downloader().ChainValidator
+		head      = chain.CurrentBlock()
+	)
+
+	snapshot := &NodeSnapshot{
+		ChainFile:        fmt.Sprintf("node%d.rlp", index),
+		Head:             head.Number.Uint64(),
+		HeadHash:         head.Hash(),
+		FutureMilestones: []WhitelistEntry{},
+	}
+
+	file, err := os.Create(filepath.Join(dir, snapshot.ChainFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The genesis block is the one of the node the snapshot is restored to
+	if snapshot.Head > 0 {
+		if err := chain.ExportN(file, 1, snapshot.Head); err != nil {
+			return nil, err
+		}
+	}
+
+	if exists, number, hash := validator.GetWhitelistedCheckpoint(); exists {
+		snapshot.Checkpoint = &WhitelistEntry{Number: number, Hash: hash}
+	}
+
+	if exists, number, hash := validator.GetWhitelistedMilestone(); exists {
+		snapshot.Milestone = &WhitelistEntry{Number: number, Hash: hash}
+	}
+
+	numbers, hashes := validator.GetFutureMilestones()
+	for i := range numbers {
+		snapshot.FutureMilestones = append(snapshot.FutureMilestones, WhitelistEntry{Number: numbers[i], Hash: hashes[i]})
+	}
+
+	if locked, number, hash, ids := validator.GetLockedSprintInfo(); locked {
+		snapshot.Lock = &SprintLock{Number: number, Hash: hash, MilestoneIDs: ids}
+	}
+
+	return snapshot, nil
+}
+
+// RestoreNetwork restores the network snapshot saved by SnapshotNetwork in the given
+// directory to the given nodes, which have to be fresh nodes of the same genesis,
+// not mining, in the order of the snapshot. It returns the restored snapshots.
+//
+// The chain validator state is restored through the chain validator, so a lock held
+// by several milestones is restored with its first milestone id only.
+func RestoreNetwork(dir string, nodes []TimelineNode) ([]NodeSnapshot, error) {
+	manifest, err := os.ReadFile(filepath.Join(dir, networkManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []NodeSnapshot
+	if err := json.Unmarshal(manifest, &snapshots); err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) != len(nodes) {
+		return nil, fmt.Errorf("snapshot of %d nodes restored to %d nodes", len(snapshots), len(nodes))
+	}
+
+	for i, node := range nodes {
+		if err := restoreNode(dir, snapshots[i], node); err != nil {
+			return nil, fmt.Errorf("node%d: %w", i, err)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// restoreNode imports the chain of the snapshot to the node and restores the state
+// of its chain validator.
+func restoreNode(dir string, snapshot NodeSnapshot, node TimelineNode) error {
+	chain := node.Eth.BlockChain()
+
+	file, err := os.Open(filepath.Join(dir, snapshot.ChainFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stream := rlp.NewStream(file, 0)
+	blocks := make([]*types.Block, 0, restoreBatchSize)
+
+	for {
+		var block types.Block
+
+		err := stream.Decode(&block)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("block %d: %w", chain.CurrentBlock().Number.Uint64()+uint64(len(blocks))+1, err)
+		}
+
+		if err == nil {
+			blocks = append(blocks, &block)
+		}
+
+		if len(blocks) == restoreBatchSize || (errors.Is(err, io.EOF) && len(blocks) > 0) {
+			if _, err := chain.InsertChain(blocks); err != nil {
+				return err
+			}
+
+			blocks = blocks[:0]
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	if head := chain.CurrentBlock(); head.Hash() != snapshot.HeadHash {
+		return fmt.Errorf("restored head %d (%s), expected %d (%s)", head.Number, head.Hash().TerminalString(), snapshot.Head, snapshot.HeadHash.TerminalString())
+	}
+
+	validator := node.Eth.Downloader().ChainValidator
+
+	if snapshot.Checkpoint != nil {
+		validator.ProcessCheckpoint(snapshot.Checkpoint.Number, snapshot.Checkpoint.Hash)
+	}
+
+	if snapshot.Milestone != nil {
+		validator.ProcessMilestone(snapshot.Milestone.Number, snapshot.Milestone.Hash)
+	}
+
+	for _, future := range snapshot.FutureMilestones {
+		validator.ProcessFutureMilestone(future.Number, future.Hash)
+	}
+
+	if snapshot.Lock != nil && len(snapshot.Lock.MilestoneIDs) > 0 {
+		doLock := validator.LockMutex(snapshot.Lock.Number)
+		validator.UnlockMutex(doLock, snapshot.Lock.MilestoneIDs[0], snapshot.Lock.Number, snapshot.Lock.Hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package bortest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReorgError is returned by AssertNoReorg when the chain got reorged.
+type ReorgError struct {
+	OldHead *types.Header // Head of the dropped chain
+	NewHead *types.Header // Head of the chain which replaced it
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf(
+		"chain reorged from block %d (%s) to block %d (%s)",
+		e.OldHead.Number, e.OldHead.Hash().TerminalString(),
+		e.NewHead.Number, e.NewHead.Hash().TerminalString(),
+	)
+}
+
+// AssertNoReorg watches the chain until its head reaches the given number. It
+// returns a ReorgError as soon as the chain gets reorged, or the context's error
+// if it's done first.
+func AssertNoReorg(ctx context.Context, chain *core.BlockChain, untilNumber uint64) error {
+	events := make(chan core.Chain2HeadEvent, 64)
+
+	sub := chain.SubscribeChain2HeadEvent(events)
+	defer sub.Unsubscribe()
+
+	for chain.CurrentHeader().Number.Uint64() < untilNumber {
+		select {
+		case ev := <-events:
+			if ev.Type == core.Chain2HeadReorgEvent {
+				return &ReorgError{
+					OldHead: ev.OldChain[0].Header(),
+					NewHead: ev.NewChain[0].Header(),
+				}
+			}
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
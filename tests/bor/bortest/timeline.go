@@ -0,0 +1,177 @@
+package bortest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// timelinePollInterval is the delay between two checks of the watched heads.
+const timelinePollInterval = 10 * time.Millisecond
+
+// TimelineNode is a node driven by RunTimeline.
+type TimelineNode struct {
+	Stack *node.Node
+	Eth   *eth.Ethereum
+}
+
+// Action is run by RunTimeline on the nodes once the head of the watched node
+// reaches the block of its step, given as number.
+type Action struct {
+	Name string
+	Run  func(nodes []TimelineNode, number uint64) error
+}
+
+// Step runs Action once the head of the node Watch reaches the block AtBlock.
+type Step struct {
+	Watch   int
+	AtBlock uint64
+	Action  Action
+}
+
+// ExecutedStep is a step run by RunTimeline.
+type ExecutedStep struct {
+	Step   int    // Index of the step
+	Action string // Name of the action
+	Watch  int    // Index of the watched node
+	Head   uint64 // Head of the watched node when the action was run
+	Time   time.Time
+}
+
+func (s ExecutedStep) String() string {
+	return fmt.Sprintf("step %d: %s at block %d of node%d", s.Step, s.Action, s.Head, s.Watch)
+}
+
+// RunTimeline runs the steps in order, each one as soon as the head of its watched
+// node reaches its block (or is past it, as the head can skip blocks on a reorg
+// or a sync) and the previous steps ran. It returns the log of the steps run, and
+// the error of the failed action or the context's error if the timeline didn't
+// complete.
+func RunTimeline(ctx context.Context, nodes []TimelineNode, steps []Step) ([]ExecutedStep, error) {
+	ticker := time.NewTicker(timelinePollInterval)
+	defer ticker.Stop()
+
+	executed := make([]ExecutedStep, 0, len(steps))
+
+	for i := 0; i < len(steps); {
+		step := steps[i]
+		if step.Watch < 0 || step.Watch >= len(nodes) {
+			return executed, fmt.Errorf("step %d: watched node %d out of range", i, step.Watch)
+		}
+
+		head := nodes[step.Watch].Eth.BlockChain().CurrentHeader()
+		if head.Number.Uint64() >= step.AtBlock {
+			if err := step.Action.Run(nodes, step.AtBlock); err != nil {
+				return executed, fmt.Errorf("step %d: %s at block %d of node%d: %w", i, step.Action.Name, head.Number.Uint64(), step.Watch, err)
+			}
+
+			executed = append(executed, ExecutedStep{
+				Step:   i,
+				Action: step.Action.Name,
+				Watch:  step.Watch,
+				Head:   head.Number.Uint64(),
+				Time:   time.Now(),
+			})
+			i++
+
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return executed, ctx.Err()
+		}
+	}
+
+	return executed, nil
+}
+
+// Lock locks the sprint ending at the block of the step on the given node, for the
+// given milestone id, as the milestone voting does.
+func Lock(target int, milestoneID string) Action {
+	return Action{
+		Name: fmt.Sprintf("lock %s on node%d", milestoneID, target),
+		Run: func(nodes []TimelineNode, number uint64) error {
+			header := nodes[target].Eth.BlockChain().GetHeaderByNumber(number)
+			if header == nil {
+				return fmt.Errorf("no header %d on node%d", number, target)
+			}
+
+			validator := nodes[target].Eth.Downloader().ChainValidator
+			validator.LockMutex(number)
+			validator.UnlockMutex(true, milestoneID, number, header.Hash())
+
+			return nil
+		},
+	}
+}
+
+// Unlock unlocks the sprint ending at the given block on the given node.
+func Unlock(target int, endBlock uint64) Action {
+	return Action{
+		Name: fmt.Sprintf("unlock sprint %d on node%d", endBlock, target),
+		Run: func(nodes []TimelineNode, _ uint64) error {
+			nodes[target].Eth.Downloader().ChainValidator.UnlockSprint(endBlock)
+			return nil
+		},
+	}
+}
+
+// ProcessMilestone whitelists a milestone ending at the block of the step on the
+// given node.
+func ProcessMilestone(target int) Action {
+	return Action{
+		Name: fmt.Sprintf("process milestone on node%d", target),
+		Run: func(nodes []TimelineNode, number uint64) error {
+			header := nodes[target].Eth.BlockChain().GetHeaderByNumber(number)
+			if header == nil {
+				return fmt.Errorf("no header %d on node%d", number, target)
+			}
+
+			nodes[target].Eth.Downloader().ChainValidator.ProcessMilestone(number, header.Hash())
+
+			return nil
+		},
+	}
+}
+
+// Disconnect disconnects the given nodes from each other.
+func Disconnect(a, b int) Action {
+	return Action{
+		Name: fmt.Sprintf("disconnect node%d and node%d", a, b),
+		Run: func(nodes []TimelineNode, _ uint64) error {
+			nodes[a].Stack.Server().RemovePeer(nodes[b].Stack.Server().Self())
+			nodes[b].Stack.Server().RemovePeer(nodes[a].Stack.Server().Self())
+
+			return nil
+		},
+	}
+}
+
+// Connect connects the given nodes to each other.
+func Connect(a, b int) Action {
+	return Action{
+		Name: fmt.Sprintf("connect node%d and node%d", a, b),
+		Run: func(nodes []TimelineNode, _ uint64) error {
+			nodes[a].Stack.Server().AddPeer(nodes[b].Stack.Server().Self())
+			nodes[b].Stack.Server().AddPeer(nodes[a].Stack.Server().Self())
+
+			return nil
+		},
+	}
+}
+
+// Mark does nothing, it only records in the log that the watched node reached the
+// block of its step, e.g. to wait for a height at the end of a timeline.
+func Mark(name string) Action {
+	return Action{
+		Name: name,
+		Run: func([]TimelineNode, uint64) error {
+			return nil
+		},
+	}
+}